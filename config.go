@@ -0,0 +1,469 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 分层配置加载：默认值 < 配置文件（按添加顺序）< 环境变量 < 显式覆盖，后加载的层只覆盖其中出现的字段
+
+// ConfigValidator 配置校验接口，加载完成后自动调用
+type ConfigValidator interface {
+	Validate() error
+}
+
+// configFile 配置文件来源
+type configFile struct {
+	path     string
+	optional bool // 文件不存在时跳过
+}
+
+// configOverride 按路径覆盖的配置项
+type configOverride struct {
+	path  string
+	value interface{}
+}
+
+// ConfigLoader 分层配置加载器
+type ConfigLoader[T any] struct {
+	defaults  *T
+	files     []configFile
+	env       bool
+	envPrefix string
+	overrides []configOverride
+	codec     *MarshalExt
+}
+
+// NewConfigLoader 创建配置加载器
+// 文件按扩展名选择格式，无法识别时按内容识别；字段名按风格无关方式匹配，time_format、timeFormat 和 TimeFormat 等价；
+// time.Duration 字段在配置文件、环境变量和覆盖值中都接受 time.ParseDuration 格式的字符串，如 "10s"
+func NewConfigLoader[T any]() *ConfigLoader[T] {
+	return &ConfigLoader[T]{
+		codec: DefaultMarshalExt().SetKeyNaming(SnakeCaseKeys),
+	}
+}
+
+// SetDefaults 设置默认值，每次加载都从默认值的深拷贝开始（链式调用）
+func (l *ConfigLoader[T]) SetDefaults(defaults T) *ConfigLoader[T] {
+	l.defaults = &defaults
+	return l
+}
+
+// AddFile 添加配置文件，文件不存在时加载失败（链式调用）
+func (l *ConfigLoader[T]) AddFile(path string) *ConfigLoader[T] {
+	l.files = append(l.files, configFile{path: path})
+	return l
+}
+
+// AddOptionalFile 添加可选配置文件，文件不存在时跳过（链式调用）
+func (l *ConfigLoader[T]) AddOptionalFile(path string) *ConfigLoader[T] {
+	l.files = append(l.files, configFile{path: path, optional: true})
+	return l
+}
+
+// SetEnvPrefix 启用环境变量并设置前缀（链式调用）
+//
+// 变量名为前缀加字段路径，各级以下划线连接并转为大写蛇形，如前缀 APP 时 File.MaxSizeMb 对应 APP_FILE_MAX_SIZE_MB；
+// env 标签替换该级名称，env:"-" 忽略字段。切片以逗号分隔，time.Duration 使用 time.ParseDuration 格式
+func (l *ConfigLoader[T]) SetEnvPrefix(prefix string) *ConfigLoader[T] {
+	l.env = true
+	l.envPrefix = strings.TrimSuffix(prefix, "_")
+	return l
+}
+
+// SetOverride 按点分路径覆盖配置项，如 "file.path"，路径各级按风格无关方式匹配字段名或 map 键（链式调用）
+func (l *ConfigLoader[T]) SetOverride(path string, value interface{}) *ConfigLoader[T] {
+	l.overrides = append(l.overrides, configOverride{path: path, value: value})
+	return l
+}
+
+// SetOverrides 批量覆盖配置项，按路径排序后依次应用（链式调用）
+func (l *ConfigLoader[T]) SetOverrides(overrides map[string]interface{}) *ConfigLoader[T] {
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		l.SetOverride(path, overrides[path])
+	}
+	return l
+}
+
+// SetMarshalExt 设置解码配置文件使用的序列化器，如开启严格模式（链式调用）
+func (l *ConfigLoader[T]) SetMarshalExt(m *MarshalExt) *ConfigLoader[T] {
+	l.codec = m
+	return l
+}
+
+// Load 按层加载配置，实现了 ConfigValidator 时最后调用 Validate
+func (l *ConfigLoader[T]) Load() (*T, error) {
+	cfg := new(T)
+	if l.defaults != nil {
+		reflect.ValueOf(cfg).Elem().Set(deepCopyValue(reflect.ValueOf(l.defaults).Elem(), map[copyKey]reflect.Value{}))
+	}
+
+	for _, file := range l.files {
+		if err := l.loadFile(file.path, cfg); err != nil {
+			if file.optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("config file error: %s: %w", file.path, err)
+		}
+	}
+
+	if l.env {
+		if root := reflect.ValueOf(cfg).Elem(); root.Kind() == reflect.Struct {
+			if _, err := applyConfigEnv(root, l.envPrefix); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, override := range l.overrides {
+		if err := setConfigPath(reflect.ValueOf(cfg).Elem(), strings.Split(override.path, "."), override.value); err != nil {
+			return nil, fmt.Errorf("config override error: %s: %w", override.path, err)
+		}
+	}
+
+	if validator, ok := interface{}(cfg).(ConfigValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("config validate error: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// loadFile 读取配置文件，格式选择与 MarshalExt.LoadFile 相同
+// encoding/json 不接受字符串形式的 time.Duration，JSON 文件先按目标类型将其转换为纳秒
+func (l *ConfigLoader[T]) loadFile(path string, cfg *T) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file error: %w", err)
+	}
+	codec := l.codec.forPath(path)
+	if _, ok := FormatByExtension(path); !ok {
+		codec = l.codec.Clone().SetFormat(l.codec.detectOrDefault(data))
+	}
+	if codec.options.Format == JSONFormat {
+		if converted, ok := convertJSONDurations(data, reflect.TypeOf(cfg).Elem()); ok {
+			data = converted
+		}
+	}
+	return codec.Unmarshal(data, cfg)
+}
+
+// MustLoad 加载配置，出错时 panic
+func (l *ConfigLoader[T]) MustLoad() *T {
+	cfg, err := l.Load()
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// Watch 每隔 interval 检查配置文件的修改时间和大小，变化时重新加载并调用 onReload
+// 加载失败时 onReload 收到错误，调用方应继续使用旧配置；返回的函数用于停止监听
+func (l *ConfigLoader[T]) Watch(interval time.Duration, onReload func(cfg *T, err error)) (stop func()) {
+	state := l.fileState()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				current := l.fileState()
+				if current == state {
+					continue
+				}
+				state = current
+				onReload(l.Load())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// fileState 返回全部配置文件的修改时间和大小，不存在的文件记为空
+func (l *ConfigLoader[T]) fileState() string {
+	var sb strings.Builder
+	for _, file := range l.files {
+		if info, err := os.Stat(file.path); err == nil {
+			fmt.Fprintf(&sb, "%s:%d:%d;", file.path, info.ModTime().UnixNano(), info.Size())
+		} else {
+			fmt.Fprintf(&sb, "%s:-;", file.path)
+		}
+	}
+	return sb.String()
+}
+
+// LoadConfig 按顺序加载配置文件，再应用 prefix 前缀的环境变量（prefix 为空时不读取环境变量）
+func LoadConfig[T any](defaults T, prefix string, paths ...string) (*T, error) {
+	loader := NewConfigLoader[T]().SetDefaults(defaults)
+	for _, path := range paths {
+		loader.AddFile(path)
+	}
+	if prefix != "" {
+		loader.SetEnvPrefix(prefix)
+	}
+	return loader.Load()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convertJSONDurations 将 JSON 中对应 time.Duration 字段的字符串转换为纳秒，没有需要转换的值或 JSON 无效时返回 false
+func convertJSONDurations(data []byte, t reflect.Type) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) != nil {
+		return nil, false
+	}
+	value, changed := convertDurations(t, value)
+	if !changed {
+		return nil, false
+	}
+	converted, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	return converted, true
+}
+
+// convertDurations 按目标类型遍历通用结构，将 time.Duration 位置上可解析的字符串替换为纳秒
+func convertDurations(t reflect.Type, value interface{}) (interface{}, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		if s, ok := value.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				return int64(d), true
+			}
+		}
+		return value, false
+	}
+
+	changed := false
+	switch items := value.(type) {
+	case map[string]interface{}:
+		for key, item := range items {
+			var itemType reflect.Type
+			switch {
+			case t.Kind() == reflect.Map:
+				itemType = t.Elem()
+			case t.Kind() == reflect.Struct && t != timeType:
+				itemType = configFieldType(t, key)
+			}
+			if itemType == nil {
+				continue
+			}
+			if converted, ok := convertDurations(itemType, item); ok {
+				items[key] = converted
+				changed = true
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			break
+		}
+		for i, item := range items {
+			if converted, ok := convertDurations(t.Elem(), item); ok {
+				items[i] = converted
+				changed = true
+			}
+		}
+	}
+	return value, changed
+}
+
+// configFieldType 按风格无关方式查找键对应的字段类型，找不到时返回 nil
+func configFieldType(t reflect.Type, key string) reflect.Type {
+	for _, f := range structFields(t, "json") {
+		sf := t.FieldByIndex(f.index)
+		if normalizeKeyName(f.name) == normalizeKeyName(key) || normalizeKeyName(sf.Name) == normalizeKeyName(key) {
+			return sf.Type
+		}
+	}
+	return nil
+}
+
+// applyConfigEnv 将环境变量写入结构体字段，返回是否有字段被设置
+func applyConfigEnv(v reflect.Value, prefix string) (bool, error) {
+	changed := false
+	t := v.Type()
+	for _, f := range structFields(t, "env") {
+		sf := t.FieldByIndex(f.index)
+		name := strings.ToUpper(SnakeCaseKeys.Apply(f.name))
+		if tag, ok := sf.Tag.Lookup("env"); ok {
+			name, _, _ = strings.Cut(tag, ",")
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case isScalarType(ft) || (ft.Kind() == reflect.Slice && isScalarType(ft.Elem())):
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			field, _ := fieldByIndex(v, f.index, true)
+			if err := setConfigValue(field, value); err != nil {
+				return changed, fmt.Errorf("config env error: %s: %w", name, err)
+			}
+			changed = true
+		case ft.Kind() == reflect.Struct:
+			field, _ := fieldByIndex(v, f.index, true)
+			if field.Kind() != reflect.Ptr {
+				fieldChanged, err := applyConfigEnv(field, name)
+				if err != nil {
+					return changed, err
+				}
+				changed = changed || fieldChanged
+				continue
+			}
+			// 指针字段为 nil 时只在有变量命中时分配
+			target := field
+			if field.IsNil() {
+				target = reflect.New(field.Type().Elem())
+			}
+			fieldChanged, err := applyConfigEnv(target.Elem(), name)
+			if err != nil {
+				return changed, err
+			}
+			if fieldChanged {
+				field.Set(target)
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// setConfigValue 将字符串写入标量或标量切片，切片以逗号分隔（[]byte 除外），time.Duration 按 time.ParseDuration 解析
+func setConfigValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setConfigValue(v.Elem(), s)
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(s))
+		return nil
+	}
+	if v.Kind() == reflect.Slice && !isScalarType(v.Type()) {
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setConfigValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setScalar(v, s)
+}
+
+// setConfigPath 按路径设置值，结构体字段和 map 键按风格无关方式匹配
+func setConfigPath(v reflect.Value, path []string, value interface{}) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setConfigPath(v.Elem(), path, value)
+	}
+	if len(path) == 0 {
+		return assignConfigValue(v, value)
+	}
+
+	key := path[0]
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		for _, f := range structFields(v.Type(), "json") {
+			sf := v.Type().FieldByIndex(f.index)
+			if normalizeKeyName(f.name) != normalizeKeyName(key) && normalizeKeyName(sf.Name) != normalizeKeyName(key) {
+				continue
+			}
+			field, _ := fieldByIndex(v, f.index, true)
+			return setConfigPath(field, path[1:], value)
+		}
+		return fmt.Errorf("%w: %s", ErrPathNotFound, key)
+	case v.Kind() == reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		mapKey := reflect.New(v.Type().Key()).Elem()
+		if err := setScalar(mapKey, key); err != nil {
+			return err
+		}
+		item := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(mapKey); existing.IsValid() {
+			item.Set(existing)
+		}
+		if err := setConfigPath(item, path[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(mapKey, item)
+		return nil
+	}
+	return fmt.Errorf("%w: cannot descend into %s at %s", ErrPathNotFound, v.Type(), key)
+}
+
+// assignConfigValue 写入覆盖值：类型可直接赋值时直接赋值，字符串按环境变量规则解析，其余经通用结构转换
+func assignConfigValue(v reflect.Value, value interface{}) error {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(v.Type()) {
+		v.Set(rv)
+		return nil
+	}
+	if rv.Type().ConvertibleTo(v.Type()) && rv.Kind() != reflect.String && v.Kind() != reflect.String && isScalarType(v.Type()) {
+		v.Set(rv.Convert(v.Type()))
+		return nil
+	}
+	if s, ok := value.(string); ok && (isScalarType(v.Type()) || v.Kind() == reflect.Slice) {
+		return setConfigValue(v, s)
+	}
+	generic, err := normalizeJSON(value)
+	if err != nil {
+		return err
+	}
+	return assignGeneric(v, normalizeGeneric(generic), "json")
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/so68/utils/logger"
+)
+
+/*
+分层配置加载测试
+
+运行命令：
+go test -v -run "^TestConfig.*$"
+
+测试内容：
+1. 默认值、多个配置文件、环境变量和显式覆盖的优先级
+2. 不同格式和键名风格的配置文件
+3. 环境变量的 env 标签、切片、time.Duration 和指针结构体
+4. 配置文件中字符串形式的 time.Duration
+5. 可选文件、缺失文件和 Validate 校验失败
+6. 默认值不被加载过程修改
+7. 文件变化后重新加载并回调
+*/
+
+// writeConfigFile 在临时目录写入配置文件
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Write %s failed: %v", path, err)
+	}
+	return path
+}
+
+func TestConfigLoaderLayers(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := writeConfigFile(t, dir, "app.yaml", "level: debug\noutput: file\nfile:\n  path: /var/log/app.log\n  max_size_mb: 50\n")
+	jsonPath := writeConfigFile(t, dir, "local.json", `{"timeFormat": "2006-01-02", "file": {"maxBackups": 3}}`)
+
+	t.Setenv("APP_OUTPUT", "both")
+	t.Setenv("APP_FILE_MAX_AGE_DAYS", "7")
+
+	cfg, err := NewConfigLoader[logger.Config]().
+		SetDefaults(*logger.DefaultConfig()).
+		AddFile(yamlPath).
+		AddFile(jsonPath).
+		AddOptionalFile(filepath.Join(dir, "missing.toml")).
+		SetEnvPrefix("APP_").
+		SetOverride("file.compress", false).
+		SetOverride("caller_skip", "5").
+		Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Level != logger.LevelDebug || cfg.Output != logger.OutputBoth || cfg.TimeFormat != "2006-01-02" || cfg.CallerSkip != 5 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	file := cfg.File
+	if file.Path != "/var/log/app.log" || file.MaxSizeMb != 50 || file.MaxBackups != 3 || file.MaxAgeDays != 7 || file.Compress || !file.LocalTime {
+		t.Errorf("Unexpected file config: %+v", file)
+	}
+}
+
+func TestConfigLoaderEnv(t *testing.T) {
+	type database struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type config struct {
+		Name     string            `json:"name" env:"SERVICE_NAME"`
+		Timeout  time.Duration     `json:"timeout"`
+		Hosts    []string          `json:"hosts"`
+		Ports    []int             `json:"ports"`
+		Debug    *bool             `json:"debug"`
+		Secret   string            `json:"secret" env:"-"`
+		Primary  database          `json:"primary"`
+		Replica  *database         `json:"replica"`
+		Backup   *database         `json:"backup"`
+		Labels   map[string]string `json:"labels"`
+		Internal string            `json:"-"`
+	}
+
+	t.Setenv("SVC_SERVICE_NAME", "orders")
+	t.Setenv("SVC_TIMEOUT", "1m30s")
+	t.Setenv("SVC_HOSTS", "a.example, b.example")
+	t.Setenv("SVC_PORTS", "80,443")
+	t.Setenv("SVC_DEBUG", "true")
+	t.Setenv("SVC_SECRET", "leak")
+	t.Setenv("SVC_PRIMARY_PORT", "5433")
+	t.Setenv("SVC_REPLICA_HOST", "replica.local")
+
+	defaults := config{Primary: database{Host: "localhost", Port: 5432}, Labels: map[string]string{"env": "dev"}}
+	loader := NewConfigLoader[config]().
+		SetDefaults(defaults).
+		SetEnvPrefix("SVC").
+		SetOverrides(map[string]interface{}{"labels.team": "core", "primary.host": "db.local"})
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Name != "orders" || cfg.Timeout != 90*time.Second || cfg.Secret != "" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if strings.Join(cfg.Hosts, "|") != "a.example|b.example" || len(cfg.Ports) != 2 || cfg.Ports[1] != 443 {
+		t.Errorf("Unexpected slices: %v %v", cfg.Hosts, cfg.Ports)
+	}
+	if cfg.Debug == nil || !*cfg.Debug {
+		t.Errorf("Debug = %v", cfg.Debug)
+	}
+	if cfg.Primary != (database{Host: "db.local", Port: 5433}) {
+		t.Errorf("Primary = %+v", cfg.Primary)
+	}
+	if cfg.Replica == nil || cfg.Replica.Host != "replica.local" || cfg.Backup != nil {
+		t.Errorf("Replica = %+v, Backup = %+v", cfg.Replica, cfg.Backup)
+	}
+	if cfg.Labels["env"] != "dev" || cfg.Labels["team"] != "core" {
+		t.Errorf("Labels = %v", cfg.Labels)
+	}
+	if len(defaults.Labels) != 1 || defaults.Primary.Host != "localhost" {
+		t.Errorf("Defaults modified: %+v", defaults)
+	}
+
+	t.Setenv("SVC_TIMEOUT", "soon")
+	if _, err := loader.Load(); err == nil || !strings.Contains(err.Error(), "SVC_TIMEOUT") {
+		t.Errorf("Invalid env error = %v", err)
+	}
+	if _, err := NewConfigLoader[config]().SetOverride("primary.missing", 1).Load(); err == nil {
+		t.Error("Unknown override path should fail")
+	}
+}
+
+func TestConfigLoaderFileDurations(t *testing.T) {
+	type server struct {
+		IdleTimeout time.Duration `json:"idle_timeout"`
+	}
+	type config struct {
+		Timeout  time.Duration            `json:"timeout"`
+		Server   *server                  `json:"server"`
+		Backoff  []time.Duration          `json:"backoff"`
+		Deadline map[string]time.Duration `json:"deadline"`
+		Raw      time.Duration            `json:"raw"`
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.json": `{"timeout": "10s", "server": {"idleTimeout": "1m"}, "backoff": ["1s", "2s"], "deadline": {"read": "500ms"}, "raw": 1000}`,
+		"app.yaml": "timeout: 10s\nserver:\n  idle_timeout: 1m\nbackoff: [1s, 2s]\ndeadline:\n  read: 500ms\nraw: 1us\n",
+		"app.conf": `{"timeout": "10s", "server": {"idle_timeout": "1m"}, "backoff": ["1s", "2s"], "deadline": {"read": "500ms"}, "raw": 1000}`,
+	}
+	for name, content := range files {
+		cfg, err := NewConfigLoader[config]().AddFile(writeConfigFile(t, dir, name, content)).Load()
+		if err != nil {
+			t.Errorf("%s: Load failed: %v", name, err)
+			continue
+		}
+		if cfg.Timeout != 10*time.Second || cfg.Server == nil || cfg.Server.IdleTimeout != time.Minute || cfg.Raw != time.Microsecond {
+			t.Errorf("%s: unexpected config %+v", name, cfg)
+		}
+		if len(cfg.Backoff) != 2 || cfg.Backoff[1] != 2*time.Second || cfg.Deadline["read"] != 500*time.Millisecond {
+			t.Errorf("%s: unexpected durations %v %v", name, cfg.Backoff, cfg.Deadline)
+		}
+	}
+
+	bad := writeConfigFile(t, dir, "bad.json", `{"timeout": "soon"}`)
+	if _, err := NewConfigLoader[config]().AddFile(bad).Load(); err == nil {
+		t.Error("Invalid duration should fail")
+	}
+}
+
+func TestConfigLoaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewConfigLoader[logger.Config]().AddFile(filepath.Join(dir, "missing.yaml")).Load(); err == nil {
+		t.Error("Missing required file should fail")
+	}
+
+	bad := writeConfigFile(t, dir, "bad.yaml", "level: verbose\n")
+	_, err := LoadConfig(*logger.DefaultConfig(), "", bad)
+	if err == nil || !strings.Contains(err.Error(), "config validate error: invalid log level: verbose") {
+		t.Errorf("Validate error = %v", err)
+	}
+
+	broken := writeConfigFile(t, dir, "broken.json", `{"level": `)
+	if _, err := LoadConfig(*logger.DefaultConfig(), "", broken); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Errorf("Decode error = %v", err)
+	}
+}
+
+func TestConfigLoaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfigFile(t, dir, "app.yaml", "level: info\n")
+	loader := NewConfigLoader[logger.Config]().SetDefaults(*logger.DefaultConfig()).AddFile(path)
+
+	type result struct {
+		cfg *logger.Config
+		err error
+	}
+	reloads := make(chan result, 4)
+	stop := loader.Watch(10*time.Millisecond, func(cfg *logger.Config, err error) {
+		reloads <- result{cfg, err}
+	})
+	defer stop()
+
+	next := func() result {
+		select {
+		case r := <-reloads:
+			return r
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for reload")
+		}
+		return result{}
+	}
+
+	writeConfigFile(t, dir, "app.yaml", "level: warn\noutput: stdout\n")
+	if r := next(); r.err != nil || r.cfg.Level != logger.LevelWarn || r.cfg.Output != logger.OutputStdout {
+		t.Errorf("Reload = %+v, %v", r.cfg, r.err)
+	}
+
+	writeConfigFile(t, dir, "app.yaml", "level: loud\n")
+	if r := next(); r.err == nil {
+		t.Errorf("Invalid reload should report error, got %+v", r.cfg)
+	}
+
+	stop()
+	stop()
+	writeConfigFile(t, dir, "app.yaml", "level: error\n# changed after stop\n")
+	select {
+	case r := <-reloads:
+		t.Errorf("Unexpected reload after stop: %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -2,12 +2,15 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,6 +19,21 @@ type HTTPClient struct {
 	client  *http.Client
 	baseURL string
 	headers map[string]string
+
+	capture       bool            // 是否记录请求/响应
+	captureLimit  int             // 最多保留的记录数，<=0 表示不限制
+	redactHeaders []string        // 导出时需要脱敏的请求头
+	exchanges     []*HTTPExchange // 已记录的请求/响应
+	mu            sync.Mutex      // 保护 exchanges 和 random
+
+	maxRetries        int              // 最大重试次数，0=不重试
+	retryDelay        time.Duration    // 重试间隔
+	retryStatuses     []int            // 触发重试的状态码，nil 时为 429 和 5xx
+	idempotency       bool             // 是否为 POST/PATCH 自动附加幂等键
+	idempotencyHeader string           // 幂等键请求头名称
+	random            *RandomGenerator // 幂等键生成器
+
+	codec *MarshalExt // 请求体编码器，nil 时使用 JSON 且不设置 Content-Type/Accept
 }
 
 // HTTPResponse HTTP响应结构体
@@ -24,6 +42,7 @@ type HTTPResponse struct {
 	Headers    http.Header
 	Body       []byte
 	Error      error
+	Exchange   *HTTPExchange // 开启记录时的请求/响应快照
 }
 
 // NewHTTPClient 创建新的HTTP客户端
@@ -32,8 +51,12 @@ func NewHTTPClient(baseURL string) *HTTPClient {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		baseURL: baseURL,
-		headers: make(map[string]string),
+		baseURL:           baseURL,
+		headers:           make(map[string]string),
+		captureLimit:      DefaultCaptureLimit,
+		redactHeaders:     append([]string(nil), DefaultRedactHeaders...),
+		idempotencyHeader: DefaultIdempotencyHeader,
+		random:            NewRandomGenerator(),
 	}
 }
 
@@ -57,12 +80,39 @@ func (c *HTTPClient) SetHeaders(headers map[string]string) *HTTPClient {
 	return c
 }
 
+// SetRetry 设置失败重试次数和间隔（链式调用）
+// 网络错误和 SetRetryStatusCodes 指定的状态码（默认 429 和 5xx）会触发重试
+// POST/PATCH 不是幂等请求，只有带幂等键（EnableIdempotency 或显式设置幂等键请求头）时才会重试
+func (c *HTTPClient) SetRetry(maxRetries int, delay time.Duration) *HTTPClient {
+	c.maxRetries = maxRetries
+	c.retryDelay = delay
+	return c
+}
+
+// SetRetryStatusCodes 设置触发重试的响应状态码（链式调用）
+// 不传参数时恢复默认的 429 和 5xx
+func (c *HTTPClient) SetRetryStatusCodes(codes ...int) *HTTPClient {
+	if len(codes) == 0 {
+		c.retryStatuses = nil
+		return c
+	}
+	c.retryStatuses = append([]int{}, codes...)
+	return c
+}
+
 // SetBaseURL 设置基础URL（链式调用）
 func (c *HTTPClient) SetBaseURL(baseURL string) *HTTPClient {
 	c.baseURL = baseURL
 	return c
 }
 
+// SetFormat 设置请求体编码格式（链式调用）
+// 设置后请求体按该格式编码，并自动附加对应的 Content-Type 和 Accept 请求头（已显式设置时除外）
+func (c *HTTPClient) SetFormat(format MarshalFormat) *HTTPClient {
+	c.codec = DefaultMarshalExt().SetFormat(format)
+	return c
+}
+
 // SetContentType 设置Content-Type请求头（链式调用）
 func (c *HTTPClient) SetContentType(contentType string) *HTTPClient {
 	c.headers["Content-Type"] = contentType
@@ -86,6 +136,15 @@ func (c *HTTPClient) Get(path string, params map[string]string) *HTTPResponse {
 	return c.request("GET", path, params, nil)
 }
 
+// GetQuery 发送GET请求，query 可以是带 url 标签的结构体、map 或 url.Values，编码规则见 EncodeValues
+func (c *HTTPClient) GetQuery(path string, query interface{}) *HTTPResponse {
+	values, err := EncodeValues(query)
+	if err != nil {
+		return &HTTPResponse{Error: err}
+	}
+	return c.requestURL("GET", c.buildURLWithValues(path, values), nil)
+}
+
 // Post 发送POST请求
 func (c *HTTPClient) Post(path string, data interface{}) *HTTPResponse {
 	return c.request("POST", path, nil, data)
@@ -96,6 +155,17 @@ func (c *HTTPClient) PostForm(path string, formData map[string]string) *HTTPResp
 	return c.requestForm("POST", path, formData)
 }
 
+// PostFormValues 发送表单POST请求，form 可以是带 url 标签的结构体、map 或 url.Values，切片编码为重复的键
+func (c *HTTPClient) PostFormValues(path string, form interface{}) *HTTPResponse {
+	values, err := EncodeValues(form)
+	if err != nil {
+		return &HTTPResponse{Error: err}
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do("POST", c.buildURL(path, nil), []byte(values.Encode()), header)
+}
+
 // Put 发送PUT请求
 func (c *HTTPClient) Put(path string, data interface{}) *HTTPResponse {
 	return c.request("PUT", path, nil, data)
@@ -111,48 +181,60 @@ func (c *HTTPClient) Patch(path string, data interface{}) *HTTPResponse {
 	return c.request("PATCH", path, nil, data)
 }
 
+// Send 发送原始请求体，headers 仅作用于本次请求
+func (c *HTTPClient) Send(method, path string, body []byte, headers map[string]string) *HTTPResponse {
+	extra := http.Header{}
+	for k, v := range headers {
+		extra.Set(k, v)
+	}
+	return c.do(method, c.buildURL(path, nil), body, extra)
+}
+
 // request 通用请求方法
 func (c *HTTPClient) request(method, path string, params map[string]string, data interface{}) *HTTPResponse {
-	// 构建完整URL
-	fullURL := c.buildURL(path, params)
+	return c.requestURL(method, c.buildURL(path, params), data)
+}
+
+// requestURL 向完整URL发送请求
+func (c *HTTPClient) requestURL(method, fullURL string, data interface{}) *HTTPResponse {
+	if c.codec != nil {
+		return c.requestWithCodec(method, fullURL, data)
+	}
 
 	// 准备请求体
-	var body io.Reader
+	var body []byte
 	if data != nil {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return &HTTPResponse{Error: fmt.Errorf("json marshal error: %w", err)}
 		}
-		body = bytes.NewBuffer(jsonData)
-	}
-
-	// 创建请求
-	req, err := http.NewRequest(method, fullURL, body)
-	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("create request error: %w", err)}
+		body = jsonData
 	}
 
-	// 设置请求头
-	c.setHeaders(req)
+	return c.do(method, fullURL, body, nil)
+}
 
-	// 发送请求
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("request error: %w", err)}
+// requestWithCodec 按 SetFormat 设置的格式编码请求体并协商响应格式
+func (c *HTTPClient) requestWithCodec(method, fullURL string, data interface{}) *HTTPResponse {
+	contentType := c.codec.ContentType()
+	extra := http.Header{}
+	if _, ok := c.headers["Accept"]; !ok {
+		extra.Set("Accept", contentType)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("read response error: %w", err)}
+	var body []byte
+	if data != nil {
+		encoded, err := c.codec.marshal(data)
+		if err != nil {
+			return &HTTPResponse{Error: fmt.Errorf("marshal error: %w", err)}
+		}
+		body = encoded
+		if _, ok := c.headers["Content-Type"]; !ok {
+			extra.Set("Content-Type", contentType)
+		}
 	}
 
-	return &HTTPResponse{
-		StatusCode: resp.StatusCode,
-		Headers:    resp.Header,
-		Body:       respBody,
-	}
+	return c.do(method, fullURL, body, extra)
 }
 
 // requestForm 发送表单请求
@@ -165,40 +247,113 @@ func (c *HTTPClient) requestForm(method, path string, formData map[string]string
 	for k, v := range formData {
 		values.Set(k, v)
 	}
-	body := strings.NewReader(values.Encode())
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(method, fullURL, []byte(values.Encode()), header)
+}
+
+// do 发送请求并按重试配置重试，extra 中的请求头会覆盖客户端默认请求头
+func (c *HTTPClient) do(method, fullURL string, body []byte, extra http.Header) *HTTPResponse {
+	// 同一次逻辑调用的所有重试共用一个幂等键
+	if c.needsIdempotencyKey(method, extra) {
+		if extra == nil {
+			extra = http.Header{}
+		}
+		extra.Set(c.idempotencyHeader, c.newIdempotencyKey())
+	}
+
+	// 非幂等请求没有幂等键时重试可能导致重复提交
+	maxRetries := c.maxRetries
+	if isNonIdempotentMethod(method) && !c.hasIdempotencyKey(extra) {
+		maxRetries = 0
+	}
+
+	var result *HTTPResponse
+	for attempt := 0; ; attempt++ {
+		var transportErr bool
+		result, transportErr = c.doOnce(method, fullURL, body, extra)
+		if attempt >= maxRetries || !c.shouldRetry(result, transportErr) {
+			return result
+		}
+		time.Sleep(c.retryDelay)
+	}
+}
+
+// doOnce 发送单次请求并读取响应，transportErr 表示错误发生在发送或读取响应的过程中
+func (c *HTTPClient) doOnce(method, fullURL string, body []byte, extra http.Header) (result *HTTPResponse, transportErr bool) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
 
 	// 创建请求
-	req, err := http.NewRequest(method, fullURL, body)
+	req, err := http.NewRequest(method, fullURL, reader)
 	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("create request error: %w", err)}
+		return &HTTPResponse{Error: fmt.Errorf("create request error: %w", err)}, false
 	}
 
 	// 设置请求头
 	c.setHeaders(req)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, vs := range extra {
+		req.Header[k] = vs
+	}
 
 	// 发送请求
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("request error: %w", err)}
+		return &HTTPResponse{Error: fmt.Errorf("request error: %w", err)}, true
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return &HTTPResponse{Error: fmt.Errorf("read response error: %w", err)}
+		return &HTTPResponse{Error: fmt.Errorf("read response error: %w", err)}, true
 	}
 
-	return &HTTPResponse{
+	result = &HTTPResponse{
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
 		Body:       respBody,
 	}
+
+	// 记录请求/响应
+	if c.capture {
+		result.Exchange = c.record(req, body, resp, respBody, start)
+	}
+
+	return result, false
+}
+
+// shouldRetry 判断响应是否需要重试：网络错误（取消的请求除外）和指定的状态码
+func (c *HTTPClient) shouldRetry(resp *HTTPResponse, transportErr bool) bool {
+	if resp.Error != nil {
+		return transportErr && !errors.Is(resp.Error, context.Canceled)
+	}
+	if c.retryStatuses == nil {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	}
+	for _, code := range c.retryStatuses {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
 }
 
 // buildURL 构建完整URL
 func (c *HTTPClient) buildURL(path string, params map[string]string) string {
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return c.buildURLWithValues(path, values)
+}
+
+// buildURLWithValues 构建带查询参数的完整URL
+func (c *HTTPClient) buildURLWithValues(path string, values url.Values) string {
 	fullURL := c.baseURL
 	if !strings.HasSuffix(fullURL, "/") {
 		fullURL += "/"
@@ -206,11 +361,7 @@ func (c *HTTPClient) buildURL(path string, params map[string]string) string {
 	path = strings.TrimPrefix(path, "/")
 	fullURL += path
 
-	if len(params) > 0 {
-		values := url.Values{}
-		for k, v := range params {
-			values.Set(k, v)
-		}
+	if len(values) > 0 {
 		fullURL += "?" + values.Encode()
 	}
 
@@ -237,6 +388,42 @@ func (r *HTTPResponse) JSON(v interface{}) error {
 	return json.Unmarshal(r.Body, v)
 }
 
+// Decode 按响应的 Content-Type 选择格式解析响应体，无法识别时按 JSON 解析
+func (r *HTTPResponse) Decode(v interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	format, ok := FormatByContentType(r.Headers.Get("Content-Type"))
+	if !ok {
+		format = JSONFormat
+	}
+	return DefaultMarshalExt().SetFormat(format).Unmarshal(r.Body, v)
+}
+
+// Pointer 按 JSON Pointer 读取 JSON 响应体中的值，需要具体类型时使用 GetPointer[T](r.Body, pointer)
+func (r *HTTPResponse) Pointer(pointer string) (interface{}, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(r.Body)
+}
+
+// Query 按 JSONPath 查询 JSON 响应体，需要具体类型时使用 QueryPath[T](r.Body, path)
+func (r *HTTPResponse) Query(path string) ([]interface{}, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	compiled, err := CompileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Select(r.Body)
+}
+
 // IsSuccess 判断请求是否成功
 func (r *HTTPResponse) IsSuccess() bool {
 	return r.Error == nil && r.StatusCode >= 200 && r.StatusCode < 300
@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RedactedValue 脱敏后的请求头取值
+const RedactedValue = "[REDACTED]"
+
+// DefaultCaptureLimit 默认最多保留的请求/响应记录数
+const DefaultCaptureLimit = 100
+
+// DefaultRedactHeaders 默认需要脱敏的请求头
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// HTTPExchange 一次请求/响应的快照
+type HTTPExchange struct {
+	Method          string
+	URL             string
+	RequestHeaders  http.Header
+	RequestBody     []byte
+	StatusCode      int
+	Status          string
+	Proto           string
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+	StartedAt       time.Time
+	Duration        time.Duration
+
+	redactHeaders []string
+}
+
+// EnableCapture 开启或关闭请求/响应记录（链式调用）
+func (c *HTTPClient) EnableCapture(enable bool) *HTTPClient {
+	c.capture = enable
+	return c
+}
+
+// SetCaptureLimit 设置最多保留的记录数，超出时丢弃最早的记录，<=0 表示不限制（链式调用）
+func (c *HTTPClient) SetCaptureLimit(limit int) *HTTPClient {
+	c.mu.Lock()
+	c.captureLimit = limit
+	c.trimExchanges()
+	c.mu.Unlock()
+	return c
+}
+
+// SetRedactHeaders 设置导出时需要脱敏的请求头，覆盖默认列表（链式调用）
+func (c *HTTPClient) SetRedactHeaders(headers ...string) *HTTPClient {
+	c.redactHeaders = append([]string(nil), headers...)
+	return c
+}
+
+// Exchanges 返回已记录的请求/响应
+func (c *HTTPClient) Exchanges() []*HTTPExchange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*HTTPExchange(nil), c.exchanges...)
+}
+
+// ClearExchanges 清空已记录的请求/响应
+func (c *HTTPClient) ClearExchanges() {
+	c.mu.Lock()
+	c.exchanges = nil
+	c.mu.Unlock()
+}
+
+// ExportHAR 将已记录的请求/响应导出为 HAR 1.2 JSON
+func (c *HTTPClient) ExportHAR() ([]byte, error) {
+	return ExportHAR(c.Exchanges()...)
+}
+
+// record 保存一次请求/响应
+func (c *HTTPClient) record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, start time.Time) *HTTPExchange {
+	exchange := &HTTPExchange{
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		RequestHeaders:  req.Header.Clone(),
+		RequestBody:     reqBody,
+		StatusCode:      resp.StatusCode,
+		Status:          resp.Status,
+		Proto:           resp.Proto,
+		ResponseHeaders: resp.Header.Clone(),
+		ResponseBody:    respBody,
+		StartedAt:       start,
+		Duration:        time.Since(start),
+		redactHeaders:   c.redactHeaders,
+	}
+
+	c.mu.Lock()
+	c.exchanges = append(c.exchanges, exchange)
+	c.trimExchanges()
+	c.mu.Unlock()
+
+	return exchange
+}
+
+// trimExchanges 丢弃超出上限的最早记录，调用方需持有 c.mu
+func (c *HTTPClient) trimExchanges() {
+	if c.captureLimit <= 0 || len(c.exchanges) <= c.captureLimit {
+		return
+	}
+	drop := len(c.exchanges) - c.captureLimit
+	c.exchanges = append(c.exchanges[:0:0], c.exchanges[drop:]...)
+}
+
+// isRedacted 判断请求头是否需要脱敏
+func (e *HTTPExchange) isRedacted(name string) bool {
+	for _, h := range e.redactHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedHeaders 返回排序后的脱敏请求头
+func (e *HTTPExchange) sortedHeaders(header http.Header) []harNameValue {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []harNameValue
+	for _, k := range keys {
+		for _, v := range header[k] {
+			if e.isRedacted(k) {
+				v = RedactedValue
+			}
+			result = append(result, harNameValue{Name: k, Value: v})
+		}
+	}
+	return result
+}
+
+// Curl 将请求导出为可执行的 curl 命令
+func (e *HTTPExchange) Curl() string {
+	var b strings.Builder
+	b.WriteString("curl")
+	if e.Method != http.MethodGet || len(e.RequestBody) > 0 {
+		b.WriteString(" -X " + e.Method)
+	}
+	b.WriteString(" " + shellQuote(e.URL))
+	for _, h := range e.sortedHeaders(e.RequestHeaders) {
+		b.WriteString(" -H " + shellQuote(h.Name+": "+h.Value))
+	}
+	if len(e.RequestBody) > 0 {
+		b.WriteString(" --data-raw " + shellQuote(string(e.RequestBody)))
+	}
+	return b.String()
+}
+
+// shellQuote 使用单引号转义 shell 参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// HAR 1.2 结构定义
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBody        `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harBody struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harEntry 转换为 HAR 条目
+func (e *HTTPExchange) harEntry() harEntry {
+	millis := float64(e.Duration) / float64(time.Millisecond)
+
+	var query []harNameValue
+	if u, err := url.Parse(e.URL); err == nil {
+		keys := make([]string, 0)
+		values := u.Query()
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, v := range values[k] {
+				query = append(query, harNameValue{Name: k, Value: v})
+			}
+		}
+	}
+
+	proto := e.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	entry := harEntry{
+		StartedDateTime: e.StartedAt.Format(time.RFC3339Nano),
+		Time:            millis,
+		Request: harRequest{
+			Method:      e.Method,
+			URL:         e.URL,
+			HTTPVersion: proto,
+			Cookies:     []harNameValue{},
+			Headers:     nonNil(e.sortedHeaders(e.RequestHeaders)),
+			QueryString: nonNil(query),
+			HeadersSize: -1,
+			BodySize:    len(e.RequestBody),
+		},
+		Response: harResponse{
+			Status:      e.StatusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(e.Status, fmt.Sprint(e.StatusCode))),
+			HTTPVersion: proto,
+			Cookies:     []harNameValue{},
+			Headers:     nonNil(e.sortedHeaders(e.ResponseHeaders)),
+			Content: harBody{
+				Size:     len(e.ResponseBody),
+				MimeType: e.ResponseHeaders.Get("Content-Type"),
+				Text:     string(e.ResponseBody),
+			},
+			HeadersSize: -1,
+			BodySize:    len(e.ResponseBody),
+		},
+		Timings: harTimings{Wait: millis},
+	}
+
+	if len(e.RequestBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: e.RequestHeaders.Get("Content-Type"),
+			Text:     string(e.RequestBody),
+		}
+	}
+
+	return entry
+}
+
+// nonNil 保证 HAR 中的数组字段不为 null
+func nonNil(values []harNameValue) []harNameValue {
+	if values == nil {
+		return []harNameValue{}
+	}
+	return values
+}
+
+// HAR 将单个请求/响应导出为 HAR 1.2 JSON
+func (e *HTTPExchange) HAR() ([]byte, error) {
+	return ExportHAR(e)
+}
+
+// ExportHAR 将请求/响应导出为 HAR 1.2 JSON
+func ExportHAR(exchanges ...*HTTPExchange) ([]byte, error) {
+	log := harLog{
+		Log: harContent{
+			Version: "1.2",
+			Creator: harCreator{Name: "github.com/so68/utils", Version: "1.0"},
+			Entries: make([]harEntry, 0, len(exchanges)),
+		},
+	}
+	for _, e := range exchanges {
+		log.Log.Entries = append(log.Log.Entries, e.harEntry())
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
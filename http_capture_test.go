@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+HTTP请求记录与导出测试
+
+运行命令：
+go test -v -run "^Test.*Capture.*$|^Test.*HAR.*$|^Test.*Curl.*$"
+
+测试内容：
+1. 请求/响应记录 (EnableCapture, Exchanges, ClearExchanges, SetCaptureLimit)
+2. curl 命令导出及请求头脱敏
+3. HAR 1.2 导出
+*/
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+}
+
+func TestHTTPCapture(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	resp := client.Post("/items", map[string]string{"name": "x"})
+	if resp.Exchange != nil {
+		t.Error("Exchange should be nil when capture is disabled")
+	}
+
+	client.EnableCapture(true)
+	resp = client.Post("/items", map[string]string{"name": "x"})
+	if resp.Error != nil {
+		t.Fatalf("Request failed: %v", resp.Error)
+	}
+	if resp.Exchange == nil {
+		t.Fatal("Exchange should be recorded when capture is enabled")
+	}
+	if resp.Exchange.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.Exchange.StatusCode)
+	}
+	if len(client.Exchanges()) != 1 {
+		t.Errorf("Expected 1 exchange, got %d", len(client.Exchanges()))
+	}
+
+	client.ClearExchanges()
+	if len(client.Exchanges()) != 0 {
+		t.Error("ClearExchanges should remove recorded exchanges")
+	}
+}
+
+func TestCaptureLimit(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).EnableCapture(true).SetCaptureLimit(2)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		if resp := client.Get(path, nil); resp.Error != nil {
+			t.Fatalf("Request failed: %v", resp.Error)
+		}
+	}
+
+	exchanges := client.Exchanges()
+	if len(exchanges) != 2 {
+		t.Fatalf("Expected 2 exchanges, got %d", len(exchanges))
+	}
+	if !strings.HasSuffix(exchanges[0].URL, "/b") || !strings.HasSuffix(exchanges[1].URL, "/c") {
+		t.Errorf("Expected the oldest exchange to be dropped, got %s and %s", exchanges[0].URL, exchanges[1].URL)
+	}
+
+	client.SetCaptureLimit(1)
+	if exchanges = client.Exchanges(); len(exchanges) != 1 || !strings.HasSuffix(exchanges[0].URL, "/c") {
+		t.Errorf("Lowering the limit should keep only the newest exchange, got %d", len(exchanges))
+	}
+}
+
+func TestCurlExport(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).
+		EnableCapture(true).
+		SetAuthorization("Bearer secret-token").
+		SetContentType("application/json")
+
+	resp := client.Post("/items", map[string]string{"name": "it's"})
+	if resp.Error != nil {
+		t.Fatalf("Request failed: %v", resp.Error)
+	}
+
+	curl := resp.Exchange.Curl()
+	expected := "curl -X POST '" + server.URL + "/items' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json' --data-raw '{\"name\":\"it'\\''s\"}'"
+	if curl != expected {
+		t.Errorf("Unexpected curl command:\n got: %s\nwant: %s", curl, expected)
+	}
+
+	client.SetRedactHeaders()
+	resp = client.Get("/items", map[string]string{"q": "1"})
+	curl = resp.Exchange.Curl()
+	if !strings.Contains(curl, "Bearer secret-token") {
+		t.Errorf("Authorization should not be redacted after SetRedactHeaders(): %s", curl)
+	}
+	if strings.Contains(curl, "-X") {
+		t.Errorf("GET without body should omit -X: %s", curl)
+	}
+}
+
+func TestExportHAR(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).
+		EnableCapture(true).
+		SetAuthorization("Bearer secret-token")
+	client.Get("/items", map[string]string{"page": "2"})
+	client.PostForm("/login", map[string]string{"user": "a"})
+
+	data, err := client.ExportHAR()
+	if err != nil {
+		t.Fatalf("ExportHAR failed: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("HAR output should not contain redacted values")
+	}
+
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Request struct {
+					Method      string `json:"method"`
+					QueryString []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"queryString"`
+					PostData *struct {
+						MimeType string `json:"mimeType"`
+						Text     string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+				Response struct {
+					Status     int    `json:"status"`
+					StatusText string `json:"statusText"`
+					Content    struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatalf("HAR output is not valid JSON: %v", err)
+	}
+
+	if har.Log.Version != "1.2" {
+		t.Errorf("Expected HAR version 1.2, got %s", har.Log.Version)
+	}
+	if len(har.Log.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(har.Log.Entries))
+	}
+
+	get := har.Log.Entries[0]
+	if get.Request.Method != "GET" || len(get.Request.QueryString) != 1 || get.Request.QueryString[0].Value != "2" {
+		t.Errorf("Unexpected GET entry: %+v", get.Request)
+	}
+	if get.Response.Status != 201 || get.Response.StatusText != "Created" || get.Response.Content.Text != `{"ok":true}` {
+		t.Errorf("Unexpected response entry: %+v", get.Response)
+	}
+
+	post := har.Log.Entries[1]
+	if post.Request.PostData == nil || post.Request.PostData.Text != "user=a" ||
+		post.Request.PostData.MimeType != "application/x-www-form-urlencoded" {
+		t.Errorf("Unexpected postData: %+v", post.Request.PostData)
+	}
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLRequest GraphQL 请求
+type GraphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+
+	// Persisted 为 true 时先只发送查询哈希（Automatic Persisted Queries），
+	// 服务端未缓存时再附带完整查询重发
+	Persisted bool `json:"-"`
+}
+
+// GraphQLLocation 错误在查询中的位置
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError GraphQL 错误
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLErrors 响应中的 errors 数组
+type GraphQLErrors []*GraphQLError
+
+// GraphQLResponse GraphQL 响应
+type GraphQLResponse struct {
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Errors     GraphQLErrors          `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *GraphQLError) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("%s (path: %s)", e.Message, e.PathString())
+	}
+	return e.Message
+}
+
+// PathString 返回点分隔的错误路径，例如 user.friends.0.name
+func (e *GraphQLError) PathString() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Code 返回 extensions.code
+func (e *GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Error 实现 error 接口
+func (errs GraphQLErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// PersistedQueryHash 计算持久化查询使用的 SHA-256 哈希
+func PersistedQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// GraphQL 发送 GraphQL 请求并将 data 解析到 target
+// 响应包含 errors 时返回 GraphQLErrors，此时已返回的部分 data 仍会解析到 target
+func (c *HTTPClient) GraphQL(path string, req GraphQLRequest, target interface{}) error {
+	resp, err := c.GraphQLRaw(path, req)
+	if err != nil {
+		return err
+	}
+
+	if target != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, target); err != nil {
+			return fmt.Errorf("graphql decode data: %w", err)
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+// GraphQLRaw 发送 GraphQL 请求并返回原始响应
+func (c *HTTPClient) GraphQLRaw(path string, req GraphQLRequest) (*GraphQLResponse, error) {
+	if !req.Persisted {
+		return c.sendGraphQL(path, req)
+	}
+
+	// 先只发送哈希
+	extensions := make(map[string]interface{}, len(req.Extensions)+1)
+	for k, v := range req.Extensions {
+		extensions[k] = v
+	}
+	extensions["persistedQuery"] = map[string]interface{}{
+		"version":    1,
+		"sha256Hash": PersistedQueryHash(req.Query),
+	}
+	req.Extensions = extensions
+
+	query := req.Query
+	req.Query = ""
+	resp, err := c.sendGraphQL(path, req)
+	if err != nil || !resp.Errors.persistedQueryNotFound() {
+		return resp, err
+	}
+
+	// 服务端未缓存，附带完整查询重发
+	req.Query = query
+	return c.sendGraphQL(path, req)
+}
+
+// sendGraphQL 发送单次 GraphQL 请求
+func (c *HTTPClient) sendGraphQL(path string, req GraphQLRequest) (*GraphQLResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal error: %w", err)
+	}
+
+	httpResp := c.Send(http.MethodPost, path, body, map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/graphql-response+json, application/json",
+	})
+	if httpResp.Error != nil {
+		return nil, httpResp.Error
+	}
+
+	// 部分服务端在 4xx/5xx 时同样返回标准 errors 数组
+	var resp GraphQLResponse
+	if err := json.Unmarshal(httpResp.Body, &resp); err != nil || (resp.Data == nil && resp.Errors == nil) {
+		if !httpResp.IsSuccess() {
+			return nil, fmt.Errorf("graphql http status %d: %s", httpResp.StatusCode, httpResp.String())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graphql decode response: %w", err)
+		}
+	}
+	return &resp, nil
+}
+
+// persistedQueryNotFound 判断是否为持久化查询未命中
+func (errs GraphQLErrors) persistedQueryNotFound() bool {
+	for _, e := range errs {
+		if e.Message == "PersistedQueryNotFound" || e.Code() == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+/*
+GraphQL 客户端测试
+
+运行命令：
+go test -v -run "^Test.*GraphQL.*$"
+
+测试内容：
+1. query/variables/operationName 发送及 data 解析
+2. errors 数组解析为 GraphQLErrors（路径和扩展信息）
+3. 持久化查询哈希及未命中重发
+*/
+
+func TestGraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+
+		if req.OperationName != "GetUser" || req.Variables["id"] != "1" {
+			t.Errorf("Unexpected request %s", body)
+		}
+		w.Write([]byte(`{"data":{"user":{"id":"1","name":"alice","friends":[null]}},
+			"errors":[{"message":"friend not found","locations":[{"line":3,"column":5}],
+			"path":["user","friends",0],"extensions":{"code":"NOT_FOUND"}}]}`))
+	}))
+	defer server.Close()
+
+	var data struct {
+		User struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	err := NewHTTPClient(server.URL).GraphQL("/graphql", GraphQLRequest{
+		Query:         "query GetUser($id: ID!) { user(id: $id) { id name friends { name } } }",
+		Variables:     map[string]interface{}{"id": "1"},
+		OperationName: "GetUser",
+	}, &data)
+
+	if data.User.Name != "alice" {
+		t.Errorf("Partial data should be decoded, got %+v", data)
+	}
+
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) || len(gqlErrs) != 1 {
+		t.Fatalf("Expected GraphQLErrors, got %v", err)
+	}
+	e := gqlErrs[0]
+	if e.PathString() != "user.friends.0" || e.Code() != "NOT_FOUND" || e.Locations[0].Line != 3 {
+		t.Errorf("Unexpected error %+v", e)
+	}
+	if err.Error() != "graphql: friend not found (path: user.friends.0)" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+}
+
+func TestGraphQLHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream down"))
+	}))
+	defer server.Close()
+
+	err := NewHTTPClient(server.URL).GraphQL("/graphql", GraphQLRequest{Query: "{ a }"}, nil)
+	if err == nil || err.Error() != "graphql http status 502: upstream down" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestGraphQLPersistedQuery(t *testing.T) {
+	query := "{ viewer { id } }"
+	cached := false
+	var requests []GraphQLRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		pq, _ := req.Extensions["persistedQuery"].(map[string]interface{})
+		if pq["sha256Hash"] != PersistedQueryHash(query) {
+			t.Errorf("Unexpected persisted query extension %v", req.Extensions)
+		}
+		if req.Query == "" && !cached {
+			w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+		cached = true
+		w.Write([]byte(`{"data":{"viewer":{"id":"v1"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	for i := 0; i < 2; i++ {
+		var data struct {
+			Viewer struct{ ID string } `json:"viewer"`
+		}
+		if err := client.GraphQL("/graphql", GraphQLRequest{Query: query, Persisted: true}, &data); err != nil {
+			t.Fatalf("GraphQL failed: %v", err)
+		}
+		if data.Viewer.ID != "v1" {
+			t.Errorf("Unexpected data %+v", data)
+		}
+	}
+
+	// 首次：哈希 -> 未命中 -> 完整查询；第二次：仅哈希
+	if len(requests) != 3 || requests[0].Query != "" || requests[1].Query != query || requests[2].Query != "" {
+		t.Errorf("Unexpected request sequence %+v", requests)
+	}
+}
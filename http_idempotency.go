@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"net/http"
+)
+
+// DefaultIdempotencyHeader 默认幂等键请求头
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// EnableIdempotency 开启或关闭 POST/PATCH 请求自动附加幂等键（链式调用）
+// 同一次调用的所有重试使用相同的幂等键
+func (c *HTTPClient) EnableIdempotency(enable bool) *HTTPClient {
+	c.idempotency = enable
+	return c
+}
+
+// SetIdempotencyHeader 设置幂等键请求头名称（链式调用）
+func (c *HTTPClient) SetIdempotencyHeader(header string) *HTTPClient {
+	c.idempotencyHeader = header
+	return c
+}
+
+// needsIdempotencyKey 判断本次调用是否需要生成幂等键
+// 已通过 SetHeader 或调用参数显式指定时不再生成
+func (c *HTTPClient) needsIdempotencyKey(method string, extra http.Header) bool {
+	if !c.idempotency || !isNonIdempotentMethod(method) {
+		return false
+	}
+	return !c.hasIdempotencyKey(extra)
+}
+
+// hasIdempotencyKey 判断调用参数或客户端请求头中是否已带有幂等键
+func (c *HTTPClient) hasIdempotencyKey(extra http.Header) bool {
+	if c.idempotencyHeader == "" {
+		return false
+	}
+	if extra.Get(c.idempotencyHeader) != "" {
+		return true
+	}
+	for k, v := range c.headers {
+		if v != "" && http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(c.idempotencyHeader) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNonIdempotentMethod 判断请求方法是否非幂等，非幂等请求只有带幂等键时才会重试
+func isNonIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch
+}
+
+// newIdempotencyKey 生成新的幂等键
+func (c *HTTPClient) newIdempotencyKey() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.random.UUID()
+}
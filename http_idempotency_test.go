@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+幂等键与重试测试
+
+运行命令：
+go test -v -run "^Test.*Idempotency.*$|^TestRetry.*$"
+
+测试内容：
+1. POST/PATCH 自动附加幂等键
+2. 同一次调用的重试复用幂等键
+3. 安全方法及显式指定时不生成幂等键
+4. 只重试网络错误和指定的状态码
+5. POST/PATCH 没有幂等键时不重试
+*/
+
+// newKeyRecorder 记录每次请求的幂等键，前 failures 次返回 503
+func newKeyRecorder(failures int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(DefaultIdempotencyHeader))
+		n := len(keys)
+		mu.Unlock()
+		if n <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestIdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	server, keys := newKeyRecorder(2)
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).EnableIdempotency(true).SetRetry(3, 0)
+	resp := client.Post("/pay", map[string]int{"amount": 1})
+	if !resp.IsSuccess() {
+		t.Fatalf("Expected success after retries, got %d %v", resp.StatusCode, resp.Error)
+	}
+
+	got := keys()
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(got))
+	}
+	if got[0] == "" || len(got[0]) != 36 {
+		t.Errorf("Expected UUID idempotency key, got %q", got[0])
+	}
+	for _, k := range got[1:] {
+		if k != got[0] {
+			t.Errorf("Retries should reuse key %q, got %q", got[0], k)
+		}
+	}
+
+	// 新的逻辑调用使用新的幂等键
+	client.Patch("/pay", nil)
+	got = keys()
+	if got[len(got)-1] == "" || got[len(got)-1] == got[0] {
+		t.Errorf("New call should use a new key, got %q", got[len(got)-1])
+	}
+}
+
+func TestIdempotencyKeySkipped(t *testing.T) {
+	server, keys := newKeyRecorder(0)
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	client.Post("/pay", nil)
+
+	client.EnableIdempotency(true)
+	client.Put("/pay", nil)
+	client.Get("/pay", nil)
+
+	client.SetHeader("idempotency-key", "fixed")
+	client.Post("/pay", nil)
+
+	got := keys()
+	for i, k := range got[:3] {
+		if k != "" {
+			t.Errorf("Request %d should not carry an idempotency key, got %q", i, k)
+		}
+	}
+	if got[3] != "fixed" {
+		t.Errorf("Explicit header should be kept, got %q", got[3])
+	}
+}
+
+func TestIdempotencyCustomHeader(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Request-Key")
+	}))
+	defer server.Close()
+
+	NewHTTPClient(server.URL).
+		EnableIdempotency(true).
+		SetIdempotencyHeader("X-Request-Key").
+		PostForm("/pay", map[string]string{"a": "b"})
+	if got == "" {
+		t.Error("Expected idempotency key in custom header")
+	}
+}
+
+func TestRetryStopsOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	resp := NewHTTPClient(server.URL).SetRetry(3, 0).Put("/pay", nil)
+	if resp.StatusCode != http.StatusBadRequest || attempts != 1 {
+		t.Errorf("4xx should not be retried, got status %d after %d attempts", resp.StatusCode, attempts)
+	}
+}
+
+func TestRetryStatusCodes(t *testing.T) {
+	server, keys := newKeyRecorder(5)
+	defer server.Close()
+
+	resp := NewHTTPClient(server.URL).SetRetry(3, 0).SetRetryStatusCodes(http.StatusTooManyRequests).Get("/", nil)
+	if resp.StatusCode != http.StatusServiceUnavailable || len(keys()) != 1 {
+		t.Errorf("503 should not be retried when not configured, got status %d after %d attempts", resp.StatusCode, len(keys()))
+	}
+}
+
+func TestRetryTransportError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// 直接断开连接，模拟网络错误
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp := NewHTTPClient(server.URL).SetRetry(2, 0).Get("/", nil)
+	if resp.Error != nil || resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("Transport error should be retried, got %v, status %d after %d attempts", resp.Error, resp.StatusCode, attempts)
+	}
+}
+
+func TestRetrySkipsRequestError(t *testing.T) {
+	start := time.Now()
+	resp := NewHTTPClient("http://127.0.0.1").SetRetry(3, 200*time.Millisecond).Get("/%zz", nil)
+	if resp.Error == nil || time.Since(start) >= 200*time.Millisecond {
+		t.Errorf("Request construction error should not be retried, got %v after %v", resp.Error, time.Since(start))
+	}
+}
+
+func TestRetryRequiresIdempotencyKey(t *testing.T) {
+	server, keys := newKeyRecorder(2)
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).SetRetry(3, 0)
+	resp := client.Post("/pay", nil)
+	if resp.StatusCode != http.StatusServiceUnavailable || len(keys()) != 1 {
+		t.Errorf("POST without idempotency key should not be retried, got status %d after %d attempts", resp.StatusCode, len(keys()))
+	}
+
+	resp = client.SetHeader(DefaultIdempotencyHeader, "fixed").Patch("/pay", nil)
+	if resp.StatusCode != http.StatusOK || len(keys()) != 3 {
+		t.Errorf("PATCH with explicit idempotency key should be retried, got status %d after %d attempts", resp.StatusCode, len(keys()))
+	}
+}
@@ -0,0 +1,130 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/so68/utils"
+)
+
+// HTTPClient 基于 utils.HTTPClient 的 JSON-RPC 客户端
+type HTTPClient struct {
+	client *utils.HTTPClient // 底层 HTTP 客户端
+	path   string            // 请求路径
+	nextID int64             // 请求ID计数器
+}
+
+// NewHTTPClient 创建 JSON-RPC HTTP 客户端
+func NewHTTPClient(client *utils.HTTPClient, path string) *HTTPClient {
+	return &HTTPClient{client: client, path: path}
+}
+
+// Call 调用方法并将结果解析到 result
+func (c *HTTPClient) Call(method string, params interface{}, result interface{}) error {
+	id := atomic.AddInt64(&c.nextID, 1)
+	body, err := json.Marshal(Request{JSONRPC: Version, ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	data, err := c.post(body)
+	if err != nil {
+		return err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	// id 为 null 的错误响应表示服务端无法识别请求，仍按该错误返回
+	if key := idKey(resp.ID); key != fmt.Sprint(id) && !(resp.Error != nil && isNullID(resp.ID)) {
+		return fmt.Errorf("response id %s does not match request %d", key, id)
+	}
+	return decodeResult(&resp, result)
+}
+
+// Notify 发送通知，不等待结果
+func (c *HTTPClient) Notify(method string, params interface{}) error {
+	body, err := json.Marshal(Request{JSONRPC: Version, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	_, err = c.post(body)
+	return err
+}
+
+// Batch 发送批量调用，每个调用的错误写入对应的 BatchCall.Error
+// 返回的错误仅表示整个批量请求失败
+func (c *HTTPClient) Batch(calls []*BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	requests := make([]Request, len(calls))
+	pending := make(map[string]*BatchCall, len(calls))
+	for i, call := range calls {
+		requests[i] = Request{JSONRPC: Version, Method: call.Method, Params: call.Params}
+		if !call.Notify {
+			call.id = atomic.AddInt64(&c.nextID, 1)
+			requests[i].ID = call.id
+			pending[fmt.Sprint(call.id)] = call
+		}
+	}
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return fmt.Errorf("marshal batch: %w", err)
+	}
+
+	data, err := c.post(body)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	messages, err := parseMessages(data)
+	if err != nil {
+		return fmt.Errorf("decode batch response: %w", err)
+	}
+
+	for _, raw := range messages {
+		var resp Response
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return fmt.Errorf("decode batch response: %w", err)
+		}
+		call, ok := pending[idKey(resp.ID)]
+		if !ok {
+			// 无法对应到调用的错误（例如整个批量无效）
+			if resp.Error != nil {
+				return resp.Error
+			}
+			continue
+		}
+		call.Error = decodeResult(&resp, call.Result)
+		delete(pending, idKey(resp.ID))
+	}
+
+	for _, call := range pending {
+		call.Error = fmt.Errorf("no response for request %d", call.id)
+	}
+	return nil
+}
+
+// post 发送请求体并返回响应体
+func (c *HTTPClient) post(body []byte) ([]byte, error) {
+	resp := c.client.Send(http.MethodPost, c.path, body, map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	})
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("jsonrpc http status %d: %s", resp.StatusCode, resp.String())
+	}
+	return resp.Body, nil
+}
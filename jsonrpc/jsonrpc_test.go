@@ -0,0 +1,280 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/so68/utils"
+	"github.com/so68/utils/socket/client"
+)
+
+/*
+JSON-RPC 2.0 客户端测试
+
+运行命令：
+go test -v ./jsonrpc
+
+测试内容：
+1. HTTP 单次调用、通知和批量调用
+2. 错误对象解析
+3. WebSocket 请求/响应关联、服务端通知和超时
+4. id 为 null 的错误响应及响应 ID 校验
+*/
+
+// serve 处理单个请求，返回 nil 表示通知无需响应
+func serve(req map[string]interface{}) map[string]interface{} {
+	id, hasID := req["id"]
+	if !hasID {
+		return nil
+	}
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	params, _ := req["params"].([]interface{})
+	switch req["method"] {
+	case "add":
+		sum := 0.0
+		for _, p := range params {
+			sum += p.(float64)
+		}
+		resp["result"] = sum
+	case "echo":
+		resp["result"] = params
+	case "slow":
+		time.Sleep(200 * time.Millisecond)
+		resp["result"] = "late"
+	case "invalid":
+		// 模拟服务端无法识别请求 ID
+		resp["id"] = nil
+		resp["error"] = map[string]interface{}{"code": CodeInvalidRequest, "message": "Invalid Request"}
+	case "wrongid":
+		resp["id"] = "other"
+		resp["result"] = true
+	default:
+		resp["error"] = map[string]interface{}{
+			"code":    CodeMethodNotFound,
+			"message": "Method not found",
+			"data":    map[string]string{"method": req["method"].(string)},
+		}
+	}
+	return resp
+}
+
+// handle 处理单个或批量请求
+func handle(body []byte) []byte {
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		var batch []map[string]interface{}
+		json.Unmarshal(body, &batch)
+		var out []map[string]interface{}
+		// 逆序返回，验证按 ID 关联
+		for i := len(batch) - 1; i >= 0; i-- {
+			if resp := serve(batch[i]); resp != nil {
+				out = append(out, resp)
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		data, _ := json.Marshal(out)
+		return data
+	}
+	var req map[string]interface{}
+	json.Unmarshal(body, &req)
+	resp := serve(req)
+	if resp == nil {
+		return nil
+	}
+	data, _ := json.Marshal(resp)
+	return data
+}
+
+func newHTTPServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected Content-Type %q", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		out := handle(body)
+		if out == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write(out)
+	}))
+}
+
+func TestHTTPCall(t *testing.T) {
+	server := newHTTPServer(t)
+	defer server.Close()
+
+	rpc := NewHTTPClient(utils.NewHTTPClient(server.URL), "/rpc")
+
+	var sum int
+	if err := rpc.Call("add", []int{1, 2, 3}, &sum); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if sum != 6 {
+		t.Errorf("Expected 6, got %d", sum)
+	}
+
+	err := rpc.Call("missing", nil, nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Expected code %d, got %d", CodeMethodNotFound, rpcErr.Code)
+	}
+	var data map[string]string
+	if err := rpcErr.DecodeData(&data); err != nil || data["method"] != "missing" {
+		t.Errorf("Unexpected error data %v (%v)", data, err)
+	}
+
+	if err := rpc.Call("invalid", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidRequest {
+		t.Errorf("Expected invalid request error, got %v", err)
+	}
+	if err := rpc.Call("wrongid", nil, nil); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected id mismatch error, got %v", err)
+	}
+
+	if err := rpc.Notify("log", []string{"hello"}); err != nil {
+		t.Errorf("Notify failed: %v", err)
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	server := newHTTPServer(t)
+	defer server.Close()
+
+	rpc := NewHTTPClient(utils.NewHTTPClient(server.URL), "/rpc")
+
+	var sum int
+	var echo []string
+	calls := []*BatchCall{
+		{Method: "add", Params: []int{2, 3}, Result: &sum},
+		{Method: "log", Params: []string{"x"}, Notify: true},
+		{Method: "echo", Params: []string{"a", "b"}, Result: &echo},
+		{Method: "missing"},
+	}
+	if err := rpc.Batch(calls); err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	if calls[0].Error != nil || sum != 5 {
+		t.Errorf("Unexpected add result %d (%v)", sum, calls[0].Error)
+	}
+	if calls[1].Error != nil {
+		t.Errorf("Notification should not fail: %v", calls[1].Error)
+	}
+	if calls[2].Error != nil || strings.Join(echo, ",") != "a,b" {
+		t.Errorf("Unexpected echo result %v (%v)", echo, calls[2].Error)
+	}
+	var rpcErr *Error
+	if !errors.As(calls[3].Error, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Expected method not found, got %v", calls[3].Error)
+	}
+}
+
+func newWSServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var writeMu sync.Mutex
+		// 连接后推送一条通知
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"tick","params":{"n":1}}`))
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			go func() {
+				if out := handle(message); out != nil {
+					writeMu.Lock()
+					conn.WriteMessage(websocket.TextMessage, out)
+					writeMu.Unlock()
+				}
+			}()
+		}
+	}))
+}
+
+func TestWSClient(t *testing.T) {
+	server := newWSServer(t)
+	defer server.Close()
+
+	notifications := make(chan *Notification, 1)
+	rpc := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http")).
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))).
+		SetTimeout(100 * time.Millisecond).
+		SetNotificationHandler(func(n *Notification) { notifications <- n })
+	config := client.DefaultConfig()
+	config.MaxRetries = 1
+	config.RetryDelay = 0
+	rpc.Websocket().SetConfig(config)
+
+	if err := rpc.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer rpc.Close()
+
+	select {
+	case n := <-notifications:
+		if n.Method != "tick" || string(n.Params) != `{"n":1}` {
+			t.Errorf("Unexpected notification %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Error("Notification not received")
+	}
+
+	// 并发调用按 ID 关联
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			var sum int
+			if err := rpc.Call("add", []int{i, 100}, &sum); err != nil {
+				errs <- err
+				return
+			}
+			if sum != i+100 {
+				errs <- errors.New("mismatched response")
+				return
+			}
+			errs <- nil
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Concurrent call failed: %v", err)
+		}
+	}
+
+	var rpcErr *Error
+	if err := rpc.Call("missing", nil, nil); !errors.As(err, &rpcErr) {
+		t.Errorf("Expected *Error, got %v", err)
+	}
+	if err := rpc.Call("invalid", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidRequest {
+		t.Errorf("Error response with null id should fail the pending call, got %v", err)
+	}
+
+	if err := rpc.Call("slow", nil, nil); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Expected timeout, got %v", err)
+	}
+
+	if err := rpc.Notify("log", nil); err != nil {
+		t.Errorf("Notify failed: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version JSON-RPC 协议版本
+const Version = "2.0"
+
+// 标准错误码
+const (
+	CodeParseError     = -32700 // 解析错误
+	CodeInvalidRequest = -32600 // 无效请求
+	CodeMethodNotFound = -32601 // 方法不存在
+	CodeInvalidParams  = -32602 // 无效参数
+	CodeInternalError  = -32603 // 内部错误
+)
+
+// Request JSON-RPC 请求，ID 为空时表示通知
+type Request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Response JSON-RPC 响应
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification 服务端推送的通知
+type Notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// NotificationHandler 通知处理器
+type NotificationHandler func(notification *Notification)
+
+// Error JSON-RPC 错误对象
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("jsonrpc error %d: %s (%s)", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// DecodeData 将错误附加数据解析到 v
+func (e *Error) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// BatchCall 批量调用中的单个调用
+type BatchCall struct {
+	Method string      // 方法名
+	Params interface{} // 参数
+	Result interface{} // 结果接收对象，为 nil 时忽略结果
+	Notify bool        // 是否为通知（不期待响应）
+	Error  error       // 调用错误，Do 之后填充
+	id     int64
+}
+
+// decodeResult 将响应解析到 result
+func decodeResult(resp *Response, result interface{}) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("decode result: %w", err)
+	}
+	return nil
+}
+
+// idKey 将响应 ID 规范化为映射键
+func idKey(raw json.RawMessage) string {
+	var id interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&id); err != nil {
+		return string(raw)
+	}
+	return fmt.Sprint(id)
+}
+
+// isNullID 判断响应 ID 是否为空或 null，服务端无法确定请求 ID 时（如解析错误）返回 null
+func isNullID(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// parseMessages 解析单个或批量响应
+func parseMessages(data []byte) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, err
+		}
+		return batch, nil
+	}
+	return []json.RawMessage{data}, nil
+}
@@ -0,0 +1,207 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/so68/utils/socket/client"
+)
+
+// ErrClosed 客户端已关闭
+var ErrClosed = errors.New("jsonrpc: client closed")
+
+// WSClient 基于 socket/client.Websocket 的 JSON-RPC 客户端
+type WSClient struct {
+	ws                  *client.Websocket         // 底层 WebSocket
+	logger              *slog.Logger              // 日志记录器
+	timeout             time.Duration             // 默认调用超时
+	notificationHandler NotificationHandler       // 通知处理器
+	pending             map[string]chan *Response // 等待响应的调用，key为请求ID
+	mu                  sync.Mutex                // 保护 pending 和 closed
+	nextID              int64                     // 请求ID计数器
+	closed              bool                      // 是否已关闭
+}
+
+// NewWSClient 创建 JSON-RPC WebSocket 客户端
+func NewWSClient(dialURL string) *WSClient {
+	c := &WSClient{
+		logger:  slog.Default(),
+		timeout: 30 * time.Second,
+		pending: make(map[string]chan *Response),
+	}
+	c.ws = client.NewWebsocket(dialURL, c.handleMessage)
+	return c
+}
+
+// Websocket 返回底层 WebSocket，用于设置配置、日志和回调
+func (c *WSClient) Websocket() *client.Websocket {
+	return c.ws
+}
+
+// SetTimeout 设置默认调用超时
+func (c *WSClient) SetTimeout(timeout time.Duration) *WSClient {
+	c.timeout = timeout
+	return c
+}
+
+// SetLogger 设置日志记录器
+func (c *WSClient) SetLogger(logger *slog.Logger) *WSClient {
+	c.logger = logger
+	c.ws.SetLogger(logger)
+	return c
+}
+
+// SetNotificationHandler 设置服务端通知处理器
+func (c *WSClient) SetNotificationHandler(handler NotificationHandler) *WSClient {
+	c.notificationHandler = handler
+	return c
+}
+
+// Start 建立连接
+func (c *WSClient) Start() error {
+	return c.ws.Start()
+}
+
+// Close 关闭连接，所有等待中的调用返回 ErrClosed
+func (c *WSClient) Close() {
+	c.mu.Lock()
+	c.closed = true
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	c.ws.Close()
+}
+
+// Call 使用默认超时调用方法并将结果解析到 result
+func (c *WSClient) Call(method string, params interface{}, result interface{}) error {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.CallContext(ctx, method, params, result)
+}
+
+// CallContext 调用方法，ctx 取消或超时后放弃等待
+func (c *WSClient) CallContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	id := atomic.AddInt64(&c.nextID, 1)
+	key := fmt.Sprint(id)
+
+	body, err := json.Marshal(Request{JSONRPC: Version, ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	ch := make(chan *Response, 1)
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.pending[key] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+	}()
+
+	if err := c.ws.WriteMessage(body); err != nil {
+		return fmt.Errorf("write request: %w", err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return ErrClosed
+		}
+		return decodeResult(resp, result)
+	case <-ctx.Done():
+		return fmt.Errorf("jsonrpc call %s: %w", method, ctx.Err())
+	}
+}
+
+// Notify 发送通知，不等待结果
+func (c *WSClient) Notify(method string, params interface{}) error {
+	body, err := json.Marshal(Request{JSONRPC: Version, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	return c.ws.WriteMessage(body)
+}
+
+// handleMessage 按 ID 分发响应，无 ID 且带 method 的消息作为通知处理
+// id 为 null 的错误响应无法对应到具体调用，所有等待中的调用都以该错误返回
+func (c *WSClient) handleMessage(message []byte) {
+	messages, err := parseMessages(message)
+	if err != nil {
+		c.logger.Error("jsonrpc invalid message", "error", err.Error())
+		return
+	}
+
+	for _, raw := range messages {
+		var envelope struct {
+			Response
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			c.logger.Error("jsonrpc invalid message", "error", err.Error())
+			continue
+		}
+
+		if envelope.Method != "" {
+			if c.notificationHandler != nil {
+				c.notificationHandler(&Notification{
+					JSONRPC: envelope.JSONRPC,
+					Method:  envelope.Method,
+					Params:  envelope.Params,
+				})
+			}
+			continue
+		}
+
+		key := idKey(envelope.ID)
+		c.mu.Lock()
+		ch, ok := c.pending[key]
+		if ok {
+			delete(c.pending, key)
+		}
+		c.mu.Unlock()
+
+		resp := envelope.Response
+		if !ok {
+			if resp.Error != nil && isNullID(resp.ID) {
+				c.failPending(&resp)
+				continue
+			}
+			c.logger.Warn("jsonrpc response for unknown request", "id", key)
+			continue
+		}
+		ch <- &resp
+	}
+}
+
+// failPending 将错误响应发送给所有等待中的调用
+func (c *WSClient) failPending(resp *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		c.logger.Warn("jsonrpc error response without request", "error", resp.Error.Error())
+		return
+	}
+	for key, ch := range c.pending {
+		ch <- resp
+		delete(c.pending, key)
+	}
+}
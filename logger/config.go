@@ -21,11 +21,13 @@ const (
 	LevelError LogLevel = "error" // 错误级别
 
 	// ANSI 颜色代码
-	colorReset  = "\033[0m"  // 重置颜色
-	colorRed    = "\033[31m" // 红色
-	colorGreen  = "\033[32m" // 绿色
-	colorYellow = "\033[33m" // 黄色
-	colorBlue   = "\033[34m" // 蓝色
+	ColorReset   = "\033[0m"  // 重置颜色
+	ColorRed     = "\033[31m" // 红色
+	ColorGreen   = "\033[32m" // 绿色
+	ColorYellow  = "\033[33m" // 黄色
+	ColorBlue    = "\033[34m" // 蓝色
+	ColorMagenta = "\033[35m" // 品红色
+	ColorCyan    = "\033[36m" // 青色
 
 	// 预定义的输出类型
 	OutputStdout LogOutput = "stdout" // 标准输出
@@ -162,13 +164,13 @@ func (c *Config) SlogLevel() slog.Level {
 func (c *Config) GetLevelColor() string {
 	switch c.Level {
 	case LevelDebug:
-		return colorBlue
+		return ColorBlue
 	case LevelInfo:
-		return colorGreen
+		return ColorGreen
 	case LevelWarn:
-		return colorYellow
+		return ColorYellow
 	case LevelError:
-		return colorRed
+		return ColorRed
 	}
-	return colorReset
+	return ColorReset
 }
@@ -172,7 +172,7 @@ func (h *textHandler) Handle(ctx context.Context, r slog.Record) error {
 	builder.WriteString("[")
 	builder.WriteString(r.Level.String())
 	builder.WriteString("]")
-	builder.WriteString(colorReset)
+	builder.WriteString(ColorReset)
 	builder.WriteString(" ")
 
 	// 消息
@@ -257,15 +257,15 @@ func (h *textHandler) writeSourceFromAttrs(builder *strings.Builder, sourceAttrs
 func (h *textHandler) getLevelColor(level slog.Level) string {
 	switch level {
 	case slog.LevelDebug:
-		return colorBlue
+		return ColorBlue
 	case slog.LevelInfo:
-		return colorGreen
+		return ColorGreen
 	case slog.LevelWarn:
-		return colorYellow
+		return ColorYellow
 	case slog.LevelError:
-		return colorRed
+		return ColorRed
 	default:
-		return colorReset
+		return ColorReset
 	}
 }
 
@@ -1,13 +1,19 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/mattn/go-colorable"
 )
 
-// MarshalFormat 序列化格式
+// MarshalFormat 序列化格式，内置格式之外的格式可通过 RegisterFormat 注册
 type MarshalFormat int
 
 const (
@@ -19,6 +25,19 @@ const (
 	XMLFormat
 	// StringFormat 字符串格式
 	StringFormat
+	// TOMLFormat TOML 格式
+	TOMLFormat
+	// CSVFormat CSV 格式，适用于结构体切片或 map 切片
+	CSVFormat
+	// INIFormat INI 格式，嵌套结构体对应节
+	INIFormat
+	// MsgPackFormat MessagePack 二进制格式
+	MsgPackFormat
+	// CBORFormat CBOR 二进制格式 (RFC 8949)
+	CBORFormat
+
+	// AutoFormat 解码时根据内容自动识别格式，编码时按 JSON 处理，取值不与注册的格式冲突
+	AutoFormat MarshalFormat = math.MinInt32
 )
 
 // MarshalOptions 序列化选项
@@ -30,6 +49,41 @@ type MarshalOptions struct {
 	Truncate   bool
 	EscapeHTML bool
 	SortKeys   bool
+	Canonical  bool // 规范 JSON：键排序、无空白、不转义 HTML，适用于哈希和签名
+	YAMLFlow   bool // YAML 使用流式风格（{a: 1, b: [x, y]}），默认块风格
+
+	TruncateSuffix  string       // 截断后追加的标记，%d 替换为被截断的字节数，为空时不追加
+	TruncateMode    TruncateMode // 截断方式，TruncateStructure 仅对 JSON 生效
+	MaxStringLength int          // 结构化截断时字符串的最大字符数，0 使用默认值
+	MaxArrayItems   int          // 结构化截断时数组的最大元素数，0 使用默认值
+
+	XMLRoot       string // map/切片编码为 XML 时的根元素名，默认 root
+	XMLAttrPrefix string // map 键以此前缀开头时编码为 XML 属性，默认 @
+	XMLTextKey    string // map 中表示元素文本内容的键，默认 #text
+
+	CSVDelimiter rune // CSV 分隔符，默认逗号
+
+	DisallowUnknownFields bool // 解码时拒绝目标结构体中不存在的字段（JSON、YAML、XML）
+	UseNumber             bool // JSON 解码到 interface{} 时数字保留为 json.Number，避免大整数丢失精度
+	DisallowTrailingData  bool // 拒绝值之后的多余数据，如 YAML 的后续文档、XML 的多个根元素（JSON 总是拒绝）
+	MaxDepth              int  // 解码时的最大嵌套深度，0 不限制
+	MaxSize               int  // 解码时的最大输入字节数，0 不限制
+
+	Redact     bool     // 编码时按 redact/mask 标签脱敏，不修改输入
+	RedactKeys []string // 需要脱敏的 map 键名模式（path.Match 通配，大小写不敏感），非空时启用脱敏
+	RedactMask string   // 脱敏后的替换文本，默认 ******
+
+	KeyNaming KeyNaming // 编码时转换字段名和 map 键名的风格，解码时按风格无关方式匹配字段，map 键保持原样（JSON、YAML、XML）
+
+	Schema *JSONSchema // 解码前按 JSON Schema 校验输入，失败时返回 *SchemaError 且不修改目标
+
+	Color       ColorMode   // 编码 JSON、YAML 时按 ANSI 颜色高亮，ColorAuto 仅在 MarshalToWriter 写入终端时启用
+	ColorScheme ColorScheme // 高亮使用的颜色，零值使用 DefaultColorScheme
+
+	TimeEncoding  TimeEncoding   // time.Time 的编码方式（JSON、YAML），解码时按同样方式解析
+	TimeLayout    string         // TimeString 使用的布局，默认 time.RFC3339
+	TimeLocation  *time.Location // 编码前将时间转换到该时区，解析 Unix 时间和不含时区的布局时使用该时区，nil 时解析使用 UTC
+	BytesEncoding BytesEncoding  // []byte 的编码方式（JSON、YAML），解码时按同样方式解析
 }
 
 // DefaultMarshalOptions 默认选项
@@ -41,6 +95,17 @@ var DefaultMarshalOptions = MarshalOptions{
 	Truncate:   false,
 	EscapeHTML: true,
 	SortKeys:   false,
+
+	TruncateSuffix: "…(truncated %d bytes)",
+	TruncateMode:   TruncateBytes,
+
+	XMLRoot:       "root",
+	XMLAttrPrefix: "@",
+	XMLTextKey:    "#text",
+
+	CSVDelimiter: ',',
+
+	RedactMask: "******",
 }
 
 // Marshaler 序列化器接口
@@ -102,6 +167,18 @@ func (m *MarshalExt) SetTruncate(truncate bool) *MarshalExt {
 	return m
 }
 
+// SetTruncateSuffix 设置截断标记，%d 替换为被截断的字节数（链式调用）
+func (m *MarshalExt) SetTruncateSuffix(suffix string) *MarshalExt {
+	m.options.TruncateSuffix = suffix
+	return m
+}
+
+// SetTruncateMode 设置截断方式（链式调用）
+func (m *MarshalExt) SetTruncateMode(mode TruncateMode) *MarshalExt {
+	m.options.TruncateMode = mode
+	return m
+}
+
 // SetEscapeHTML 设置是否转义HTML（链式调用）
 func (m *MarshalExt) SetEscapeHTML(escape bool) *MarshalExt {
 	m.options.EscapeHTML = escape
@@ -114,38 +191,183 @@ func (m *MarshalExt) SetSortKeys(sort bool) *MarshalExt {
 	return m
 }
 
+// SetCanonical 设置是否输出规范 JSON（链式调用）
+func (m *MarshalExt) SetCanonical(canonical bool) *MarshalExt {
+	m.options.Canonical = canonical
+	return m
+}
+
+// SetYAMLFlow 设置 YAML 是否使用流式风格（链式调用）
+func (m *MarshalExt) SetYAMLFlow(flow bool) *MarshalExt {
+	m.options.YAMLFlow = flow
+	return m
+}
+
+// SetXMLRoot 设置 map/切片编码为 XML 时的根元素名（链式调用）
+func (m *MarshalExt) SetXMLRoot(root string) *MarshalExt {
+	m.options.XMLRoot = root
+	return m
+}
+
+// SetXMLAttrPrefix 设置 XML 属性键前缀（链式调用）
+func (m *MarshalExt) SetXMLAttrPrefix(prefix string) *MarshalExt {
+	m.options.XMLAttrPrefix = prefix
+	return m
+}
+
+// SetXMLTextKey 设置 XML 文本内容键（链式调用）
+func (m *MarshalExt) SetXMLTextKey(key string) *MarshalExt {
+	m.options.XMLTextKey = key
+	return m
+}
+
+// SetCSVDelimiter 设置 CSV 分隔符（链式调用）
+func (m *MarshalExt) SetCSVDelimiter(delimiter rune) *MarshalExt {
+	m.options.CSVDelimiter = delimiter
+	return m
+}
+
+// SetDisallowUnknownFields 设置解码时是否拒绝未知字段（链式调用）
+func (m *MarshalExt) SetDisallowUnknownFields(disallow bool) *MarshalExt {
+	m.options.DisallowUnknownFields = disallow
+	return m
+}
+
+// SetUseNumber 设置 JSON 解码时是否使用 json.Number（链式调用）
+func (m *MarshalExt) SetUseNumber(useNumber bool) *MarshalExt {
+	m.options.UseNumber = useNumber
+	return m
+}
+
+// SetDisallowTrailingData 设置解码时是否拒绝多余数据（链式调用）
+func (m *MarshalExt) SetDisallowTrailingData(disallow bool) *MarshalExt {
+	m.options.DisallowTrailingData = disallow
+	return m
+}
+
+// SetMaxDepth 设置解码时的最大嵌套深度（链式调用）
+func (m *MarshalExt) SetMaxDepth(maxDepth int) *MarshalExt {
+	m.options.MaxDepth = maxDepth
+	return m
+}
+
+// SetMaxSize 设置解码时的最大输入字节数（链式调用）
+func (m *MarshalExt) SetMaxSize(maxSize int) *MarshalExt {
+	m.options.MaxSize = maxSize
+	return m
+}
+
+// SetStrict 同时设置 DisallowUnknownFields、UseNumber 和 DisallowTrailingData（链式调用）
+func (m *MarshalExt) SetStrict(strict bool) *MarshalExt {
+	m.options.DisallowUnknownFields = strict
+	m.options.UseNumber = strict
+	m.options.DisallowTrailingData = strict
+	return m
+}
+
+// SetRedact 设置编码时是否按标签脱敏（链式调用）
+func (m *MarshalExt) SetRedact(redact bool) *MarshalExt {
+	m.options.Redact = redact
+	return m
+}
+
+// SetRedactKeys 设置需要脱敏的 map 键名模式，如 "*password*"（链式调用）
+func (m *MarshalExt) SetRedactKeys(patterns ...string) *MarshalExt {
+	m.options.RedactKeys = patterns
+	return m
+}
+
+// SetRedactMask 设置脱敏后的替换文本（链式调用）
+func (m *MarshalExt) SetRedactMask(mask string) *MarshalExt {
+	m.options.RedactMask = mask
+	return m
+}
+
+// SetKeyNaming 设置键名风格（链式调用）
+// 编码时 map 的键同样会被转换，解码时只匹配结构体字段，map 的键不会还原
+func (m *MarshalExt) SetKeyNaming(naming KeyNaming) *MarshalExt {
+	m.options.KeyNaming = naming
+	return m
+}
+
+// SetSchema 设置解码前用于校验输入的 JSON Schema，nil 表示不校验（链式调用）
+func (m *MarshalExt) SetSchema(schema *JSONSchema) *MarshalExt {
+	m.options.Schema = schema
+	return m
+}
+
+// SetColor 设置 ANSI 颜色高亮模式（链式调用）
+func (m *MarshalExt) SetColor(mode ColorMode) *MarshalExt {
+	m.options.Color = mode
+	return m
+}
+
+// SetColorScheme 设置高亮使用的颜色（链式调用）
+func (m *MarshalExt) SetColorScheme(scheme ColorScheme) *MarshalExt {
+	m.options.ColorScheme = scheme
+	return m
+}
+
+// SetTimeEncoding 设置 time.Time 的编码方式（链式调用）
+func (m *MarshalExt) SetTimeEncoding(encoding TimeEncoding) *MarshalExt {
+	m.options.TimeEncoding = encoding
+	return m
+}
+
+// SetTimeLayout 设置时间布局并使用 TimeString 编码（链式调用）
+func (m *MarshalExt) SetTimeLayout(layout string) *MarshalExt {
+	m.options.TimeEncoding = TimeString
+	m.options.TimeLayout = layout
+	return m
+}
+
+// SetTimeLocation 设置编码和解析时间使用的时区（链式调用）
+func (m *MarshalExt) SetTimeLocation(loc *time.Location) *MarshalExt {
+	m.options.TimeLocation = loc
+	return m
+}
+
+// SetBytesEncoding 设置 []byte 的编码方式（链式调用）
+func (m *MarshalExt) SetBytesEncoding(encoding BytesEncoding) *MarshalExt {
+	m.options.BytesEncoding = encoding
+	return m
+}
+
 // Clone 克隆序列化器
 func (m *MarshalExt) Clone() *MarshalExt {
 	return &MarshalExt{options: m.options}
 }
 
-// Marshal 序列化对象
+// Marshal 序列化对象，结果不对应具体的输出目标，因此只在 ColorAlways 模式下高亮
 func (m *MarshalExt) Marshal(v interface{}) ([]byte, error) {
-	var data []byte
-	var err error
-
-	switch m.options.Format {
-	case JSONFormat:
-		data, err = m.marshalJSON(v)
-	case YAMLFormat:
-		data, err = m.marshalYAML(v)
-	case XMLFormat:
-		data, err = m.marshalXML(v)
-	case StringFormat:
-		data, err = m.marshalString(v)
-	default:
-		data, err = m.marshalJSON(v)
+	data, err := m.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if m.colorEnabled(nil) {
+		return m.colorize(data), nil
+	}
+	return data, nil
+}
+
+// marshal 序列化对象，不做颜色高亮
+func (m *MarshalExt) marshal(v interface{}) ([]byte, error) {
+	if m.redactEnabled() {
+		redacted, err := m.redact(v)
+		if err != nil {
+			return nil, err
+		}
+		v = redacted
 	}
 
+	data, err := lookupCodec(m.options.Format).Marshal(v, m.options)
 	if err != nil {
 		return nil, err
 	}
 
 	// 处理长度限制
 	if m.options.MaxLength > 0 && len(data) > m.options.MaxLength {
-		if m.options.Truncate {
-			data = data[:m.options.MaxLength]
-		}
+		return m.truncate(v, data)
 	}
 
 	return data, nil
@@ -169,30 +391,38 @@ func (m *MarshalExt) MustMarshalToString(v interface{}) string {
 	return str
 }
 
-// MarshalToWriter 序列化到 Writer
+// MarshalToWriter 序列化到 Writer，ColorAuto 模式下按 Writer 是否为终端决定是否高亮
 func (m *MarshalExt) MarshalToWriter(w io.Writer, v interface{}) error {
-	data, err := m.Marshal(v)
+	data, err := m.marshal(v)
 	if err != nil {
 		return err
 	}
+	if m.colorEnabled(w) {
+		data = m.colorize(data)
+		// 使用 colorable 支持 Windows 控制台
+		if f, ok := w.(*os.File); ok {
+			w = colorable.NewColorable(f)
+		}
+	}
 	_, err = w.Write(data)
 	return err
 }
 
-// Unmarshal 反序列化
+// Unmarshal 反序列化，格式为 AutoFormat 时按内容识别，无法识别时按 JSON 解析
 func (m *MarshalExt) Unmarshal(data []byte, v interface{}) error {
-	switch m.options.Format {
-	case JSONFormat:
-		return m.unmarshalJSON(data, v)
-	case YAMLFormat:
-		return m.unmarshalYAML(data, v)
-	case XMLFormat:
-		return m.unmarshalXML(data, v)
-	case StringFormat:
-		return m.unmarshalString(data, v)
-	default:
-		return m.unmarshalJSON(data, v)
+	if m.options.MaxSize > 0 && len(data) > m.options.MaxSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrMaxSizeExceeded, len(data), m.options.MaxSize)
+	}
+	format := m.options.Format
+	if format == AutoFormat {
+		format = m.detectOrDefault(data)
 	}
+	if m.options.Schema != nil {
+		if err := m.validateSchema(data, format); err != nil {
+			return err
+		}
+	}
+	return lookupCodec(format).Unmarshal(data, v, m.options)
 }
 
 // UnmarshalFromString 从字符串反序列化
@@ -201,7 +431,11 @@ func (m *MarshalExt) UnmarshalFromString(str string, v interface{}) error {
 }
 
 // UnmarshalFromReader 从 Reader 反序列化
+// 设置了 MaxSize 时最多读取 MaxSize+1 字节，超出即返回错误
 func (m *MarshalExt) UnmarshalFromReader(r io.Reader, v interface{}) error {
+	if m.options.MaxSize > 0 {
+		r = io.LimitReader(r, int64(m.options.MaxSize)+1)
+	}
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return err
@@ -211,50 +445,106 @@ func (m *MarshalExt) UnmarshalFromReader(r io.Reader, v interface{}) error {
 
 // JSON 序列化实现
 func (m *MarshalExt) marshalJSON(v interface{}) ([]byte, error) {
-	if m.options.Pretty {
-		return json.MarshalIndent(v, "", m.options.Indent)
+	canonical := m.options.Canonical
+	sorted := m.options.SortKeys || canonical
+	escapeHTML := m.options.EscapeHTML && !canonical
+
+	// 先按原生规则编码，再按选项改写时间和 []byte，结果作为 json.RawMessage 继续处理
+	if m.encodingEnabled() {
+		encoded, err := m.encodeJSONWithEncodings(v, escapeHTML)
+		if err != nil {
+			return nil, err
+		}
+		v = json.RawMessage(encoded)
 	}
-	return json.Marshal(v)
-}
 
-func (m *MarshalExt) unmarshalJSON(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
-}
+	// 结构体字段默认按定义顺序输出，排序时先转换为通用结构（map 键由 encoding/json 排序）
+	if sorted {
+		normalized, err := normalizeJSON(v)
+		if err != nil {
+			return nil, err
+		}
+		v = normalized
+		if m.options.KeyNaming != KeepKeyNames {
+			v = m.renameGenericKeys(normalized)
+		}
+	}
 
-// YAML 序列化实现（需要导入 yaml 包）
-func (m *MarshalExt) marshalYAML(v interface{}) ([]byte, error) {
-	// 这里需要导入 gopkg.in/yaml.v3
-	// import "gopkg.in/yaml.v3"
-	// return yaml.Marshal(v)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(escapeHTML)
+	if m.options.Pretty && !canonical {
+		encoder.SetIndent("", m.options.Indent)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
 
-	// 暂时使用 JSON 格式，实际使用时替换为：
-	// return yaml.Marshal(v)
-	return m.marshalJSON(v)
+	// 未排序时直接改写输出中的键名，保持字段顺序
+	if m.options.KeyNaming != KeepKeyNames && !sorted {
+		return m.renameJSONKeys(data, escapeHTML)
+	}
+	return data, nil
 }
 
-func (m *MarshalExt) unmarshalYAML(data []byte, v interface{}) error {
-	// 这里需要导入 gopkg.in/yaml.v3
-	// return yaml.Unmarshal(data, v)
-
-	return m.unmarshalJSON(data, v)
+// normalizeJSON 将任意值转换为 map[string]interface{}/[]interface{}/json.Number 组成的通用结构
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
 }
 
-// XML 序列化实现
-func (m *MarshalExt) marshalXML(v interface{}) ([]byte, error) {
-	// 对于 map 类型，先转换为 JSON 再处理
-	if _, ok := v.(map[string]interface{}); ok {
-		// 对于 map 类型，使用 JSON 格式
-		return m.marshalJSON(v)
+func (m *MarshalExt) unmarshalJSON(data []byte, v interface{}) error {
+	if m.options.KeyNaming != KeepKeyNames {
+		matched, err := matchJSONKeys(data, v)
+		if err != nil {
+			return err
+		}
+		data = matched
 	}
 
-	if m.options.Pretty {
-		return xml.MarshalIndent(v, "", m.options.Indent)
+	if t := reflect.TypeOf(v); t != nil && m.decodingEnabled() {
+		rewritten, err := m.rewriteJSONInput(data, t)
+		if err != nil {
+			return fmt.Errorf("json unmarshal error: %w", err)
+		}
+		data = rewritten
 	}
-	return xml.Marshal(v)
-}
 
-func (m *MarshalExt) unmarshalXML(data []byte, v interface{}) error {
-	return xml.Unmarshal(data, v)
+	if !m.options.DisallowUnknownFields && !m.options.UseNumber && m.options.MaxDepth <= 0 {
+		return json.Unmarshal(data, v)
+	}
+
+	if m.options.MaxDepth > 0 {
+		if err := checkJSONDepth(data, m.options.MaxDepth); err != nil {
+			return fmt.Errorf("json unmarshal error: %w", err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if m.options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if m.options.UseNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return wrapUnknownJSONField(err)
+	}
+	// 与 json.Unmarshal 一致，总是拒绝多余数据
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("json unmarshal error: %w", ErrTrailingData)
+	}
+	return nil
 }
 
 // 字符串序列化实现
@@ -291,6 +581,24 @@ func (m *MarshalExt) ToPrettyJSON(v interface{}) (string, error) {
 	return ext.MarshalToString(v)
 }
 
+// ToColorJSON 转换为美化的 JSON，标准输出为终端时按颜色高亮
+func (m *MarshalExt) ToColorJSON(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(JSONFormat).SetPretty(true).SetColor(ColorAuto)
+	return ext.marshalColorString(v, os.Stdout)
+}
+
+// ToColorYAML 转换为 YAML，标准输出为终端时按颜色高亮
+func (m *MarshalExt) ToColorYAML(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(YAMLFormat).SetColor(ColorAuto)
+	return ext.marshalColorString(v, os.Stdout)
+}
+
+// ToCanonicalJSON 转换为规范 JSON，相同数据总是得到相同字节
+func (m *MarshalExt) ToCanonicalJSON(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(JSONFormat).SetCanonical(true)
+	return ext.MarshalToString(v)
+}
+
 func (m *MarshalExt) ToYAML(v interface{}) (string, error) {
 	ext := m.Clone().SetFormat(YAMLFormat)
 	return ext.MarshalToString(v)
@@ -311,6 +619,21 @@ func (m *MarshalExt) ToString(v interface{}) (string, error) {
 	return ext.MarshalToString(v)
 }
 
+func (m *MarshalExt) ToTOML(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(TOMLFormat)
+	return ext.MarshalToString(v)
+}
+
+func (m *MarshalExt) ToCSV(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(CSVFormat)
+	return ext.MarshalToString(v)
+}
+
+func (m *MarshalExt) ToINI(v interface{}) (string, error) {
+	ext := m.Clone().SetFormat(INIFormat)
+	return ext.MarshalToString(v)
+}
+
 // Must 版本便捷方法（直接返回 string，出错时 panic）
 func (m *MarshalExt) MustToJSON(v interface{}) string {
 	ext := m.Clone().SetFormat(JSONFormat)
@@ -342,6 +665,21 @@ func (m *MarshalExt) MustToString(v interface{}) string {
 	return ext.MustMarshalToString(v)
 }
 
+func (m *MarshalExt) MustToTOML(v interface{}) string {
+	ext := m.Clone().SetFormat(TOMLFormat)
+	return ext.MustMarshalToString(v)
+}
+
+func (m *MarshalExt) MustToCSV(v interface{}) string {
+	ext := m.Clone().SetFormat(CSVFormat)
+	return ext.MustMarshalToString(v)
+}
+
+func (m *MarshalExt) MustToINI(v interface{}) string {
+	ext := m.Clone().SetFormat(INIFormat)
+	return ext.MustMarshalToString(v)
+}
+
 // 全局默认序列化器
 var DefaultMarshal = DefaultMarshalExt()
 
@@ -384,6 +722,20 @@ func ToPrettyJSON(v interface{}) (string, error) {
 	return DefaultMarshal.ToPrettyJSON(v)
 }
 
+// ToColorJSON 使用默认序列化器转换为美化的 JSON，标准输出为终端时按颜色高亮
+func ToColorJSON(v interface{}) (string, error) {
+	return DefaultMarshal.ToColorJSON(v)
+}
+
+// ToColorYAML 使用默认序列化器转换为 YAML，标准输出为终端时按颜色高亮
+func ToColorYAML(v interface{}) (string, error) {
+	return DefaultMarshal.ToColorYAML(v)
+}
+
+func ToCanonicalJSON(v interface{}) (string, error) {
+	return DefaultMarshal.ToCanonicalJSON(v)
+}
+
 func ToYAML(v interface{}) (string, error) {
 	return DefaultMarshal.ToYAML(v)
 }
@@ -400,6 +752,18 @@ func ToString(v interface{}) (string, error) {
 	return DefaultMarshal.ToString(v)
 }
 
+func ToTOML(v interface{}) (string, error) {
+	return DefaultMarshal.ToTOML(v)
+}
+
+func ToCSV(v interface{}) (string, error) {
+	return DefaultMarshal.ToCSV(v)
+}
+
+func ToINI(v interface{}) (string, error) {
+	return DefaultMarshal.ToINI(v)
+}
+
 // Must 版本全局格式特定函数（直接返回 string，出错时 panic）
 func MustToJSON(v interface{}) string {
 	return DefaultMarshal.MustToJSON(v)
@@ -425,6 +789,18 @@ func MustToString(v interface{}) string {
 	return DefaultMarshal.MustToString(v)
 }
 
+func MustToTOML(v interface{}) string {
+	return DefaultMarshal.MustToTOML(v)
+}
+
+func MustToCSV(v interface{}) string {
+	return DefaultMarshal.MustToCSV(v)
+}
+
+func MustToINI(v interface{}) string {
+	return DefaultMarshal.MustToINI(v)
+}
+
 // MarshalBuilder 序列化构建器，支持链式调用
 type MarshalBuilder struct {
 	value   interface{}
@@ -469,6 +845,18 @@ func (b *MarshalBuilder) SetTruncate(truncate bool) *MarshalBuilder {
 	return b
 }
 
+// SetColor 设置颜色高亮
+func (b *MarshalBuilder) SetColor(mode ColorMode) *MarshalBuilder {
+	b.marshal.SetColor(mode)
+	return b
+}
+
+// SetRedact 设置脱敏
+func (b *MarshalBuilder) SetRedact(redact bool) *MarshalBuilder {
+	b.marshal.SetRedact(redact)
+	return b
+}
+
 // Build 构建结果
 func (b *MarshalBuilder) Build() ([]byte, error) {
 	return b.marshal.Marshal(b.value)
@@ -514,6 +902,18 @@ func (b *MarshalBuilder) ToString() (string, error) {
 	return b.SetFormat(StringFormat).BuildString()
 }
 
+func (b *MarshalBuilder) ToTOML() (string, error) {
+	return b.SetFormat(TOMLFormat).BuildString()
+}
+
+func (b *MarshalBuilder) ToCSV() (string, error) {
+	return b.SetFormat(CSVFormat).BuildString()
+}
+
+func (b *MarshalBuilder) ToINI() (string, error) {
+	return b.SetFormat(INIFormat).BuildString()
+}
+
 // Must 版本格式特定的构建方法（直接返回 string，出错时 panic）
 func (b *MarshalBuilder) MustToJSON() string {
 	return b.SetFormat(JSONFormat).MustBuildString()
@@ -539,6 +939,18 @@ func (b *MarshalBuilder) MustToString() string {
 	return b.SetFormat(StringFormat).MustBuildString()
 }
 
+func (b *MarshalBuilder) MustToTOML() string {
+	return b.SetFormat(TOMLFormat).MustBuildString()
+}
+
+func (b *MarshalBuilder) MustToCSV() string {
+	return b.SetFormat(CSVFormat).MustBuildString()
+}
+
+func (b *MarshalBuilder) MustToINI() string {
+	return b.SetFormat(INIFormat).MustBuildString()
+}
+
 // Clone 克隆构建器
 func (b *MarshalBuilder) Clone() *MarshalBuilder {
 	return &MarshalBuilder{
@@ -547,12 +959,16 @@ func (b *MarshalBuilder) Clone() *MarshalBuilder {
 	}
 }
 
-// 类型转换工具
+// TypeConverter 类型转换工具
+//
+// Deprecated: 新代码编码直接使用 ToJSON、ToYAML 等函数，解码和转换使用 UnmarshalAs、Decode、ConvertTo 等泛型函数
 type TypeConverter struct {
 	value interface{}
 }
 
 // NewTypeConverter 创建类型转换器
+//
+// Deprecated: 见 TypeConverter
 func NewTypeConverter(v interface{}) *TypeConverter {
 	return &TypeConverter{value: v}
 }
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// 二进制格式（MessagePack、CBOR）共用的编码遍历和读取工具
+
+// binaryMaxDepth 二进制格式编解码的最大嵌套深度
+const binaryMaxDepth = 1000
+
+// binaryWriter 二进制格式的基本类型写入器
+type binaryWriter interface {
+	writeNil()
+	writeBool(b bool)
+	writeInt(i int64)
+	writeUint(u uint64)
+	writeFloat32(f float32)
+	writeFloat64(f float64)
+	writeString(s string)
+	writeBytes(b []byte)
+	writeTime(t time.Time)
+	writeArrayHeader(n int)
+	writeMapHeader(n int)
+}
+
+// encodeBinary 按反射遍历值并写入 w，结构体键名依次取 tagName 标签、json 标签、字段名
+// map 按键排序以保证输出稳定，实现 encoding.TextMarshaler 的类型编码为字符串
+func encodeBinary(w binaryWriter, v reflect.Value, tagName string, depth int) error {
+	if depth > binaryMaxDepth {
+		return ErrMaxDepthExceeded
+	}
+
+	v = indirectValue(v)
+	if !v.IsValid() {
+		w.writeNil()
+		return nil
+	}
+
+	t := v.Type()
+	if t == timeType {
+		w.writeTime(v.Interface().(time.Time))
+		return nil
+	}
+	if t.Implements(textMarshalerType) {
+		text, err := formatScalar(v)
+		if err != nil {
+			return err
+		}
+		w.writeString(text)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		w.writeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.writeUint(v.Uint())
+	case reflect.Float32:
+		w.writeFloat32(float32(v.Float()))
+	case reflect.Float64:
+		w.writeFloat64(v.Float())
+	case reflect.String:
+		w.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			w.writeNil()
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			w.writeBytes(v.Bytes())
+			return nil
+		}
+		return encodeBinaryArray(w, v, tagName, depth)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(data), v)
+			w.writeBytes(data)
+			return nil
+		}
+		return encodeBinaryArray(w, v, tagName, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			w.writeNil()
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		w.writeMapHeader(len(keys))
+		for _, key := range keys {
+			if err := encodeBinary(w, key, tagName, depth+1); err != nil {
+				return err
+			}
+			if err := encodeBinary(w, v.MapIndex(key), tagName, depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		type entry struct {
+			name  string
+			value reflect.Value
+		}
+		var entries []entry
+		for _, f := range structFields(t, tagName) {
+			field, ok := fieldByIndex(v, f.index, false)
+			if !ok || (f.omitEmpty && field.IsZero()) {
+				continue
+			}
+			entries = append(entries, entry{f.name, field})
+		}
+		w.writeMapHeader(len(entries))
+		for _, e := range entries {
+			w.writeString(e.name)
+			if err := encodeBinary(w, e.value, tagName, depth+1); err != nil {
+				return fmt.Errorf("field %s: %w", e.name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	return nil
+}
+
+// encodeBinaryArray 写入数组或切片
+func encodeBinaryArray(w binaryWriter, v reflect.Value, tagName string, depth int) error {
+	w.writeArrayHeader(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := encodeBinary(w, v.Index(i), tagName, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// binaryReader 二进制格式的字节读取器
+type binaryReader struct {
+	data     []byte
+	pos      int
+	maxDepth int // 最大嵌套深度
+}
+
+// newBinaryReader 创建读取器，MaxDepth 未设置或超过 binaryMaxDepth 时使用 binaryMaxDepth
+func (m *MarshalExt) newBinaryReader(data []byte) binaryReader {
+	maxDepth := binaryMaxDepth
+	if m.options.MaxDepth > 0 {
+		maxDepth = min(maxDepth, m.options.MaxDepth)
+	}
+	return binaryReader{data: data, maxDepth: maxDepth}
+}
+
+// remaining 返回剩余字节数
+func (r *binaryReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+// readByte 读取一个字节
+func (r *binaryReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+// next 读取 n 个字节，返回的切片引用原始数据
+func (r *binaryReader) next(n uint64) ([]byte, error) {
+	if n > uint64(r.remaining()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// checkLength 检查声明的元素个数是否超过剩余数据可能容纳的数量，避免恶意长度导致大量分配
+func (r *binaryReader) checkLength(n uint64, minSize int) error {
+	if n > uint64(r.remaining()/minSize) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// binaryMapKey 将解码出的 map 键转换为字符串
+func binaryMapKey(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// assignDecoded 将解码出的通用值写入目标指针
+func assignDecoded(target interface{}, value interface{}, tagName string) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	return assignGeneric(rv.Elem(), value, tagName)
+}
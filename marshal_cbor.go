@@ -0,0 +1,430 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+	"unicode/utf8"
+)
+
+// CBOR 主类型 (RFC 8949)
+const (
+	cborUint   byte = 0
+	cborNegInt byte = 1
+	cborBytes  byte = 2
+	cborText   byte = 3
+	cborArray  byte = 4
+	cborMap    byte = 5
+	cborTag    byte = 6
+	cborSimple byte = 7
+)
+
+// CBOR 时间标签
+const (
+	cborTagDateTime  = 0 // RFC 3339 字符串
+	cborTagEpochTime = 1 // Unix 时间戳
+)
+
+// cborBreak 不定长数据的结束标记
+const cborBreak byte = 0xff
+
+// errCBORBreak 在不定长数据之外遇到结束标记
+var errCBORBreak = errors.New("unexpected break")
+
+// CBOR 序列化实现，结构体键名取 cbor 标签
+// time.Time 无纳秒时编码为标签 1 的整数时间戳，否则编码为标签 0 的 RFC 3339 字符串
+func (m *MarshalExt) marshalCBOR(v interface{}) ([]byte, error) {
+	w := &cborWriter{}
+	if err := encodeBinary(w, reflect.ValueOf(v), "cbor", 0); err != nil {
+		return nil, fmt.Errorf("cbor marshal error: %w", err)
+	}
+	return w.Bytes(), nil
+}
+
+// unmarshalCBOR 解码 CBOR，支持不定长数据和半精度浮点，
+// 整数解码为 int64（超出范围时为 uint64），map 解码为 map[string]interface{}，未知标签返回其内容
+func (m *MarshalExt) unmarshalCBOR(data []byte, v interface{}) error {
+	d := &cborDecoder{m.newBinaryReader(data)}
+	value, err := d.decode(0)
+	if err != nil {
+		return fmt.Errorf("cbor unmarshal error: %w", err)
+	}
+	if d.remaining() > 0 {
+		return fmt.Errorf("cbor unmarshal error: %w: %d bytes", ErrTrailingData, d.remaining())
+	}
+	if err := assignDecoded(v, value, "cbor"); err != nil {
+		return fmt.Errorf("cbor unmarshal error: %w", err)
+	}
+	return nil
+}
+
+// cborWriter CBOR 写入器
+type cborWriter struct {
+	bytes.Buffer
+}
+
+// writeHead 写入主类型和参数，参数使用最短编码
+func (w *cborWriter) writeHead(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		w.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		w.WriteByte(major | 24)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(major | 25)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		w.WriteByte(major | 26)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		w.WriteByte(major | 27)
+		w.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func (w *cborWriter) writeNil() {
+	w.WriteByte(0xf6)
+}
+
+func (w *cborWriter) writeBool(b bool) {
+	if b {
+		w.WriteByte(0xf5)
+	} else {
+		w.WriteByte(0xf4)
+	}
+}
+
+func (w *cborWriter) writeInt(i int64) {
+	if i >= 0 {
+		w.writeHead(cborUint, uint64(i))
+		return
+	}
+	w.writeHead(cborNegInt, uint64(-(i + 1)))
+}
+
+func (w *cborWriter) writeUint(u uint64) {
+	w.writeHead(cborUint, u)
+}
+
+func (w *cborWriter) writeFloat32(f float32) {
+	w.WriteByte(0xfa)
+	w.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f)))
+}
+
+func (w *cborWriter) writeFloat64(f float64) {
+	w.WriteByte(0xfb)
+	w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (w *cborWriter) writeString(s string) {
+	w.writeHead(cborText, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *cborWriter) writeBytes(b []byte) {
+	w.writeHead(cborBytes, uint64(len(b)))
+	w.Write(b)
+}
+
+func (w *cborWriter) writeTime(t time.Time) {
+	if t.Nanosecond() == 0 {
+		w.writeHead(cborTag, cborTagEpochTime)
+		w.writeInt(t.Unix())
+		return
+	}
+	w.writeHead(cborTag, cborTagDateTime)
+	w.writeString(t.Format(time.RFC3339Nano))
+}
+
+func (w *cborWriter) writeArrayHeader(n int) {
+	w.writeHead(cborArray, uint64(n))
+}
+
+func (w *cborWriter) writeMapHeader(n int) {
+	w.writeHead(cborMap, uint64(n))
+}
+
+// cborDecoder CBOR 解码器
+type cborDecoder struct {
+	binaryReader
+}
+
+// readArgument 读取头部参数，indefinite 表示不定长
+func (d *cborDecoder) readArgument(info byte) (n uint64, indefinite bool, err error) {
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info <= 27:
+		b, err := d.next(1 << (info - 24))
+		if err != nil {
+			return 0, false, err
+		}
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, false, nil
+	case info == 31:
+		return 0, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid additional information %d at offset %d", info, d.pos-1)
+}
+
+// atBreak 判断下一个字节是否为结束标记，是则消费该字节
+func (d *cborDecoder) atBreak() (bool, error) {
+	if d.remaining() == 0 {
+		return false, errors.New("missing break for indefinite-length item")
+	}
+	if d.data[d.pos] == cborBreak {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > d.maxDepth {
+		return nil, ErrMaxDepthExceeded
+	}
+
+	head, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := head>>5, head&0x1f
+	if major == cborSimple {
+		return d.decodeSimple(info)
+	}
+
+	n, indefinite, err := d.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major == cborUint || major == cborNegInt || major == cborTag) {
+		return nil, fmt.Errorf("invalid indefinite length for major type %d", major)
+	}
+
+	switch major {
+	case cborUint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case cborNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer -1-%d overflows int64", n)
+		}
+		return -1 - int64(n), nil
+	case cborBytes, cborText:
+		b, err := d.decodeChunks(major, n, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborBytes {
+			return b, nil
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("invalid UTF-8 in text string")
+		}
+		return string(b), nil
+	case cborArray:
+		return d.decodeArray(n, indefinite, depth)
+	case cborMap:
+		return d.decodeMap(n, indefinite, depth)
+	default:
+		return d.decodeTag(n, depth)
+	}
+}
+
+// decodeChunks 读取定长或分块的字节串/文本串
+func (d *cborDecoder) decodeChunks(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	}
+
+	result := []byte{}
+	for {
+		done, err := d.atBreak()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return result, nil
+		}
+		head, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if head>>5 != major || head&0x1f == 31 {
+			return nil, fmt.Errorf("invalid chunk 0x%02x in indefinite-length string", head)
+		}
+		size, _, err := d.readArgument(head & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := d.next(size)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunk...)
+	}
+}
+
+func (d *cborDecoder) decodeArray(n uint64, indefinite bool, depth int) (interface{}, error) {
+	if indefinite {
+		result := []interface{}{}
+		for {
+			done, err := d.atBreak()
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return result, nil
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+	}
+
+	if err := d.checkLength(n, 1); err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, n)
+	for i := range result {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func (d *cborDecoder) decodeMap(n uint64, indefinite bool, depth int) (interface{}, error) {
+	if !indefinite {
+		if err := d.checkLength(n, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string]interface{})
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite {
+			done, err := d.atBreak()
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				break
+			}
+		}
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[binaryMapKey(key)] = value
+	}
+	return result, nil
+}
+
+// decodeTag 解码标签，时间标签解码为 time.Time，其余标签返回内容本身
+func (d *cborDecoder) decodeTag(tag uint64, depth int) (interface{}, error) {
+	content, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case cborTagDateTime:
+		s, ok := content.(string)
+		if !ok {
+			return nil, fmt.Errorf("tag 0 expects text string, got %T", content)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case cborTagEpochTime:
+		switch value := content.(type) {
+		case int64:
+			return time.Unix(value, 0).UTC(), nil
+		case uint64:
+			return nil, fmt.Errorf("epoch time %d overflows int64", value)
+		case float64:
+			sec, frac := math.Modf(value)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		}
+		return nil, fmt.Errorf("tag 1 expects number, got %T", content)
+	}
+	return content, nil
+}
+
+// decodeSimple 解码简单值和浮点数
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		return float16ToFloat64(binary.BigEndian.Uint16(b)), nil
+	case 26:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 31:
+		return nil, errCBORBreak
+	}
+	return nil, fmt.Errorf("unsupported simple value %d", info)
+}
+
+// float16ToFloat64 将 IEEE 754 半精度浮点转换为 float64
+func float16ToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var value float64
+	switch exp {
+	case 0:
+		value = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		value = -value
+	}
+	return value
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+CBOR 序列化测试
+
+运行命令：
+go test -v -run "^TestCBOR.*$"
+
+测试内容：
+1. 编码与 RFC 8949 附录 A 示例一致
+2. 半精度浮点、时间标签和不定长数据的解码
+3. 结构体标签及往返一致性
+4. 非法数据和尾随数据
+*/
+
+func TestCBOREncoding(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-10, "29"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{1, 2, 3}, "83010203"},
+		{map[string]interface{}{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+		{time.Unix(1363896240, 0), "c11a514b67b0"},
+		{time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC), "c076" + hex.EncodeToString([]byte("2013-03-21T20:04:00.5Z"))},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(CBORFormat)
+	for _, tt := range tests {
+		data, err := marshal.Marshal(tt.value)
+		if err != nil {
+			t.Fatalf("Marshal %v failed: %v", tt.value, err)
+		}
+		if hex.EncodeToString(data) != tt.expected {
+			t.Errorf("Marshal %v: got %x, want %s", tt.value, data, tt.expected)
+		}
+	}
+}
+
+func TestCBORDecoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"f93c00", 1.0},
+		{"f97bff", 65504.0},
+		{"f90001", 5.960464477539063e-08},
+		{"f9fc00", math.Inf(-1)},
+		{"fa47c35000", 100000.0},
+		{"3bffffffffffffffff", nil},
+		{"c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"c11a514b67b0", time.Unix(1363896240, 0).UTC()},
+		{"c1fb41d452d9ec200000", time.Unix(1363896240, 500000000).UTC()},
+		{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", "http://www.example.com"},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []interface{}{}},
+		{"9f018202039f0405ffff", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"a201020304", map[string]interface{}{"1": int64(2), "3": int64(4)}},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(CBORFormat)
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.input)
+		var value interface{}
+		err := marshal.Unmarshal(data, &value)
+		if tt.expected == nil {
+			if err == nil {
+				t.Errorf("Expected error for %s, got %v", tt.input, value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal %s failed: %v", tt.input, err)
+			continue
+		}
+		if expected, ok := tt.expected.(time.Time); ok {
+			if !value.(time.Time).Equal(expected) {
+				t.Errorf("Unmarshal %s: got %v, want %v", tt.input, value, expected)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(value, tt.expected) {
+			t.Errorf("Unmarshal %s: got %#v, want %#v", tt.input, value, tt.expected)
+		}
+	}
+}
+
+type cborTestMessage struct {
+	Kind    string            `cbor:"kind"`
+	Payload []byte            `cbor:"payload"`
+	SentAt  time.Time         `cbor:"sent_at"`
+	Retries uint8             `cbor:"retries"`
+	Headers map[string]string `cbor:"headers,omitempty"`
+	Scores  []float32         `cbor:"scores"`
+	Next    *cborTestMessage  `cbor:"next,omitempty"`
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	message := cborTestMessage{
+		Kind:    "ping",
+		Payload: []byte("hello"),
+		SentAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Retries: 3,
+		Scores:  []float32{0.25, -1},
+		Next:    &cborTestMessage{Kind: "pong", SentAt: time.Unix(0, 1).UTC()},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(CBORFormat)
+	data, err := marshal.Marshal(&message)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded cborTestMessage
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !decoded.SentAt.Equal(message.SentAt) || !decoded.Next.SentAt.Equal(message.Next.SentAt) {
+		t.Errorf("Time mismatch: %v / %v", decoded.SentAt, decoded.Next.SentAt)
+	}
+	decoded.SentAt, decoded.Next.SentAt = message.SentAt, message.Next.SentAt
+	if !reflect.DeepEqual(decoded, message) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", decoded, message)
+	}
+
+	var generic map[string]interface{}
+	if err := marshal.Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Unmarshal to map failed: %v", err)
+	}
+	if _, ok := generic["headers"]; ok {
+		t.Error("omitempty field should be omitted")
+	}
+	if generic["kind"] != "ping" || generic["retries"] != int64(3) {
+		t.Errorf("Unexpected generic value: %v", generic)
+	}
+}
+
+func TestCBORErrors(t *testing.T) {
+	marshal := DefaultMarshalExt().SetFormat(CBORFormat)
+
+	for _, input := range []string{"", "ff", "1c", "62ff", "9f01", "5f01ff", "7f40ff", "f800", "c06161", "0000", "9bffffffffffffffff"} {
+		data, _ := hex.DecodeString(input)
+		var value interface{}
+		if err := marshal.Unmarshal(data, &value); err == nil {
+			t.Errorf("Expected error for %q, got %v", input, value)
+		}
+	}
+
+	deep := strings.Repeat("81", binaryMaxDepth+2) + "f6"
+	data, _ := hex.DecodeString(deep)
+	var value interface{}
+	if err := marshal.Unmarshal(data, &value); err == nil {
+		t.Error("Expected depth error")
+	}
+
+	var target struct {
+		Count int `cbor:"count"`
+	}
+	data, _ = hex.DecodeString("a165636f756e746161")
+	if err := marshal.Unmarshal(data, &target); err == nil || !strings.Contains(err.Error(), "count") {
+		t.Errorf("Type mismatch should report field, got %v", err)
+	}
+}
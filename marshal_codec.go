@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Codec 格式编解码器，options 为调用方 MarshalExt 的当前选项
+type Codec interface {
+	Marshal(v interface{}, options MarshalOptions) ([]byte, error)
+	Unmarshal(data []byte, v interface{}, options MarshalOptions) error
+}
+
+// FormatInfo 已注册格式的信息
+type FormatInfo struct {
+	Format     MarshalFormat
+	Name       string
+	MIMETypes  []string // 第一个为首选 MIME 类型
+	Extensions []string // 文件扩展名，含前导点，如 .json
+	Codec      Codec
+}
+
+// formatRegistry 格式注册表，下标即 MarshalFormat 值
+var formatRegistry struct {
+	sync.RWMutex
+	formats []*FormatInfo
+}
+
+// builtinCodec 由 MarshalExt 内置实现组成的编解码器
+type builtinCodec struct {
+	marshal   func(m *MarshalExt, v interface{}) ([]byte, error)
+	unmarshal func(m *MarshalExt, data []byte, v interface{}) error
+}
+
+func (c builtinCodec) Marshal(v interface{}, options MarshalOptions) ([]byte, error) {
+	return c.marshal(&MarshalExt{options: options}, v)
+}
+
+func (c builtinCodec) Unmarshal(data []byte, v interface{}, options MarshalOptions) error {
+	return c.unmarshal(&MarshalExt{options: options}, data, v)
+}
+
+func init() {
+	builtins := []FormatInfo{
+		{JSONFormat, "json", []string{"application/json", "text/json"}, []string{".json"},
+			builtinCodec{(*MarshalExt).marshalJSON, (*MarshalExt).unmarshalJSON}},
+		{YAMLFormat, "yaml", []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}, []string{".yaml", ".yml"},
+			builtinCodec{(*MarshalExt).marshalYAML, (*MarshalExt).unmarshalYAML}},
+		{XMLFormat, "xml", []string{"application/xml", "text/xml"}, []string{".xml"},
+			builtinCodec{(*MarshalExt).marshalXML, (*MarshalExt).unmarshalXML}},
+		{StringFormat, "string", []string{"text/plain"}, []string{".txt"},
+			builtinCodec{(*MarshalExt).marshalString, (*MarshalExt).unmarshalString}},
+		{TOMLFormat, "toml", []string{"application/toml"}, []string{".toml"},
+			builtinCodec{(*MarshalExt).marshalTOML, (*MarshalExt).unmarshalTOML}},
+		{CSVFormat, "csv", []string{"text/csv"}, []string{".csv"},
+			builtinCodec{(*MarshalExt).marshalCSV, (*MarshalExt).unmarshalCSV}},
+		{INIFormat, "ini", nil, []string{".ini", ".cfg"},
+			builtinCodec{(*MarshalExt).marshalINI, (*MarshalExt).unmarshalINI}},
+		{MsgPackFormat, "msgpack", []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, []string{".msgpack", ".mpk"},
+			builtinCodec{(*MarshalExt).marshalMsgPack, (*MarshalExt).unmarshalMsgPack}},
+		{CBORFormat, "cbor", []string{"application/cbor"}, []string{".cbor"},
+			builtinCodec{(*MarshalExt).marshalCBOR, (*MarshalExt).unmarshalCBOR}},
+	}
+	for i := range builtins {
+		formatRegistry.formats = append(formatRegistry.formats, &builtins[i])
+	}
+}
+
+// RegisterFormat 注册格式并返回其 MarshalFormat 值
+// 名称（大小写不敏感）已存在时替换编解码器、MIME 类型和扩展名，并保留原值，可用于替换内置实现
+func RegisterFormat(name string, codec Codec, mimeTypes []string, extensions []string) MarshalFormat {
+	info := &FormatInfo{
+		Name:      strings.ToLower(name),
+		MIMETypes: make([]string, len(mimeTypes)),
+		Codec:     codec,
+	}
+	for i, mimeType := range mimeTypes {
+		info.MIMETypes[i] = strings.ToLower(mimeType)
+	}
+	for _, ext := range extensions {
+		info.Extensions = append(info.Extensions, normalizeExtension(ext))
+	}
+
+	formatRegistry.Lock()
+	defer formatRegistry.Unlock()
+
+	for i, existing := range formatRegistry.formats {
+		if existing.Name == info.Name {
+			info.Format = MarshalFormat(i)
+			formatRegistry.formats[i] = info
+			return info.Format
+		}
+	}
+	info.Format = MarshalFormat(len(formatRegistry.formats))
+	formatRegistry.formats = append(formatRegistry.formats, info)
+	return info.Format
+}
+
+// Formats 返回所有已注册格式
+func Formats() []FormatInfo {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	result := make([]FormatInfo, len(formatRegistry.formats))
+	for i, info := range formatRegistry.formats {
+		result[i] = *info
+	}
+	return result
+}
+
+// LookupFormat 按名称查找格式，大小写不敏感
+func LookupFormat(name string) (MarshalFormat, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return findFormat(func(info *FormatInfo) bool {
+		return info.Name == name
+	})
+}
+
+// FormatByExtension 按文件扩展名查找格式，参数可以是文件路径、".json" 或 "json"
+func FormatByExtension(path string) (MarshalFormat, bool) {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		ext = path
+	}
+	ext = normalizeExtension(ext)
+	return findFormat(func(info *FormatInfo) bool {
+		for _, e := range info.Extensions {
+			if e == ext {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FormatByContentType 按 Content-Type 查找格式，忽略参数（如 charset）
+// 未直接注册的类型按结构化后缀匹配，如 application/problem+json 对应 json
+func FormatByContentType(contentType string) (MarshalFormat, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, false
+	}
+
+	if format, ok := findFormat(func(info *FormatInfo) bool {
+		for _, m := range info.MIMETypes {
+			if m == mediaType {
+				return true
+			}
+		}
+		return false
+	}); ok {
+		return format, true
+	}
+
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		return LookupFormat(mediaType[i+1:])
+	}
+	return 0, false
+}
+
+// String 返回格式名称
+func (f MarshalFormat) String() string {
+	if f == AutoFormat {
+		return "auto"
+	}
+	if info, ok := formatInfo(f); ok {
+		return info.Name
+	}
+	return fmt.Sprintf("MarshalFormat(%d)", int(f))
+}
+
+// ContentType 返回格式的首选 MIME 类型，未设置时返回 application/octet-stream
+func (f MarshalFormat) ContentType() string {
+	if info, ok := formatInfo(f); ok && len(info.MIMETypes) > 0 {
+		return info.MIMETypes[0]
+	}
+	return "application/octet-stream"
+}
+
+// ContentType 返回序列化器输出的 MIME 类型，AutoFormat 按 JSON 处理
+func (m *MarshalExt) ContentType() string {
+	if m.options.Format == AutoFormat {
+		return JSONFormat.ContentType()
+	}
+	return m.options.Format.ContentType()
+}
+
+// formatInfo 返回格式的注册信息
+func formatInfo(f MarshalFormat) (*FormatInfo, bool) {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	if f < 0 || int(f) >= len(formatRegistry.formats) {
+		return nil, false
+	}
+	return formatRegistry.formats[f], true
+}
+
+// findFormat 返回第一个满足条件的格式
+func findFormat(match func(info *FormatInfo) bool) (MarshalFormat, bool) {
+	formatRegistry.RLock()
+	defer formatRegistry.RUnlock()
+
+	for _, info := range formatRegistry.formats {
+		if match(info) {
+			return info.Format, true
+		}
+	}
+	return 0, false
+}
+
+// lookupCodec 返回格式的编解码器，未注册的格式使用 JSON
+func lookupCodec(f MarshalFormat) Codec {
+	if info, ok := formatInfo(f); ok {
+		return info.Codec
+	}
+	info, _ := formatInfo(JSONFormat)
+	return info.Codec
+}
+
+// normalizeExtension 统一扩展名为带前导点的小写形式
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// LoadFile 读取文件并反序列化，格式由扩展名决定，扩展名无法识别时按内容识别，仍无法识别时使用当前格式
+func (m *MarshalExt) LoadFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file error: %w", err)
+	}
+	if _, ok := FormatByExtension(path); ok {
+		return m.forPath(path).Unmarshal(data, v)
+	}
+	return m.Clone().SetFormat(m.detectOrDefault(data)).Unmarshal(data, v)
+}
+
+// SaveFile 序列化并写入文件，格式由扩展名决定，无法识别时使用当前格式，不做颜色高亮
+func (m *MarshalExt) SaveFile(path string, v interface{}) error {
+	data, err := m.forPath(path).marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write file error: %w", err)
+	}
+	return nil
+}
+
+// forPath 返回按文件扩展名设置格式的副本
+func (m *MarshalExt) forPath(path string) *MarshalExt {
+	if format, ok := FormatByExtension(path); ok {
+		return m.Clone().SetFormat(format)
+	}
+	return m
+}
+
+// LoadFile 使用默认序列化器读取文件
+func LoadFile(path string, v interface{}) error {
+	return DefaultMarshal.LoadFile(path, v)
+}
+
+// SaveFile 使用默认序列化器写入文件
+func SaveFile(path string, v interface{}) error {
+	return DefaultMarshal.SaveFile(path, v)
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+格式注册表测试
+
+运行命令：
+go test -v -run "^Test.*Format.*$|^TestLoadSaveFile$|^TestHTTPFormatNegotiation$"
+
+测试内容：
+1. 内置格式按名称、扩展名、Content-Type 查找
+2. 注册自定义格式并通过 MarshalExt 使用
+3. 按名称替换已注册格式
+4. 按扩展名读写文件
+5. HTTPClient 按格式编码请求并按 Content-Type 解析响应
+*/
+
+// upperCodec 测试用编解码器：字符串转为大写
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}, options MarshalOptions) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("upper codec expects string")
+	}
+	return []byte(strings.ToUpper(s)), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}, options MarshalOptions) error {
+	target, ok := v.(*string)
+	if !ok {
+		return errors.New("upper codec expects *string")
+	}
+	*target = strings.ToLower(string(data))
+	return nil
+}
+
+func TestBuiltinFormatLookup(t *testing.T) {
+	if format, ok := LookupFormat("YAML"); !ok || format != YAMLFormat {
+		t.Errorf("LookupFormat(YAML) = %v, %v", format, ok)
+	}
+	if _, ok := LookupFormat("unknown"); ok {
+		t.Error("Unknown format should not be found")
+	}
+
+	extensions := map[string]MarshalFormat{
+		"config.yml":      YAMLFormat,
+		"/etc/app.TOML":   TOMLFormat,
+		".json":           JSONFormat,
+		"cbor":            CBORFormat,
+		"report.csv":      CSVFormat,
+		"legacy.ini":      INIFormat,
+		"payload.msgpack": MsgPackFormat,
+	}
+	for path, expected := range extensions {
+		if format, ok := FormatByExtension(path); !ok || format != expected {
+			t.Errorf("FormatByExtension(%q) = %v, %v", path, format, ok)
+		}
+	}
+	if _, ok := FormatByExtension("README"); ok {
+		t.Error("Path without extension should not match")
+	}
+
+	contentTypes := map[string]MarshalFormat{
+		"application/json; charset=utf-8": JSONFormat,
+		"Application/YAML":                YAMLFormat,
+		"text/xml":                        XMLFormat,
+		"application/problem+json":        JSONFormat,
+		"application/atom+xml":            XMLFormat,
+		"application/x-msgpack":           MsgPackFormat,
+	}
+	for contentType, expected := range contentTypes {
+		if format, ok := FormatByContentType(contentType); !ok || format != expected {
+			t.Errorf("FormatByContentType(%q) = %v, %v", contentType, format, ok)
+		}
+	}
+	if _, ok := FormatByContentType("image/png"); ok {
+		t.Error("image/png should not match")
+	}
+
+	if JSONFormat.String() != "json" || MarshalFormat(-1).String() != "MarshalFormat(-1)" {
+		t.Errorf("Unexpected String(): %s, %s", JSONFormat, MarshalFormat(-1))
+	}
+	if CBORFormat.ContentType() != "application/cbor" || INIFormat.ContentType() != "application/octet-stream" {
+		t.Errorf("Unexpected ContentType(): %s, %s", CBORFormat.ContentType(), INIFormat.ContentType())
+	}
+	if ct := DefaultMarshalExt().SetFormat(AutoFormat).ContentType(); ct != "application/json" {
+		t.Errorf("AutoFormat should encode as JSON, got %s", ct)
+	}
+	if len(Formats()) < 9 {
+		t.Errorf("Expected built-in formats, got %d", len(Formats()))
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	format := RegisterFormat("Test-Upper", upperCodec{}, []string{"Text/X-Upper"}, []string{"upper"})
+	if format <= CBORFormat {
+		t.Fatalf("Custom format should get a new value, got %d", format)
+	}
+	if format.String() != "test-upper" || format.ContentType() != "text/x-upper" {
+		t.Errorf("Unexpected info: %s %s", format, format.ContentType())
+	}
+	if found, ok := FormatByExtension("a.upper"); !ok || found != format {
+		t.Errorf("FormatByExtension = %v, %v", found, ok)
+	}
+	if found, ok := FormatByContentType("text/x-upper"); !ok || found != format {
+		t.Errorf("FormatByContentType = %v, %v", found, ok)
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(format)
+	out, err := marshal.MarshalToString("hello")
+	if err != nil || out != "HELLO" {
+		t.Errorf("Marshal returned %q, %v", out, err)
+	}
+	var decoded string
+	if err := marshal.UnmarshalFromString("WORLD", &decoded); err != nil || decoded != "world" {
+		t.Errorf("Unmarshal returned %q, %v", decoded, err)
+	}
+	if _, err := marshal.Marshal(1); err == nil {
+		t.Error("Codec errors should be returned")
+	}
+
+	// 同名注册替换编解码器并保留原值
+	again := RegisterFormat("test-upper", upperCodec{}, nil, []string{".up"})
+	if again != format {
+		t.Errorf("Re-registering should keep value %d, got %d", format, again)
+	}
+	if _, ok := FormatByExtension("a.upper"); ok {
+		t.Error("Old extension should be replaced")
+	}
+}
+
+func TestLoadSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	config := map[string]interface{}{"name": "app", "port": 8080}
+
+	for _, name := range []string{"config.json", "config.yaml", "config.toml", "config.cbor"} {
+		path := filepath.Join(dir, name)
+		if err := SaveFile(path, config); err != nil {
+			t.Fatalf("SaveFile(%s) failed: %v", name, err)
+		}
+		var loaded struct {
+			Name string `json:"name" yaml:"name" toml:"name" cbor:"name"`
+			Port int    `json:"port" yaml:"port" toml:"port" cbor:"port"`
+		}
+		if err := LoadFile(path, &loaded); err != nil {
+			t.Fatalf("LoadFile(%s) failed: %v", name, err)
+		}
+		if loaded.Name != "app" || loaded.Port != 8080 {
+			t.Errorf("%s: unexpected value %+v", name, loaded)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if !strings.Contains(string(data), "name: app") {
+		t.Errorf("YAML file should be written as YAML: %s", data)
+	}
+
+	// 无法识别的扩展名使用当前格式
+	path := filepath.Join(dir, "config.data")
+	if err := DefaultMarshalExt().SetFormat(YAMLFormat).SaveFile(path, config); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "name: app") {
+		t.Errorf("Unknown extension should use current format: %s", data)
+	}
+
+	if err := LoadFile(filepath.Join(dir, "missing.json"), &config); err == nil {
+		t.Error("Missing file should fail")
+	}
+}
+
+func TestHTTPFormatNegotiation(t *testing.T) {
+	var gotContentType, gotAccept string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotAccept = r.Header.Get("Accept")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte("name: app\nport: 8080\n"))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL).SetFormat(YAMLFormat)
+	resp := client.Post("/config", map[string]string{"name": "app"})
+	if resp.Error != nil {
+		t.Fatalf("Request failed: %v", resp.Error)
+	}
+	if gotContentType != "application/yaml" || gotAccept != "application/yaml" {
+		t.Errorf("Unexpected headers: Content-Type=%q Accept=%q", gotContentType, gotAccept)
+	}
+	if !bytes.Equal(gotBody, []byte("name: app\n")) {
+		t.Errorf("Body should be YAML: %q", gotBody)
+	}
+
+	var decoded struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	}
+	if err := resp.Decode(&decoded); err != nil || decoded.Name != "app" || decoded.Port != 8080 {
+		t.Errorf("Decode returned %+v, %v", decoded, err)
+	}
+
+	// GET 不带请求体时只设置 Accept，显式设置的请求头优先
+	client.SetHeader("Accept", "application/json")
+	client.Get("/config", nil)
+	if gotContentType != "" || gotAccept != "application/json" {
+		t.Errorf("Unexpected headers: Content-Type=%q Accept=%q", gotContentType, gotAccept)
+	}
+
+	// 未设置格式时保持 JSON 且不附加请求头
+	NewHTTPClient(server.URL).Post("/config", map[string]string{"name": "app"})
+	if gotContentType != "" || string(gotBody) != `{"name":"app"}` {
+		t.Errorf("Default client should send plain JSON: %q %q", gotContentType, gotBody)
+	}
+}
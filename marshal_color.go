@@ -0,0 +1,408 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/so68/utils/logger"
+)
+
+// ColorMode 颜色高亮模式
+type ColorMode int
+
+const (
+	// ColorNever 不高亮（默认）
+	ColorNever ColorMode = iota
+	// ColorAuto 输出为终端时高亮，设置了 NO_COLOR 环境变量或 TERM=dumb 时不高亮
+	ColorAuto
+	// ColorAlways 总是高亮
+	ColorAlways
+)
+
+// ColorScheme 高亮颜色，值为 ANSI 转义序列，为空的类别不着色
+type ColorScheme struct {
+	Key    string // 对象键
+	String string // 字符串
+	Number string // 数字
+	Bool   string // 布尔值
+	Null   string // null
+}
+
+// DefaultColorScheme 默认高亮颜色，复用 logger 包的颜色代码
+var DefaultColorScheme = ColorScheme{
+	Key:    logger.ColorBlue,
+	String: logger.ColorGreen,
+	Number: logger.ColorCyan,
+	Bool:   logger.ColorYellow,
+	Null:   logger.ColorMagenta,
+}
+
+// isTerminal 判断 Writer 是否为终端
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// colorEnabled 判断输出到 w 时是否高亮，仅 JSON（规范 JSON 除外）和 YAML 支持高亮，w 为 nil 时只有 ColorAlways 高亮
+func (m *MarshalExt) colorEnabled(w io.Writer) bool {
+	switch m.options.Format {
+	case JSONFormat:
+		if m.options.Canonical {
+			return false
+		}
+	case YAMLFormat:
+	default:
+		return false
+	}
+
+	switch m.options.Color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false
+		}
+		return w != nil && isTerminal(w)
+	}
+	return false
+}
+
+// marshalColorString 序列化为字符串，按输出到 w 时的规则决定是否高亮
+func (m *MarshalExt) marshalColorString(v interface{}, w io.Writer) (string, error) {
+	data, err := m.marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if m.colorEnabled(w) {
+		data = m.colorize(data)
+	}
+	return string(data), nil
+}
+
+// colorize 按当前格式和颜色方案高亮编码结果
+func (m *MarshalExt) colorize(data []byte) []byte {
+	scheme := m.options.ColorScheme
+	if scheme == (ColorScheme{}) {
+		scheme = DefaultColorScheme
+	}
+	if m.options.Format == YAMLFormat {
+		return ColorizeYAML(data, scheme)
+	}
+	return ColorizeJSON(data, scheme)
+}
+
+// paintColor 用颜色包裹文本，颜色为空时原样写入
+func paintColor(buf *bytes.Buffer, color string, text string) {
+	if color == "" || text == "" {
+		buf.WriteString(text)
+		return
+	}
+	buf.WriteString(color)
+	buf.WriteString(text)
+	buf.WriteString(logger.ColorReset)
+}
+
+// ColorizeJSON 为 JSON 文本添加 ANSI 颜色，不校验输入，截断的 JSON 也能处理
+func ColorizeJSON(data []byte, scheme ColorScheme) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data) * 2)
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end := scanJSONString(data, i)
+			color := scheme.String
+			if isJSONKey(data, end) {
+				color = scheme.Key
+			}
+			paintColor(&buf, color, string(data[i:end]))
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(data) && strings.IndexByte("0123456789+-.eE", data[end]) >= 0 {
+				end++
+			}
+			paintColor(&buf, scheme.Number, string(data[i:end]))
+			i = end
+		case bytes.HasPrefix(data[i:], []byte("true")):
+			paintColor(&buf, scheme.Bool, "true")
+			i += 4
+		case bytes.HasPrefix(data[i:], []byte("false")):
+			paintColor(&buf, scheme.Bool, "false")
+			i += 5
+		case bytes.HasPrefix(data[i:], []byte("null")):
+			paintColor(&buf, scheme.Null, "null")
+			i += 4
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	return buf.Bytes()
+}
+
+// scanJSONString 返回从 start 处引号开始的字符串的结束位置，未结束时返回数据末尾
+func scanJSONString(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// isJSONKey 判断 end 之后跳过空白是否为冒号，即前面的字符串是对象键
+func isJSONKey(data []byte, end int) bool {
+	for end < len(data) && strings.IndexByte(" \t\r\n", data[end]) >= 0 {
+		end++
+	}
+	return end < len(data) && data[end] == ':'
+}
+
+// yamlNumberPattern 匹配 YAML 数字标量
+var yamlNumberPattern = regexp.MustCompile(`^[-+]?(\.(inf|Inf|INF)|\.(nan|NaN|NAN)|0x[0-9a-fA-F_]+|0o[0-7_]+|0b[01_]+|[0-9][0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|\.[0-9][0-9_]*([eE][-+]?[0-9]+)?)$`)
+
+// yamlColorizer 按行为 YAML 文本添加颜色
+type yamlColorizer struct {
+	scheme      ColorScheme
+	buf         bytes.Buffer
+	blockIndent int  // 块标量（| 或 >）所属节点的列，-1 表示不在块标量中
+	quote       byte // 跨行的引号字符串尚未结束时为引号字符
+	flowDepth   int  // 跨行的流式集合的嵌套深度
+}
+
+// ColorizeYAML 为 YAML 文本添加 ANSI 颜色，不校验输入
+func ColorizeYAML(data []byte, scheme ColorScheme) []byte {
+	c := &yamlColorizer{scheme: scheme, blockIndent: -1}
+	c.buf.Grow(len(data) * 2)
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			c.line(string(data))
+			break
+		}
+		c.line(string(data[:i]))
+		c.buf.WriteByte('\n')
+		data = data[i+1:]
+	}
+	return c.buf.Bytes()
+}
+
+// line 处理一行
+func (c *yamlColorizer) line(s string) {
+	trimmed := strings.TrimLeft(s, " ")
+	indent := len(s) - len(trimmed)
+
+	if c.blockIndent >= 0 {
+		if trimmed == "" || indent > c.blockIndent {
+			c.buf.WriteString(s[:indent])
+			paintColor(&c.buf, c.scheme.String, trimmed)
+			return
+		}
+		c.blockIndent = -1
+	}
+	if c.quote != 0 {
+		end, closed := scanYAMLQuoted(s, indent, c.quote)
+		c.buf.WriteString(s[:indent])
+		paintColor(&c.buf, c.scheme.String, s[indent:end])
+		if closed {
+			c.quote = 0
+			c.buf.WriteString(s[end:])
+		}
+		return
+	}
+
+	c.buf.WriteString(s[:indent])
+	if c.flowDepth > 0 {
+		c.flow(trimmed)
+		return
+	}
+	if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "--- ") || strings.HasPrefix(trimmed, "#") {
+		c.buf.WriteString(trimmed)
+		return
+	}
+
+	// 序列项标记，一行可能有多个（- - a）
+	col := indent
+	for trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+		rest := strings.TrimLeft(trimmed[1:], " ")
+		c.buf.WriteString(trimmed[:len(trimmed)-len(rest)])
+		if rest == "" {
+			return
+		}
+		next := col + len(trimmed) - len(rest)
+		if !strings.HasPrefix(rest, "- ") && rest != "-" {
+			c.node(rest, col, next)
+			return
+		}
+		col = next
+		trimmed = rest
+	}
+	c.node(trimmed, col, col)
+}
+
+// node 处理一个节点，可能是 "键: 值" 或单独的值
+// 块标量的内容须比所属节点更靠右：序列项的值属于 - 所在列 itemCol，键值对的值属于键所在列 keyCol
+func (c *yamlColorizer) node(s string, itemCol, keyCol int) {
+	if key, rest, ok := splitYAMLKey(s); ok {
+		paintColor(&c.buf, c.scheme.Key, key)
+		c.buf.WriteByte(':')
+		value := strings.TrimLeft(rest, " ")
+		c.buf.WriteString(rest[:len(rest)-len(value)])
+		c.value(value, keyCol)
+		return
+	}
+	c.value(s, itemCol)
+}
+
+// value 处理一个值
+func (c *yamlColorizer) value(s string, col int) {
+	if s == "" {
+		return
+	}
+	switch s[0] {
+	case '|', '>':
+		c.buf.WriteString(s)
+		c.blockIndent = col
+	case '{', '[':
+		c.flow(s)
+	case '"', '\'':
+		end, closed := scanYAMLQuoted(s, 1, s[0])
+		paintColor(&c.buf, c.scheme.String, s[:end])
+		if !closed {
+			c.quote = s[0]
+			return
+		}
+		c.buf.WriteString(s[end:])
+	case '&', '!':
+		// 锚点和标签后面跟着实际的值
+		i := strings.IndexByte(s, ' ')
+		if i < 0 {
+			c.buf.WriteString(s)
+			return
+		}
+		c.buf.WriteString(s[:i+1])
+		c.value(s[i+1:], col)
+	case '*':
+		c.buf.WriteString(s)
+	default:
+		paintColor(&c.buf, c.scalarColor(s), s)
+	}
+}
+
+// flow 处理流式集合（{a: 1, b: [x, y]}），跨行时记录嵌套深度
+func (c *yamlColorizer) flow(s string) {
+	for i := 0; i < len(s); {
+		ch := s[i]
+		switch {
+		case ch == '{' || ch == '[':
+			c.flowDepth++
+			c.buf.WriteByte(ch)
+			i++
+		case ch == '}' || ch == ']':
+			if c.flowDepth > 0 {
+				c.flowDepth--
+			}
+			c.buf.WriteByte(ch)
+			i++
+		case strings.IndexByte(", :", ch) >= 0:
+			c.buf.WriteByte(ch)
+			i++
+		case ch == '"' || ch == '\'':
+			end, _ := scanYAMLQuoted(s, i+1, ch)
+			color := c.scheme.String
+			if isYAMLFlowKey(s, end) {
+				color = c.scheme.Key
+			}
+			paintColor(&c.buf, color, s[i:end])
+			i = end
+		default:
+			end := i
+			for end < len(s) && strings.IndexByte(",{}[]", s[end]) < 0 && !isYAMLFlowKey(s, end) {
+				end++
+			}
+			token := strings.TrimRight(s[i:end], " ")
+			color := c.scalarColor(token)
+			if isYAMLFlowKey(s, i+len(token)) {
+				color = c.scheme.Key
+			}
+			paintColor(&c.buf, color, token)
+			i += len(token)
+		}
+	}
+}
+
+// scalarColor 返回普通标量的颜色
+func (c *yamlColorizer) scalarColor(s string) string {
+	switch s {
+	case "null", "Null", "NULL", "~":
+		return c.scheme.Null
+	case "true", "True", "TRUE", "false", "False", "FALSE":
+		return c.scheme.Bool
+	}
+	if yamlNumberPattern.MatchString(s) {
+		return c.scheme.Number
+	}
+	return c.scheme.String
+}
+
+// splitYAMLKey 拆分 "键: 值"，键可以带引号
+func splitYAMLKey(s string) (key, rest string, ok bool) {
+	if s == "" {
+		return "", "", false
+	}
+	switch s[0] {
+	case '"', '\'':
+		end, closed := scanYAMLQuoted(s, 1, s[0])
+		if closed && end < len(s) && s[end] == ':' && (end+1 == len(s) || s[end+1] == ' ') {
+			return s[:end], s[end+1:], true
+		}
+		return "", "", false
+	case '{', '[', '|', '>', '&', '*', '!', '#':
+		return "", "", false
+	}
+	idx := strings.Index(s, ": ")
+	if idx < 0 && strings.HasSuffix(s, ":") {
+		idx = len(s) - 1
+	}
+	if idx <= 0 {
+		return "", "", false
+	}
+	return s[:idx], s[idx+1:], true
+}
+
+// scanYAMLQuoted 从 start 开始查找引号字符串的结尾，返回结尾之后的位置和是否找到
+// 双引号支持反斜杠转义，单引号以两个单引号表示一个单引号
+func scanYAMLQuoted(s string, start int, quote byte) (int, bool) {
+	for i := start; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// isYAMLFlowKey 判断 end 处（跳过空格后）是否为键后的冒号
+func isYAMLFlowKey(s string, end int) bool {
+	for end < len(s) && s[end] == ' ' {
+		end++
+	}
+	return end < len(s) && s[end] == ':' && (end+1 == len(s) || strings.IndexByte(" ,}]", s[end+1]) >= 0)
+}
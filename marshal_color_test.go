@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/so68/utils/logger"
+)
+
+/*
+JSON/YAML 颜色高亮测试
+
+运行命令：
+go test -v -run "^Test(Colorize|MarshalColor).*$"
+
+测试内容：
+1. JSON 的键、字符串、数字、布尔值和 null 使用不同颜色
+2. YAML 块风格、序列、块标量、引号字符串和流式风格的高亮
+3. ColorNever、ColorAlways、ColorAuto 模式，非终端、NO_COLOR 和 Marshal 返回字节时不高亮
+4. 不支持高亮的格式、规范 JSON 和 SaveFile 不输出颜色
+*/
+
+// markerScheme 使用易读的标记代替 ANSI 颜色，便于比较输出
+var markerScheme = ColorScheme{Key: "<k>", String: "<s>", Number: "<n>", Bool: "<b>", Null: "<0>"}
+
+// readableColors 将颜色重置序列替换为 </>
+func readableColors(data []byte) string {
+	return strings.ReplaceAll(string(data), logger.ColorReset, "</>")
+}
+
+func TestColorizeJSON(t *testing.T) {
+	input := `{"name": "a\"b", "n": -1.5e3, "ok": true, "off": false, "nil": null, "list": ["x", 2]}`
+	expected := `{<k>"name"</>: <s>"a\"b"</>, <k>"n"</>: <n>-1.5e3</>, <k>"ok"</>: <b>true</>, <k>"off"</>: <b>false</>, ` +
+		`<k>"nil"</>: <0>null</>, <k>"list"</>: [<s>"x"</>, <n>2</>]}`
+	if got := readableColors(ColorizeJSON([]byte(input), markerScheme)); got != expected {
+		t.Errorf("ColorizeJSON =\n%s\nwant\n%s", got, expected)
+	}
+
+	// 截断的 JSON 不会出错
+	if got := readableColors(ColorizeJSON([]byte(`{"key": "unterminated`), markerScheme)); got != `{<k>"key"</>: <s>"unterminated</>` {
+		t.Errorf("Truncated JSON = %s", got)
+	}
+
+	// 为空的类别不着色
+	if got := readableColors(ColorizeJSON([]byte(`{"a": 1}`), ColorScheme{Number: "<n>"})); got != `{"a": <n>1</>}` {
+		t.Errorf("Partial scheme = %s", got)
+	}
+}
+
+func TestColorizeYAML(t *testing.T) {
+	input := strings.Join([]string{
+		"name: 'a: b'",
+		"version: \"1.0\"",
+		"count: 3",
+		"ratio: .5",
+		"enabled: false",
+		"missing: null",
+		"items:",
+		"  - plain text",
+		"  - - 1",
+		"    - ~",
+		"  - key: |-",
+		"      line one",
+		"",
+		"      line two",
+		"    after: x",
+		"flow: {a: 1, \"b\": [x, true]}",
+		"\"quoted key\": &anchor value",
+		"alias: *anchor",
+	}, "\n")
+	expected := strings.Join([]string{
+		"<k>name</>: <s>'a: b'</>",
+		"<k>version</>: <s>\"1.0\"</>",
+		"<k>count</>: <n>3</>",
+		"<k>ratio</>: <n>.5</>",
+		"<k>enabled</>: <b>false</>",
+		"<k>missing</>: <0>null</>",
+		"<k>items</>:",
+		"  - <s>plain text</>",
+		"  - - <n>1</>",
+		"    - <0>~</>",
+		"  - <k>key</>: |-",
+		"      <s>line one</>",
+		"",
+		"      <s>line two</>",
+		"    <k>after</>: <s>x</>",
+		"<k>flow</>: {<k>a</>: <n>1</>, <k>\"b\"</>: [<s>x</>, <b>true</>]}",
+		"<k>\"quoted key\"</>: &anchor <s>value</>",
+		"<k>alias</>: *anchor",
+	}, "\n")
+	if got := readableColors(ColorizeYAML([]byte(input), markerScheme)); got != expected {
+		t.Errorf("ColorizeYAML =\n%s\nwant\n%s", got, expected)
+	}
+
+	// 跨行的引号字符串和流式集合
+	multiline := "text: \"first\n  second\"\nlist: [a,\n  b]\nnext: 1\n"
+	expected = "<k>text</>: <s>\"first</>\n  <s>second\"</>\n<k>list</>: [<s>a</>,\n  <s>b</>]\n<k>next</>: <n>1</>\n"
+	if got := readableColors(ColorizeYAML([]byte(multiline), markerScheme)); got != expected {
+		t.Errorf("ColorizeYAML multiline =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestMarshalColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	value := map[string]interface{}{"name": "svc", "port": 8080, "debug": true}
+
+	plain := DefaultMarshalExt().SetPretty(true)
+	if out := plain.MustToJSON(value); strings.Contains(out, "\033[") {
+		t.Errorf("ColorNever should not color output: %q", out)
+	}
+
+	always := DefaultMarshalExt().SetColor(ColorAlways).SetColorScheme(markerScheme)
+	if out, _ := always.MarshalToString(value); readableColors([]byte(out)) != `{<k>"debug"</>:<b>true</>,<k>"name"</>:<s>"svc"</>,<k>"port"</>:<n>8080</>}` {
+		t.Errorf("ColorAlways JSON = %s", readableColors([]byte(out)))
+	}
+	if out, _ := always.Clone().SetFormat(YAMLFormat).MarshalToString(value); readableColors([]byte(out)) != "<k>debug</>: <b>true</>\n<k>name</>: <s>svc</>\n<k>port</>: <n>8080</>\n" {
+		t.Errorf("ColorAlways YAML = %s", readableColors([]byte(out)))
+	}
+	if out, _ := DefaultMarshalExt().SetColor(ColorAlways).MarshalToString(true); out != logger.ColorYellow+"true"+logger.ColorReset {
+		t.Errorf("Default scheme = %q", out)
+	}
+
+	// 不支持高亮的格式和规范 JSON 不输出颜色
+	for _, ext := range []*MarshalExt{always.Clone().SetFormat(XMLFormat), always.Clone().SetFormat(TOMLFormat), always.Clone().SetCanonical(true)} {
+		if out, err := ext.MarshalToString(value); err != nil || strings.Contains(out, "<k>") {
+			t.Errorf("Unsupported format colored: %q, %v", out, err)
+		}
+	}
+
+	// ColorAuto 按 Writer 是否为终端决定
+	auto := DefaultMarshalExt().SetColor(ColorAuto).SetColorScheme(markerScheme)
+	var buf bytes.Buffer
+	if err := auto.MarshalToWriter(&buf, value); err != nil || strings.Contains(buf.String(), "<k>") {
+		t.Errorf("ColorAuto to buffer = %q, %v", buf.String(), err)
+	}
+	if out, _ := DefaultMarshalExt().ToColorJSON(value); out != MustToPrettyJSON(value) {
+		t.Errorf("ToColorJSON without terminal = %q", out)
+	}
+
+	original := isTerminal
+	isTerminal = func(io.Writer) bool { return true }
+	defer func() { isTerminal = original }()
+
+	buf.Reset()
+	if err := auto.MarshalToWriter(&buf, value); err != nil || !strings.Contains(readableColors(buf.Bytes()), `<k>"name"</>`) {
+		t.Errorf("ColorAuto to terminal = %q, %v", buf.String(), err)
+	}
+	if out, _ := auto.ToColorYAML(value); !strings.Contains(readableColors([]byte(out)), "<k>name</>: <s>svc</>") {
+		t.Errorf("ToColorYAML on terminal = %q", out)
+	}
+	// Marshal 返回的字节可能用于响应体或文件，ColorAuto 时不高亮
+	if out, _ := auto.MarshalToString(value); strings.Contains(out, "<k>") {
+		t.Errorf("ColorAuto Marshal should not color output: %q", out)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if out, _ := auto.MarshalToString(value); strings.Contains(out, "<k>") {
+		t.Errorf("NO_COLOR should disable color: %q", out)
+	}
+
+	// 写入文件时总是不高亮
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := always.SaveFile(path, value); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "<k>") {
+		t.Errorf("SaveFile should not color output: %s", data)
+	}
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatDetector 可由自定义 Codec 实现，用于 DetectFormat 识别该格式
+type FormatDetector interface {
+	Detect(data []byte) bool
+}
+
+// DetectFormat 根据内容识别格式
+// 依次尝试实现了 FormatDetector 的自定义格式、JSON、XML、二进制格式（MessagePack、CBOR）、
+// TOML、INI、YAML（结果为映射或序列）和 CSV（至少两行且列数一致），无法识别时返回 false
+func DetectFormat(data []byte) (MarshalFormat, bool) {
+	for _, info := range Formats() {
+		if detector, ok := info.Codec.(FormatDetector); ok && detector.Detect(data) {
+			return info.Format, true
+		}
+	}
+
+	if !utf8.Valid(data) {
+		return detectBinaryFormat(data)
+	}
+
+	text := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(text) == 0 {
+		return 0, false
+	}
+
+	switch {
+	case (text[0] == '{' || text[0] == '[') && json.Valid(text):
+		return JSONFormat, true
+	case text[0] == '<' && isXMLDocument(text):
+		return XMLFormat, true
+	case text[0] < 0x20 || text[0] == 0x7f:
+		// 控制字符开头但仍是合法 UTF-8 的二进制数据，如 MessagePack 的小整数数组
+		if format, ok := detectBinaryFormat(data); ok {
+			return format, true
+		}
+	}
+
+	if bytes.HasPrefix(text, []byte("---")) {
+		return YAMLFormat, true
+	}
+	if hasKeyValueLines(text) {
+		var tomlValue map[string]interface{}
+		if toml.Unmarshal(text, &tomlValue) == nil {
+			return TOMLFormat, true
+		}
+		if _, err := parseINI(text); err == nil {
+			return INIFormat, true
+		}
+	}
+
+	var yamlValue interface{}
+	if yaml.Unmarshal(text, &yamlValue) == nil {
+		switch yamlValue.(type) {
+		case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+			return YAMLFormat, true
+		}
+	}
+
+	if isCSVDocument(text) {
+		return CSVFormat, true
+	}
+	return 0, false
+}
+
+// detectBinaryFormat 完整解码且无多余数据时识别为 MessagePack 或 CBOR，CBOR 自描述标签优先
+func detectBinaryFormat(data []byte) (MarshalFormat, bool) {
+	if bytes.HasPrefix(data, []byte{0xd9, 0xd9, 0xf7}) {
+		return CBORFormat, true
+	}
+
+	m := DefaultMarshalExt()
+	var value interface{}
+	if m.unmarshalMsgPack(data, &value) == nil {
+		return MsgPackFormat, true
+	}
+	if m.unmarshalCBOR(data, &value) == nil {
+		return CBORFormat, true
+	}
+	return 0, false
+}
+
+// isXMLDocument 判断是否为含根元素的合法 XML
+func isXMLDocument(data []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	hasRoot := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return hasRoot
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			hasRoot = true
+		}
+	}
+}
+
+// hasKeyValueLines 判断是否为 key = value 或 [section] 组成的文本（TOML、INI）
+// key 必须是裸标识符或带引号的 TOML 键，因此 "dsn: host=localhost" 这类 YAML 行不会被误判
+func hasKeyValueLines(data []byte) bool {
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+		default:
+			key, _, ok := strings.Cut(line, "=")
+			if !ok || !isKeyValueKey(strings.TrimSpace(key)) {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// isKeyValueKey 判断是否为裸标识符（字母、数字、_、-，可用 . 分隔）或带引号的键
+func isKeyValueKey(key string) bool {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return true
+	}
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isCSVDocument 判断是否为至少两行、列数一致且多于一列的 CSV
+func isCSVDocument(data []byte) bool {
+	reader := csv.NewReader(bytes.NewReader(data))
+	records, err := reader.ReadAll()
+	return err == nil && len(records) >= 2 && len(records[0]) > 1
+}
+
+// detectOrDefault 识别格式，无法识别时返回当前格式
+func (m *MarshalExt) detectOrDefault(data []byte) MarshalFormat {
+	if format, ok := DetectFormat(data); ok {
+		return format
+	}
+	return m.options.Format
+}
+
+// Convert 将 from 格式的数据转换为 to 格式，from 为 AutoFormat 时自动识别
+// 数据先解码为通用结构（map[string]interface{}、[]interface{} 和标量），再按当前选项编码为目标格式；
+// XML 之间转换时保留根元素名
+func (m *MarshalExt) Convert(data []byte, from, to MarshalFormat) ([]byte, error) {
+	if from == AutoFormat {
+		var ok bool
+		if from, ok = DetectFormat(data); !ok {
+			return nil, fmt.Errorf("convert error: unable to detect format")
+		}
+	}
+
+	target := m.Clone().SetFormat(to)
+	value, root, err := m.decodeGeneric(data, from)
+	if err != nil {
+		return nil, fmt.Errorf("convert error: %w", err)
+	}
+	if root != "" && to == XMLFormat {
+		target.SetXMLRoot(root)
+	}
+
+	out, err := target.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("convert error: %w", err)
+	}
+	return out, nil
+}
+
+// decodeGeneric 按格式解码为通用结构，XML 同时返回根元素名
+func (m *MarshalExt) decodeGeneric(data []byte, format MarshalFormat) (interface{}, string, error) {
+	ext := m.Clone().SetFormat(format).SetUseNumber(true).SetSchema(nil)
+
+	switch format {
+	case StringFormat:
+		return string(data), "", nil
+	case XMLFormat:
+		if ext.options.MaxDepth > 0 || ext.options.DisallowTrailingData {
+			if err := ext.checkXMLStructure(data); err != nil {
+				return nil, "", err
+			}
+		}
+		root, value, err := ext.decodeXMLTree(data)
+		return value, root, err
+	case CSVFormat:
+		var rows []map[string]interface{}
+		if err := ext.Unmarshal(data, &rows); err != nil {
+			return nil, "", err
+		}
+		items := make([]interface{}, len(rows))
+		for i, row := range rows {
+			items[i] = row
+		}
+		return items, "", nil
+	case TOMLFormat, INIFormat:
+		var value map[string]interface{}
+		if err := ext.Unmarshal(data, &value); err != nil {
+			return nil, "", err
+		}
+		return value, "", nil
+	}
+
+	var value interface{}
+	if err := ext.Unmarshal(data, &value); err != nil {
+		return nil, "", err
+	}
+	return normalizeGeneric(value), "", nil
+}
+
+// normalizeGeneric 统一通用结构：json.Number 转换为 int64/uint64/float64，
+// 超出 uint64 的整数保留为 json.Number 以免丢失精度，非字符串键的 map 转换为字符串键
+func normalizeGeneric(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return n
+		}
+		if !strings.ContainsAny(value.String(), ".eE") {
+			return value
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeGeneric(item)
+		}
+		return value
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			result[fmt.Sprint(k)] = normalizeGeneric(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeGeneric(item)
+		}
+		return value
+	}
+	return v
+}
+
+// Convert 使用默认序列化器转换格式
+func Convert(data []byte, from, to MarshalFormat) ([]byte, error) {
+	return DefaultMarshal.Convert(data, from, to)
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+格式识别与转换测试
+
+运行命令：
+go test -v -run "^TestDetectFormat$|^TestConvert.*$|^TestAutoFormat$|^TestLoadFileDetect$"
+
+测试内容：
+1. 按内容识别各内置格式
+2. JSON、YAML、TOML、INI、CSV、XML、二进制格式之间的转换
+3. XML 之间转换保留根元素名
+4. AutoFormat 解码时自动识别格式
+5. 扩展名无法识别时按内容读取文件
+*/
+
+func TestDetectFormat(t *testing.T) {
+	payload := map[string]interface{}{"name": "app", "ports": []int{80, 443}}
+	msgpack, err := DefaultMarshalExt().SetFormat(MsgPackFormat).Marshal(payload)
+	if err != nil {
+		t.Fatalf("MsgPack marshal failed: %v", err)
+	}
+	cbor, err := DefaultMarshalExt().SetFormat(CBORFormat).Marshal(payload)
+	if err != nil {
+		t.Fatalf("CBOR marshal failed: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		data     []byte
+		expected MarshalFormat
+	}{
+		{"json object", []byte(`  {"name": "app"}`), JSONFormat},
+		{"json array", []byte(`[1, 2, 3]`), JSONFormat},
+		{"json with bom", []byte("\xef\xbb\xbf{\"a\":1}"), JSONFormat},
+		{"xml", []byte(`<?xml version="1.0"?><config><name>app</name></config>`), XMLFormat},
+		{"yaml", []byte("name: app\nports:\n  - 80\n"), YAMLFormat},
+		{"yaml document", []byte("---\n- a\n- b\n"), YAMLFormat},
+		{"toml", []byte("name = \"app\"\n\n[server]\nport = 8080\n"), TOMLFormat},
+		{"ini", []byte("; comment\nname = app\n\n[server]\nport = 8080\n"), INIFormat},
+		{"toml quoted key", []byte("\"my key\" = 1\nsite.name = \"x\"\n"), TOMLFormat},
+		{"yaml with equals", []byte("dsn: host=localhost port=5432\nquery: a=b\n"), YAMLFormat},
+		{"csv", []byte("id,name\n1,alice\n2,bob\n"), CSVFormat},
+		{"msgpack", msgpack, MsgPackFormat},
+		{"cbor", cbor, CBORFormat},
+		{"cbor self-describe", append([]byte{0xd9, 0xd9, 0xf7}, cbor...), CBORFormat},
+	}
+	for _, c := range cases {
+		if format, ok := DetectFormat(c.data); !ok || format != c.expected {
+			t.Errorf("%s: DetectFormat = %v, %v, want %v", c.name, format, ok, c.expected)
+		}
+	}
+
+	for _, data := range []string{"", "   ", "just some text", "{broken"} {
+		if format, ok := DetectFormat([]byte(data)); ok {
+			t.Errorf("DetectFormat(%q) = %v, want unknown", data, format)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	input := []byte(`{"debug":true,"name":"app","port":8080,"tags":["a","b"]}`)
+
+	yamlData, err := Convert(input, JSONFormat, YAMLFormat)
+	if err != nil {
+		t.Fatalf("JSON to YAML failed: %v", err)
+	}
+	if !strings.Contains(string(yamlData), "port: 8080") || !strings.Contains(string(yamlData), "- a") {
+		t.Errorf("Unexpected YAML: %s", yamlData)
+	}
+
+	jsonData, err := Convert(yamlData, AutoFormat, JSONFormat)
+	if err != nil {
+		t.Fatalf("YAML to JSON failed: %v", err)
+	}
+	if string(jsonData) != string(input) {
+		t.Errorf("Round trip = %s, want %s", jsonData, input)
+	}
+
+	tomlData, err := Convert(input, JSONFormat, TOMLFormat)
+	if err != nil {
+		t.Fatalf("JSON to TOML failed: %v", err)
+	}
+	if !strings.Contains(string(tomlData), `name = "app"`) {
+		t.Errorf("Unexpected TOML: %s", tomlData)
+	}
+
+	msgpack, err := Convert(input, JSONFormat, MsgPackFormat)
+	if err != nil {
+		t.Fatalf("JSON to MsgPack failed: %v", err)
+	}
+	cbor, err := Convert(msgpack, AutoFormat, CBORFormat)
+	if err != nil {
+		t.Fatalf("MsgPack to CBOR failed: %v", err)
+	}
+	back, err := Convert(cbor, CBORFormat, JSONFormat)
+	if err != nil {
+		t.Fatalf("CBOR to JSON failed: %v", err)
+	}
+	if string(back) != string(input) {
+		t.Errorf("Binary round trip = %s, want %s", back, input)
+	}
+
+	// 超出 int64 的整数不丢失精度
+	for _, big := range []string{`{"id":12345678901234567890}`, `{"id":123456789012345678901234567890}`} {
+		out, err := Convert([]byte(big), JSONFormat, JSONFormat)
+		if err != nil || string(out) != big {
+			t.Errorf("Convert(%s) = %s, %v", big, out, err)
+		}
+	}
+}
+
+func TestConvertTabular(t *testing.T) {
+	csvData := []byte("id,name\n1,alice\n2,bob\n")
+	jsonData, err := Convert(csvData, CSVFormat, JSONFormat)
+	if err != nil {
+		t.Fatalf("CSV to JSON failed: %v", err)
+	}
+	expected := `[{"id":"1","name":"alice"},{"id":"2","name":"bob"}]`
+	if string(jsonData) != expected {
+		t.Errorf("CSV to JSON = %s, want %s", jsonData, expected)
+	}
+
+	back, err := Convert(jsonData, JSONFormat, CSVFormat)
+	if err != nil {
+		t.Fatalf("JSON to CSV failed: %v", err)
+	}
+	if string(back) != string(csvData) {
+		t.Errorf("JSON to CSV = %q, want %q", back, csvData)
+	}
+
+	iniData := []byte("name = app\n\n[server]\nport = 8080\n")
+	jsonData, err = Convert(iniData, INIFormat, JSONFormat)
+	if err != nil {
+		t.Fatalf("INI to JSON failed: %v", err)
+	}
+	expected = `{"name":"app","server":{"port":"8080"}}`
+	if string(jsonData) != expected {
+		t.Errorf("INI to JSON = %s, want %s", jsonData, expected)
+	}
+}
+
+func TestConvertXML(t *testing.T) {
+	input := []byte(`<config><name>app</name><port>8080</port></config>`)
+
+	out, err := Convert(input, AutoFormat, XMLFormat)
+	if err != nil {
+		t.Fatalf("XML to XML failed: %v", err)
+	}
+	if !strings.Contains(string(out), "<config>") {
+		t.Errorf("Root element should be preserved: %s", out)
+	}
+
+	jsonData, err := Convert(input, XMLFormat, JSONFormat)
+	if err != nil {
+		t.Fatalf("XML to JSON failed: %v", err)
+	}
+	if !strings.Contains(string(jsonData), `"name":"app"`) {
+		t.Errorf("Unexpected JSON: %s", jsonData)
+	}
+
+	if _, err := Convert([]byte("just some text"), AutoFormat, JSONFormat); err == nil {
+		t.Error("Convert should fail when format cannot be detected")
+	}
+	if _, err := Convert([]byte("{broken"), JSONFormat, YAMLFormat); err == nil || !strings.Contains(err.Error(), "convert error") {
+		t.Errorf("Expected convert error, got %v", err)
+	}
+}
+
+func TestAutoFormat(t *testing.T) {
+	if AutoFormat.String() != "auto" {
+		t.Errorf("AutoFormat.String() = %q", AutoFormat.String())
+	}
+
+	type Config struct {
+		Name string `json:"name" yaml:"name" toml:"name"`
+		Port int    `json:"port" yaml:"port" toml:"port"`
+	}
+	m := DefaultMarshalExt().SetFormat(AutoFormat)
+	inputs := []string{
+		`{"name":"app","port":8080}`,
+		"name: app\nport: 8080\n",
+		"name = \"app\"\nport = 8080\n",
+	}
+	for _, input := range inputs {
+		var cfg Config
+		if err := m.Unmarshal([]byte(input), &cfg); err != nil {
+			t.Errorf("Unmarshal(%q) failed: %v", input, err)
+			continue
+		}
+		if cfg.Name != "app" || cfg.Port != 8080 {
+			t.Errorf("Unmarshal(%q) = %+v", input, cfg)
+		}
+	}
+}
+
+func TestLoadFileDetect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.conf")
+	if err := os.WriteFile(path, []byte("name: app\nport: 8080\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	var cfg map[string]interface{}
+	if err := LoadFile(path, &cfg); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg["name"] != "app" {
+		t.Errorf("Unexpected config: %v", cfg)
+	}
+}
@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// csvDelimiter 返回 CSV 分隔符，默认逗号
+func (m *MarshalExt) csvDelimiter() rune {
+	if m.options.CSVDelimiter == 0 {
+		return ','
+	}
+	return m.options.CSVDelimiter
+}
+
+// CSV 序列化实现，支持结构体切片、map 切片和 [][]string
+// 结构体表头取 csv 标签（其次 json 标签、字段名），map 表头为所有键排序后的并集
+func (m *MarshalExt) marshalCSV(v interface{}) ([]byte, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return nil, fmt.Errorf("csv marshal error: expected slice, got %T", v)
+	}
+
+	var records [][]string
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	var err error
+	switch {
+	case elemType.Kind() == reflect.Struct:
+		records, err = csvStructRecords(rv, elemType)
+	case elemType.Kind() == reflect.Map:
+		records, err = csvMapRecords(rv)
+	case elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String:
+		for i := 0; i < rv.Len(); i++ {
+			records = append(records, rv.Index(i).Interface().([]string))
+		}
+	case elemType.Kind() == reflect.Interface:
+		records, err = csvMapRecords(rv)
+	default:
+		return nil, fmt.Errorf("csv marshal error: unsupported element type %s", elemType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv marshal error: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = m.csvDelimiter()
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("csv marshal error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvStructRecords 将结构体切片转换为带表头的记录
+func csvStructRecords(rv reflect.Value, elemType reflect.Type) ([][]string, error) {
+	fields := structFields(elemType, "csv")
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+
+	records := [][]string{header}
+	for i := 0; i < rv.Len(); i++ {
+		elem := indirectValue(rv.Index(i))
+		row := make([]string, len(fields))
+		if elem.IsValid() {
+			for j, f := range fields {
+				field, ok := fieldByIndex(elem, f.index, false)
+				if !ok {
+					continue
+				}
+				cell, err := csvCell(field)
+				if err != nil {
+					return nil, fmt.Errorf("row %d field %s: %w", i, f.name, err)
+				}
+				row[j] = cell
+			}
+		}
+		records = append(records, row)
+	}
+	return records, nil
+}
+
+// csvMapRecords 将 map 切片转换为带表头的记录
+func csvMapRecords(rv reflect.Value) ([][]string, error) {
+	keySet := make(map[string]bool)
+	rows := make([]reflect.Value, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		elem := indirectValue(rv.Index(i))
+		if elem.IsValid() && elem.Kind() != reflect.Map {
+			return nil, fmt.Errorf("row %d: expected map, got %s", i, elem.Type())
+		}
+		rows[i] = elem
+		if !elem.IsValid() {
+			continue
+		}
+		for _, k := range elem.MapKeys() {
+			keySet[fmt.Sprint(k.Interface())] = true
+		}
+	}
+
+	header := make([]string, 0, len(keySet))
+	for k := range keySet {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+
+	records := [][]string{header}
+	for i, elem := range rows {
+		row := make([]string, len(header))
+		if elem.IsValid() {
+			values := make(map[string]reflect.Value, elem.Len())
+			for _, k := range elem.MapKeys() {
+				values[fmt.Sprint(k.Interface())] = elem.MapIndex(k)
+			}
+			for j, key := range header {
+				value, ok := values[key]
+				if !ok {
+					continue
+				}
+				cell, err := csvCell(value)
+				if err != nil {
+					return nil, fmt.Errorf("row %d key %s: %w", i, key, err)
+				}
+				row[j] = cell
+			}
+		}
+		records = append(records, row)
+	}
+	return records, nil
+}
+
+// csvCell 格式化单元格，非标量值编码为 JSON
+func csvCell(v reflect.Value) (string, error) {
+	value := indirectValue(v)
+	if !value.IsValid() {
+		return "", nil
+	}
+	if isScalarType(value.Type()) {
+		return formatScalar(value)
+	}
+	data, err := json.Marshal(value.Interface())
+	return string(data), err
+}
+
+// unmarshalCSV 将 CSV 解析到 *[]Struct、*[]map[string]string、*[]map[string]interface{} 或 *[][]string
+func (m *MarshalExt) unmarshalCSV(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("csv unmarshal error: target must be a pointer to slice, got %T", v)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = m.csvDelimiter()
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("csv unmarshal error: %w", err)
+	}
+
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	baseType := elemType
+	for baseType.Kind() == reflect.Ptr {
+		baseType = baseType.Elem()
+	}
+
+	if baseType.Kind() == reflect.Slice && baseType.Elem().Kind() == reflect.String {
+		result := reflect.MakeSlice(slice.Type(), 0, len(records))
+		for _, record := range records {
+			row := reflect.MakeSlice(baseType, len(record), len(record))
+			for j, cell := range record {
+				row.Index(j).SetString(cell)
+			}
+			result = reflect.Append(result, csvAddr(row, elemType))
+		}
+		slice.Set(result)
+		return nil
+	}
+	if baseType.Kind() == reflect.Map && baseType.Key().Kind() != reflect.String {
+		return fmt.Errorf("csv unmarshal error: unsupported map key type %s", baseType.Key())
+	}
+	if len(records) == 0 {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+		return nil
+	}
+
+	header := records[0]
+	result := reflect.MakeSlice(slice.Type(), 0, len(records)-1)
+	for i, record := range records[1:] {
+		elem := reflect.New(baseType).Elem()
+		switch baseType.Kind() {
+		case reflect.Struct:
+			if err := csvDecodeStruct(elem, header, record); err != nil {
+				return fmt.Errorf("csv unmarshal error: row %d: %w", i+1, err)
+			}
+		case reflect.Map:
+			elem.Set(reflect.MakeMapWithSize(baseType, len(header)))
+			for j, key := range header {
+				if j >= len(record) {
+					break
+				}
+				value := reflect.New(baseType.Elem()).Elem()
+				if err := setScalar(value, record[j]); err != nil {
+					return fmt.Errorf("csv unmarshal error: row %d column %s: %w", i+1, key, err)
+				}
+				elem.SetMapIndex(reflect.ValueOf(key).Convert(baseType.Key()), value)
+			}
+		default:
+			return fmt.Errorf("csv unmarshal error: unsupported element type %s", elemType)
+		}
+
+		result = reflect.Append(result, csvAddr(elem, elemType))
+	}
+	slice.Set(result)
+	return nil
+}
+
+// csvAddr 元素类型为指针时逐层取地址
+func csvAddr(elem reflect.Value, elemType reflect.Type) reflect.Value {
+	for t := elemType; t.Kind() == reflect.Ptr; t = t.Elem() {
+		ptr := reflect.New(elem.Type())
+		ptr.Elem().Set(elem)
+		elem = ptr
+	}
+	return elem
+}
+
+// csvDecodeStruct 按表头将一行记录写入结构体，表头大小写不敏感匹配
+func csvDecodeStruct(elem reflect.Value, header, record []string) error {
+	fields := structFields(elem.Type(), "csv")
+	for j, column := range header {
+		if j >= len(record) {
+			break
+		}
+		target := findStructField(fields, column)
+		if target == nil {
+			continue
+		}
+
+		field, _ := fieldByIndex(elem, target.index, true)
+		var err error
+		if isScalarType(field.Type()) {
+			err = setScalar(field, record[j])
+		} else if record[j] != "" {
+			err = json.Unmarshal([]byte(record[j]), field.Addr().Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
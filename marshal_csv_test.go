@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+/*
+CSV 序列化测试
+
+运行命令：
+go test -v -run "^TestCSV.*$"
+
+测试内容：
+1. 结构体切片的表头（csv 标签）及往返一致性
+2. map 切片的表头排序与缺失值
+3. 自定义分隔符与 [][]string
+4. 全局及构建器便捷函数
+*/
+
+type csvTestRow struct {
+	ID        int       `csv:"id"`
+	Name      string    `csv:"name"`
+	Score     float64   `json:"score"`
+	Active    bool      `csv:"active"`
+	CreatedAt time.Time `csv:"created_at"`
+	Tags      []string  `csv:"tags"`
+	Secret    string    `csv:"-"`
+}
+
+func TestCSVStructRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []csvTestRow{
+		{ID: 1, Name: "Alice", Score: 9.5, Active: true, CreatedAt: created, Tags: []string{"a"}, Secret: "x"},
+		{ID: 2, Name: "Bob, Jr.", Score: 7, CreatedAt: created},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(CSVFormat)
+	data, err := marshal.Marshal(rows)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := "id,name,score,active,created_at,tags\n" +
+		"1,Alice,9.5,true,2024-01-02T03:04:05Z,\"[\"\"a\"\"]\"\n" +
+		"2,\"Bob, Jr.\",7,false,2024-01-02T03:04:05Z,null\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", data, expected)
+	}
+
+	var decoded []csvTestRow
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	rows[0].Secret = ""
+	if !reflect.DeepEqual(decoded, rows) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", decoded, rows)
+	}
+
+	var pointers []*csvTestRow
+	if err := marshal.UnmarshalFromString("NAME,id\nCarol,3\n", &pointers); err != nil {
+		t.Fatalf("Unmarshal to pointers failed: %v", err)
+	}
+	if len(pointers) != 1 || pointers[0].Name != "Carol" || pointers[0].ID != 3 {
+		t.Errorf("Unexpected pointer rows: %+v", pointers[0])
+	}
+
+	if err := marshal.UnmarshalFromString("id\nabc\n", &decoded); err == nil {
+		t.Error("Invalid number should fail")
+	}
+}
+
+func TestCSVMaps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"b": 2, "a": "x"},
+		{"c": true},
+	}
+	out, err := DefaultMarshalExt().ToCSV(rows)
+	if err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+	if out != "a,b,c\nx,2,\n,,true\n" {
+		t.Errorf("Unexpected CSV:\n%s", out)
+	}
+
+	var decoded []map[string]string
+	if err := DefaultMarshalExt().SetFormat(CSVFormat).UnmarshalFromString(out, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0]["a"] != "x" || decoded[1]["c"] != "true" {
+		t.Errorf("Unexpected maps: %v", decoded)
+	}
+
+	if _, err := DefaultMarshalExt().ToCSV(map[string]int{"a": 1}); err == nil {
+		t.Error("Non-slice value should fail")
+	}
+}
+
+func TestCSVDelimiter(t *testing.T) {
+	records := [][]string{{"a", "b"}, {"1", "2;3"}}
+	marshal := DefaultMarshalExt().SetFormat(CSVFormat).SetCSVDelimiter(';')
+	data, err := marshal.Marshal(records)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != "a;b\n1;\"2;3\"\n" {
+		t.Errorf("Unexpected CSV: %q", data)
+	}
+
+	var decoded [][]string
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, records) {
+		t.Errorf("Round trip mismatch: %v", decoded)
+	}
+
+	// 元素为指针或自定义字符串类型时逐行构建
+	var pointers []*[]string
+	if err := marshal.Unmarshal(data, &pointers); err != nil {
+		t.Fatalf("Unmarshal into []*[]string failed: %v", err)
+	}
+	if len(pointers) != 2 || !reflect.DeepEqual(*pointers[1], records[1]) {
+		t.Errorf("Unexpected pointer rows: %v", pointers)
+	}
+	type cell string
+	var named [][]cell
+	if err := marshal.Unmarshal(data, &named); err != nil || len(named) != 2 || named[1][1] != "2;3" {
+		t.Errorf("Unmarshal into [][]cell returned %v, %v", named, err)
+	}
+
+	var badKeys []map[int]string
+	if err := marshal.Unmarshal(data, &badKeys); err == nil {
+		t.Error("Non-string map keys should fail")
+	}
+}
+
+func TestCSVHelpers(t *testing.T) {
+	rows := []struct {
+		Name string `csv:"name"`
+	}{{Name: "a"}}
+	expected := "name\na\n"
+
+	if out, err := ToCSV(rows); err != nil || out != expected {
+		t.Errorf("ToCSV returned %q, %v", out, err)
+	}
+	if out := MustToCSV(rows); out != expected {
+		t.Errorf("MustToCSV returned %q", out)
+	}
+	if out := NewMarshalBuilder(rows).MustToCSV(); out != expected {
+		t.Errorf("Builder MustToCSV returned %q", out)
+	}
+}
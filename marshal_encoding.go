@@ -0,0 +1,588 @@
+package utils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TimeEncoding time.Time 的编码方式
+type TimeEncoding int
+
+const (
+	// TimeNative 使用各格式的默认编码（JSON、YAML 为 RFC3339Nano 字符串）
+	TimeNative TimeEncoding = iota
+	// TimeString 按 TimeLayout 格式化为字符串，TimeLayout 为空时使用 time.RFC3339
+	TimeString
+	// TimeUnix Unix 秒数
+	TimeUnix
+	// TimeUnixMilli Unix 毫秒数
+	TimeUnixMilli
+)
+
+// BytesEncoding []byte 的编码方式
+type BytesEncoding int
+
+const (
+	// BytesNative 使用各格式的默认编码（JSON 为标准 base64 字符串，YAML 为整数序列）
+	BytesNative BytesEncoding = iota
+	// BytesBase64 标准 base64 字符串
+	BytesBase64
+	// BytesBase64URL URL 安全的 base64 字符串
+	BytesBase64URL
+	// BytesHex 小写十六进制字符串
+	BytesHex
+)
+
+var yamlMarshalerType = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+
+// encodingEnabled 是否需要改写时间和 []byte 的编码
+func (m *MarshalExt) encodingEnabled() bool {
+	return m.options.TimeEncoding != TimeNative || m.options.TimeLocation != nil || m.options.BytesEncoding != BytesNative
+}
+
+// decodingEnabled 解码时是否需要改写时间和 []byte，只设置 TimeLocation 时原生格式即可解析
+func (m *MarshalExt) decodingEnabled() bool {
+	return m.options.TimeEncoding != TimeNative || m.options.BytesEncoding != BytesNative
+}
+
+// timeLayout 返回 TimeString 使用的布局
+func (m *MarshalExt) timeLayout() string {
+	if m.options.TimeLayout == "" {
+		return time.RFC3339
+	}
+	return m.options.TimeLayout
+}
+
+// formatTime 按选项编码时间，返回字符串或 int64
+func (m *MarshalExt) formatTime(t time.Time) interface{} {
+	if m.options.TimeLocation != nil {
+		t = t.In(m.options.TimeLocation)
+	}
+	switch m.options.TimeEncoding {
+	case TimeString:
+		return t.Format(m.timeLayout())
+	case TimeUnix:
+		return t.Unix()
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTime 按选项解析时间，Unix 时间和不含时区的布局使用 TimeLocation（默认 UTC）
+func (m *MarshalExt) parseTime(s string) (time.Time, error) {
+	loc := m.options.TimeLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch m.options.TimeEncoding {
+	case TimeUnix, TimeUnixMilli:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix time %q", s)
+		}
+		if m.options.TimeEncoding == TimeUnix {
+			return time.Unix(n, 0).In(loc), nil
+		}
+		return time.UnixMilli(n).In(loc), nil
+	case TimeString:
+		return time.ParseInLocation(m.timeLayout(), s, loc)
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// encodeBytes 按选项编码 []byte
+func (m *MarshalExt) encodeBytes(b []byte) string {
+	switch m.options.BytesEncoding {
+	case BytesBase64URL:
+		return base64.URLEncoding.EncodeToString(b)
+	case BytesHex:
+		return hex.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeBytes 按选项解码 []byte，base64 的填充可以省略
+func (m *MarshalExt) decodeBytes(s string) ([]byte, error) {
+	switch m.options.BytesEncoding {
+	case BytesHex:
+		return hex.DecodeString(s)
+	case BytesBase64URL:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	}
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// isBytesType 判断是否为按 BytesEncoding 编码的字节切片，自定义编码的类型除外
+func isBytesType(t reflect.Type, marshaler reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !hasCustomEncoding(t, marshaler)
+}
+
+// hasCustomEncoding 判断类型（或其指针）是否实现了指定接口或 encoding.TextMarshaler/TextUnmarshaler
+func hasCustomEncoding(t reflect.Type, iface reflect.Type) bool {
+	for _, candidate := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if candidate.Implements(iface) || candidate.Implements(textMarshalerType) || candidate.Implements(textUnmarshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// derefValue 去掉指针和接口，遇到 nil 时返回 false
+func derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// encodeJSONWithEncodings 按原生规则编码为紧凑 JSON，再改写其中的时间和 []byte
+func (m *MarshalExt) encodeJSONWithEncodings(v interface{}, escapeHTML bool) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(escapeHTML)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return m.rewriteJSONOutput(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), reflect.ValueOf(v), escapeHTML)
+}
+
+// rewriteJSONOutput 对照编码前的值改写 JSON 中的时间和 []byte，其余内容保持不变
+func (m *MarshalExt) rewriteJSONOutput(raw []byte, v reflect.Value, escapeHTML bool) ([]byte, error) {
+	v, ok := derefValue(v)
+	if !ok {
+		return raw, nil
+	}
+
+	t := v.Type()
+	switch {
+	case t == timeType:
+		return json.Marshal(m.formatTime(v.Interface().(time.Time)))
+	case isBytesType(t, jsonMarshalerType):
+		if v.IsNil() || m.options.BytesEncoding == BytesNative {
+			return raw, nil
+		}
+		return encodeJSONString(m.encodeBytes(v.Bytes()), escapeHTML), nil
+	case hasCustomEncoding(t, jsonMarshalerType):
+		return raw, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := structFields(t, "json")
+		return rewriteJSONObject(raw, escapeHTML, func(key string, value []byte) ([]byte, error) {
+			f := findStructField(fields, key)
+			if f == nil {
+				return value, nil
+			}
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok {
+				return value, nil
+			}
+			return m.rewriteJSONOutput(value, fv, escapeHTML)
+		})
+	case reflect.Map:
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[jsonMapKey(iter.Key())] = iter.Value()
+		}
+		return rewriteJSONObject(raw, escapeHTML, func(key string, value []byte) ([]byte, error) {
+			if item, ok := values[key]; ok {
+				return m.rewriteJSONOutput(value, item, escapeHTML)
+			}
+			return value, nil
+		})
+	case reflect.Slice, reflect.Array:
+		return rewriteJSONArray(raw, func(i int, value []byte) ([]byte, error) {
+			if i >= v.Len() {
+				return value, nil
+			}
+			return m.rewriteJSONOutput(value, v.Index(i), escapeHTML)
+		})
+	}
+	return raw, nil
+}
+
+// jsonMapKey 按 encoding/json 的规则返回 map 键的字符串形式
+func jsonMapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// rewriteJSONInput 按目标类型将 JSON 中按选项编码的时间和 []byte 改写为原生编码
+func (m *MarshalExt) rewriteJSONInput(raw []byte, t reflect.Type) ([]byte, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return raw, nil
+	}
+
+	switch {
+	case t == timeType:
+		if m.options.TimeEncoding == TimeNative {
+			return raw, nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		var s string
+		switch val := value.(type) {
+		case string:
+			s = val
+		case json.Number:
+			s = val.String()
+		default:
+			return nil, fmt.Errorf("cannot decode %s as time", raw)
+		}
+		parsed, err := m.parseTime(s)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(parsed)
+	case isBytesType(t, jsonUnmarshalerType):
+		if m.options.BytesEncoding == BytesNative {
+			return raw, nil
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("cannot decode %s as bytes", raw)
+		}
+		decoded, err := m.decodeBytes(s)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(decoded)
+	case hasCustomEncoding(t, jsonUnmarshalerType):
+		return raw, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := structFields(t, "json")
+		return rewriteJSONObject(raw, false, func(key string, value []byte) ([]byte, error) {
+			if f := findStructField(fields, key); f != nil {
+				return m.rewriteJSONInput(value, t.FieldByIndex(f.index).Type)
+			}
+			return value, nil
+		})
+	case reflect.Map:
+		return rewriteJSONObject(raw, false, func(_ string, value []byte) ([]byte, error) {
+			return m.rewriteJSONInput(value, t.Elem())
+		})
+	case reflect.Slice, reflect.Array:
+		return rewriteJSONArray(raw, func(_ int, value []byte) ([]byte, error) {
+			return m.rewriteJSONInput(value, t.Elem())
+		})
+	}
+	return raw, nil
+}
+
+// rewriteJSONObject 逐个改写 JSON 对象的成员值，保持成员顺序，raw 不是对象时原样返回
+func rewriteJSONObject(raw []byte, escapeHTML bool, rewrite func(key string, value []byte) ([]byte, error)) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		rewritten, err := rewrite(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(encodeJSONString(key, escapeHTML))
+		buf.WriteByte(':')
+		buf.Write(rewritten)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// rewriteJSONArray 逐个改写 JSON 数组的元素，raw 不是数组时原样返回
+func rewriteJSONArray(raw []byte, rewrite func(i int, value []byte) ([]byte, error)) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('[') {
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; decoder.More(); i++ {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		rewritten, err := rewrite(i, value)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(rewritten)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// yamlFieldIndexes 按 yaml.v3 的键名规则收集结构体字段的索引，inline 字段被展开
+func yamlFieldIndexes(t reflect.Type, prefix []int, fields map[string][]int) map[string][]int {
+	if fields == nil {
+		fields = make(map[string][]int)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		index := append(append([]int{}, prefix...), i)
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			if ft := f.Type; ft.Kind() == reflect.Struct || (ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				yamlFieldIndexes(ft, index, fields)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = index
+	}
+	return fields
+}
+
+// rewriteYAMLOutput 对照编码前的值改写节点中的时间和 []byte
+func (m *MarshalExt) rewriteYAMLOutput(node *yaml.Node, v reflect.Value) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			m.rewriteYAMLOutput(child, v)
+		}
+		return
+	}
+	v, ok := derefValue(v)
+	if !ok || node.Kind == yaml.AliasNode {
+		return
+	}
+
+	t := v.Type()
+	switch {
+	case t == timeType:
+		if node.Kind != yaml.ScalarNode {
+			return
+		}
+		switch value := m.formatTime(v.Interface().(time.Time)).(type) {
+		case string:
+			node.Tag, node.Value = "!!str", value
+		case int64:
+			node.Tag, node.Value = "!!int", strconv.FormatInt(value, 10)
+		}
+		node.Style = 0
+		return
+	case isBytesType(t, yamlMarshalerType):
+		if v.IsNil() || m.options.BytesEncoding == BytesNative {
+			return
+		}
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.encodeBytes(v.Bytes())}
+		return
+	case hasCustomEncoding(t, yamlMarshalerType):
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFieldIndexes(t, nil, nil)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if index, ok := fields[node.Content[i].Value]; ok {
+				if fv, ok := fieldByIndex(v, index, false); ok {
+					m.rewriteYAMLOutput(node.Content[i+1], fv)
+				}
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprint(iter.Key().Interface())] = iter.Value()
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if item, ok := values[node.Content[i].Value]; ok {
+				m.rewriteYAMLOutput(node.Content[i+1], item)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, child := range node.Content {
+			if i < v.Len() {
+				m.rewriteYAMLOutput(child, v.Index(i))
+			}
+		}
+	}
+}
+
+// rewriteYAMLInput 逐个文档按目标类型将 YAML 中按选项编码的时间和 []byte 改写为原生编码
+func (m *MarshalExt) rewriteYAMLInput(data []byte, target interface{}) ([]byte, error) {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return data, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := m.rewriteYAMLNode(&node, t); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rewriteYAMLNode 按目标类型改写节点中的时间和 []byte
+func (m *MarshalExt) rewriteYAMLNode(node *yaml.Node, t reflect.Type) error {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			if err := m.rewriteYAMLNode(child, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode || (node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null") {
+		return nil
+	}
+
+	switch {
+	case t == timeType:
+		if m.options.TimeEncoding == TimeNative || node.Kind != yaml.ScalarNode {
+			return nil
+		}
+		parsed, err := m.parseTime(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Tag, node.Value, node.Style = "!!timestamp", parsed.Format(time.RFC3339Nano), 0
+		return nil
+	case isBytesType(t, yamlUnmarshalerType):
+		if m.options.BytesEncoding == BytesNative || node.Kind != yaml.ScalarNode {
+			return nil
+		}
+		decoded, err := m.decodeBytes(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		content := make([]*yaml.Node, len(decoded))
+		for i, b := range decoded {
+			content[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(int(b))}
+		}
+		*node = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: content, Line: node.Line, Column: node.Column}
+		return nil
+	case hasCustomEncoding(t, yamlUnmarshalerType):
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := yamlFieldIndexes(t, nil, nil)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if index, ok := fields[node.Content[i].Value]; ok {
+				if err := m.rewriteYAMLNode(node.Content[i+1], t.FieldByIndex(index).Type); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := m.rewriteYAMLNode(node.Content[i], t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, child := range node.Content {
+			if err := m.rewriteYAMLNode(child, t.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+时间和 []byte 编码方式测试
+
+运行命令：
+go test -v -run "^Test(TimeEncoding|BytesEncoding|EncodingYAML|EncodingOptions).*$"
+
+测试内容：
+1. 时间按布局、Unix 秒、Unix 毫秒编码和解析，时区转换
+2. []byte 按标准 base64、URL base64、十六进制编码和解析
+3. YAML 的时间和 []byte 编码
+4. 与排序、键名风格、美化、结构化截断组合，自定义编码的类型保持不变
+*/
+
+type encodingEvent struct {
+	Name    string            `json:"name" yaml:"name"`
+	At      time.Time         `json:"at" yaml:"at"`
+	Expires *time.Time        `json:"expires,omitempty" yaml:"expires,omitempty"`
+	Payload []byte            `json:"payload" yaml:"payload"`
+	History []time.Time       `json:"history,omitempty" yaml:"history,omitempty"`
+	Extra   map[string][]byte `json:"extra,omitempty" yaml:"extra,omitempty"`
+}
+
+// encodingTime 测试使用的固定时间
+var encodingTime = time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+func TestTimeEncoding(t *testing.T) {
+	event := encodingEvent{Name: "deploy", At: encodingTime, History: []time.Time{encodingTime.Add(-time.Hour)}}
+
+	cases := []struct {
+		name     string
+		marshal  *MarshalExt
+		expected string
+	}{
+		{"native", DefaultMarshalExt(), `{"name":"deploy","at":"2024-05-01T08:30:00Z","payload":null,"history":["2024-05-01T07:30:00Z"]}`},
+		{"layout", DefaultMarshalExt().SetTimeLayout("2006-01-02 15:04:05"), `{"name":"deploy","at":"2024-05-01 08:30:00","payload":null,"history":["2024-05-01 07:30:00"]}`},
+		{"unix", DefaultMarshalExt().SetTimeEncoding(TimeUnix), `{"name":"deploy","at":1714552200,"payload":null,"history":[1714548600]}`},
+		{"unix milli", DefaultMarshalExt().SetTimeEncoding(TimeUnixMilli), `{"name":"deploy","at":1714552200000,"payload":null,"history":[1714548600000]}`},
+		{"location", DefaultMarshalExt().SetTimeLocation(time.FixedZone("CST", 8*3600)), `{"name":"deploy","at":"2024-05-01T16:30:00+08:00","payload":null,"history":["2024-05-01T15:30:00+08:00"]}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.marshal.MarshalToString(event)
+			if err != nil || out != tc.expected {
+				t.Fatalf("Marshal = %s, %v\nwant %s", out, err, tc.expected)
+			}
+			var decoded encodingEvent
+			if err := tc.marshal.UnmarshalFromString(out, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if !decoded.At.Equal(event.At) || len(decoded.History) != 1 || !decoded.History[0].Equal(event.History[0]) {
+				t.Errorf("Round trip = %+v", decoded)
+			}
+		})
+	}
+
+	// 不含时区的布局按 TimeLocation 解析
+	cst := time.FixedZone("CST", 8*3600)
+	local := DefaultMarshalExt().SetTimeLayout("2006-01-02 15:04").SetTimeLocation(cst)
+	var decoded encodingEvent
+	if err := local.UnmarshalFromString(`{"at":"2024-05-01 16:30","expires":null}`, &decoded); err != nil || !decoded.At.Equal(encodingTime) || decoded.Expires != nil {
+		t.Errorf("Layout in location = %+v, %v", decoded, err)
+	}
+
+	// Unix 时间也接受数字字符串，指针字段正常分配
+	unix := DefaultMarshalExt().SetTimeEncoding(TimeUnix)
+	if err := unix.UnmarshalFromString(`{"at":"1714552200","expires":1714552260}`, &decoded); err != nil || !decoded.At.Equal(encodingTime) ||
+		decoded.Expires == nil || decoded.Expires.Sub(encodingTime) != time.Minute {
+		t.Errorf("Unix decode = %+v, %v", decoded, err)
+	}
+
+	for _, input := range []string{`{"at":"yesterday"}`, `{"at":true}`, `{"history":[1, "x"]}`} {
+		if err := unix.UnmarshalFromString(input, &encodingEvent{}); err == nil {
+			t.Errorf("Unmarshal(%s) should fail", input)
+		}
+	}
+	if err := local.UnmarshalFromString(`{"at":"2024-05-01T16:30:00Z"}`, &encodingEvent{}); err == nil {
+		t.Error("Time not matching the layout should fail")
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	event := encodingEvent{Name: "blob", At: encodingTime, Payload: []byte{0xfb, 0xff, 0x01}, Extra: map[string][]byte{"sig": {0x3e}}}
+
+	cases := []struct {
+		encoding BytesEncoding
+		payload  string
+		sig      string
+	}{
+		{BytesNative, "+/8B", "Pg=="},
+		{BytesBase64, "+/8B", "Pg=="},
+		{BytesBase64URL, "-_8B", "Pg=="},
+		{BytesHex, "fbff01", "3e"},
+	}
+	for _, tc := range cases {
+		ext := DefaultMarshalExt().SetBytesEncoding(tc.encoding)
+		out, err := ext.MarshalToString(event)
+		expected := `{"name":"blob","at":"2024-05-01T08:30:00Z","payload":"` + tc.payload + `","extra":{"sig":"` + tc.sig + `"}}`
+		if err != nil || out != expected {
+			t.Errorf("Encoding %d = %s, %v\nwant %s", tc.encoding, out, err, expected)
+			continue
+		}
+		var decoded encodingEvent
+		if err := ext.UnmarshalFromString(out, &decoded); err != nil || string(decoded.Payload) != string(event.Payload) || decoded.Extra["sig"][0] != 0x3e {
+			t.Errorf("Encoding %d round trip = %+v, %v", tc.encoding, decoded, err)
+		}
+	}
+
+	// base64 的填充可以省略
+	var decoded encodingEvent
+	if err := DefaultMarshalExt().SetBytesEncoding(BytesBase64URL).UnmarshalFromString(`{"payload":"Pg"}`, &decoded); err != nil || string(decoded.Payload) != ">" {
+		t.Errorf("Unpadded base64 = %q, %v", decoded.Payload, err)
+	}
+	if err := DefaultMarshalExt().SetBytesEncoding(BytesHex).UnmarshalFromString(`{"payload":"xyz"}`, &decoded); err == nil {
+		t.Error("Invalid hex should fail")
+	}
+
+	// json.RawMessage 等自定义编码的类型保持不变
+	raw := struct {
+		Raw  json.RawMessage `json:"raw"`
+		Data []byte          `json:"data"`
+	}{Raw: json.RawMessage(`{"a":1}`), Data: []byte("hi")}
+	if out, err := DefaultMarshalExt().SetBytesEncoding(BytesHex).MarshalToString(raw); err != nil || out != `{"raw":{"a":1},"data":"6869"}` {
+		t.Errorf("RawMessage = %s, %v", out, err)
+	}
+}
+
+func TestEncodingYAML(t *testing.T) {
+	expires := encodingTime.Add(time.Hour)
+	event := encodingEvent{Name: "deploy", At: encodingTime, Expires: &expires, Payload: []byte("ok")}
+
+	ext := DefaultMarshalExt().SetFormat(YAMLFormat).SetTimeLayout("2006-01-02 15:04").SetBytesEncoding(BytesHex)
+	out, err := ext.MarshalToString(event)
+	expected := "name: deploy\nat: 2024-05-01 08:30\nexpires: 2024-05-01 09:30\npayload: 6f6b\n"
+	if err != nil || out != expected {
+		t.Fatalf("YAML = %q, %v\nwant %q", out, err, expected)
+	}
+	var decoded encodingEvent
+	if err := ext.UnmarshalFromString(out, &decoded); err != nil || !decoded.At.Equal(encodingTime) || !decoded.Expires.Equal(expires) || string(decoded.Payload) != "ok" {
+		t.Errorf("YAML round trip = %+v, %v", decoded, err)
+	}
+
+	// 看起来像其他类型的字符串会加引号
+	unix := DefaultMarshalExt().SetFormat(YAMLFormat).SetTimeEncoding(TimeUnix).SetBytesEncoding(BytesHex)
+	out, _ = unix.MarshalToString(encodingEvent{At: encodingTime, Payload: []byte{0x12}})
+	if !strings.Contains(out, "at: 1714552200\n") || !strings.Contains(out, `payload: "12"`) {
+		t.Errorf("YAML unix = %q", out)
+	}
+	if err := unix.UnmarshalFromString("at: soon\n", &decoded); err == nil {
+		t.Error("Invalid YAML time should fail")
+	}
+}
+
+func TestEncodingOptions(t *testing.T) {
+	type inner struct {
+		Seen time.Time `json:"seen"`
+	}
+	type wrapper struct {
+		inner
+		UserName string          `json:"userName"`
+		Created  time.Time       `json:"created"`
+		Any      interface{}     `json:"any"`
+		Custom   json.RawMessage `json:"custom"`
+	}
+	value := wrapper{inner: inner{Seen: encodingTime}, UserName: "<a>", Created: encodingTime, Any: encodingTime, Custom: json.RawMessage(`"x"`)}
+
+	ext := DefaultMarshalExt().SetTimeEncoding(TimeUnix).SetKeyNaming(SnakeCaseKeys)
+	if out, err := ext.MarshalToString(value); err != nil || out != `{"seen":1714552200,"user_name":"\u003ca\u003e","created":1714552200,"any":1714552200,"custom":"x"}` {
+		t.Errorf("Combined = %s, %v", out, err)
+	}
+	if out, err := ext.Clone().SetSortKeys(true).SetEscapeHTML(false).MarshalToString(value); err != nil || out != `{"any":1714552200,"created":1714552200,"custom":"x","seen":1714552200,"user_name":"<a>"}` {
+		t.Errorf("Sorted = %s, %v", out, err)
+	}
+	if out, err := ext.Clone().SetPretty(true).MarshalToString(inner{Seen: encodingTime}); err != nil || out != "{\n  \"seen\": 1714552200\n}" {
+		t.Errorf("Pretty = %q, %v", out, err)
+	}
+
+	var decoded wrapper
+	if err := ext.UnmarshalFromString(`{"seen":1714552200,"user_name":"a","created":1714552200,"any":1}`, &decoded); err != nil || !decoded.Seen.Equal(encodingTime) || !decoded.Created.Equal(encodingTime) {
+		t.Errorf("Decoded = %+v, %v", decoded, err)
+	}
+
+	// 结构化截断保持编码方式
+	long := map[string]interface{}{"at": encodingTime, "text": strings.Repeat("x", 500)}
+	truncated, err := DefaultMarshalExt().SetTimeEncoding(TimeUnix).SetMaxLength(120).SetTruncate(true).SetTruncateMode(TruncateStructure).MarshalToString(long)
+	if err != nil || !strings.Contains(truncated, `"at":1714552200`) {
+		t.Errorf("Truncated = %s, %v", truncated, err)
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// 泛型解码、转换和深拷贝，新代码优先使用这些函数代替 TypeConverter 和先声明变量再传指针的写法
+
+// UnmarshalAs 按指定格式解码为类型 T
+func UnmarshalAs[T any](data []byte, format MarshalFormat) (T, error) {
+	return UnmarshalWith[T](DefaultMarshal.Clone().SetFormat(format), data)
+}
+
+// UnmarshalWith 使用指定序列化器解码为类型 T，可配合严格模式、Schema 等选项
+func UnmarshalWith[T any](m *MarshalExt, data []byte) (T, error) {
+	var result T
+	if err := m.Unmarshal(data, &result); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// Decode 从 Reader 读取全部数据并按指定格式解码为类型 T
+func Decode[T any](r io.Reader, format MarshalFormat) (T, error) {
+	return DecodeWith[T](DefaultMarshal.Clone().SetFormat(format), r)
+}
+
+// DecodeWith 使用指定序列化器从 Reader 解码为类型 T，设置了 MaxSize 时限制读取大小
+func DecodeWith[T any](m *MarshalExt, r io.Reader) (T, error) {
+	var result T
+	if err := m.UnmarshalFromReader(r, &result); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// ConvertTo 将值转换为类型 T：类型相同时直接返回，否则经 JSON 转换（如 map 转结构体、结构体转 map）
+func ConvertTo[T any](v interface{}) (T, error) {
+	if result, ok := v.(T); ok {
+		return result, nil
+	}
+	var result T
+	data, err := DefaultMarshalExt().Marshal(v)
+	if err != nil {
+		return result, fmt.Errorf("convert error: %w", err)
+	}
+	if err := DefaultMarshalExt().Unmarshal(data, &result); err != nil {
+		var zero T
+		return zero, fmt.Errorf("convert error: %w", err)
+	}
+	return result, nil
+}
+
+// DeepCopy 深拷贝值
+//
+// 类型中没有通道、函数，且未导出字段不含指针、切片、map 等引用时直接按反射复制，保留全部字段（包括未导出字段）
+// 和循环引用；否则经 JSON 编解码复制，此时只保留可编码的导出字段。接口中的动态值按反射复制
+func DeepCopy[T any](v T) (T, error) {
+	rv := reflect.ValueOf(&v).Elem()
+	if reflectCopyable(rv.Type()) {
+		return deepCopyValue(rv, map[copyKey]reflect.Value{}).Interface().(T), nil
+	}
+
+	var result T
+	data, err := DefaultMarshalExt().Marshal(v)
+	if err != nil {
+		return result, fmt.Errorf("deep copy error: %w", err)
+	}
+	if err := DefaultMarshalExt().Unmarshal(data, &result); err != nil {
+		var zero T
+		return zero, fmt.Errorf("deep copy error: %w", err)
+	}
+	return result, nil
+}
+
+// MustDeepCopy 深拷贝值，出错时 panic
+func MustDeepCopy[T any](v T) T {
+	result, err := DeepCopy(v)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// copyableTypes 缓存类型是否可以按反射深拷贝
+var copyableTypes sync.Map
+
+// reflectCopyable 判断类型是否可以按反射深拷贝
+func reflectCopyable(t reflect.Type) bool {
+	if cached, ok := copyableTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	copyable := isCopyableType(t, map[reflect.Type]bool{})
+	copyableTypes.Store(t, copyable)
+	return copyable
+}
+
+// isCopyableType 递归检查类型，visiting 用于处理递归类型
+func isCopyableType(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == timeType || visiting[t] {
+		return true
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isCopyableType(t.Elem(), visiting)
+	case reflect.Map:
+		return isCopyableType(t.Key(), visiting) && isCopyableType(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && hasReferences(f.Type, map[reflect.Type]bool{}) {
+				return false
+			}
+			if !isCopyableType(f.Type, visiting) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasReferences 判断类型的值是否包含引用（按值复制后会与原值共享数据）
+func hasReferences(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == timeType || visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return hasReferences(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasReferences(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+泛型解码、转换与深拷贝测试
+
+运行命令：
+go test -v -run "^Test(UnmarshalAs|DecodeGeneric|ConvertTo|DeepCopy).*$"
+
+测试内容：
+1. UnmarshalAs、UnmarshalWith 按格式和选项解码为具体类型
+2. Decode、DecodeWith 从 Reader 解码
+3. ConvertTo 在 map 和结构体之间转换
+4. DeepCopy 反射复制：嵌套引用、未导出字段、循环引用、time.Time
+5. DeepCopy 对包含函数等不可反射复制的类型回退到 JSON
+*/
+
+type genericUser struct {
+	Name  string            `json:"name" yaml:"name"`
+	Age   int               `json:"age" yaml:"age"`
+	Tags  []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+}
+
+func TestUnmarshalAs(t *testing.T) {
+	user, err := UnmarshalAs[genericUser]([]byte(`{"name":"alice","age":30}`), JSONFormat)
+	if err != nil || user.Name != "alice" || user.Age != 30 {
+		t.Errorf("UnmarshalAs JSON = %+v, %v", user, err)
+	}
+
+	users, err := UnmarshalAs[[]genericUser]([]byte("- name: bob\n  age: 20\n- name: carol\n"), YAMLFormat)
+	if err != nil || len(users) != 2 || users[1].Name != "carol" {
+		t.Errorf("UnmarshalAs YAML = %+v, %v", users, err)
+	}
+
+	ptr, err := UnmarshalAs[*genericUser]([]byte(`{"name":"dave"}`), JSONFormat)
+	if err != nil || ptr == nil || ptr.Name != "dave" {
+		t.Errorf("UnmarshalAs pointer = %+v, %v", ptr, err)
+	}
+
+	if n, err := UnmarshalAs[int]([]byte(`"x"`), JSONFormat); err == nil || n != 0 {
+		t.Errorf("UnmarshalAs mismatch = %d, %v", n, err)
+	}
+
+	strict := DefaultMarshalExt().SetStrict(true)
+	if _, err := UnmarshalWith[genericUser](strict, []byte(`{"name":"a","extra":1}`)); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("UnmarshalWith strict error = %v", err)
+	}
+	partial, err := UnmarshalWith[genericUser](DefaultMarshalExt(), []byte(`{"name":"a","age":"x"}`))
+	if err == nil || !reflect.DeepEqual(partial, genericUser{}) {
+		t.Errorf("Failed decode should return zero value: %+v, %v", partial, err)
+	}
+}
+
+func TestDecodeGeneric(t *testing.T) {
+	user, err := Decode[genericUser](strings.NewReader(`{"name":"alice","tags":["a"]}`), JSONFormat)
+	if err != nil || user.Name != "alice" || len(user.Tags) != 1 {
+		t.Errorf("Decode = %+v, %v", user, err)
+	}
+
+	values, err := Decode[map[string]interface{}](strings.NewReader("name = \"svc\"\nport = 8080\n"), TOMLFormat)
+	if err != nil || values["name"] != "svc" {
+		t.Errorf("Decode TOML = %v, %v", values, err)
+	}
+
+	limited := DefaultMarshalExt().SetMaxSize(8)
+	if _, err := DecodeWith[genericUser](limited, strings.NewReader(`{"name":"too long"}`)); !errors.Is(err, ErrMaxSizeExceeded) {
+		t.Errorf("DecodeWith error = %v, want ErrMaxSizeExceeded", err)
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	user, err := ConvertTo[genericUser](map[string]interface{}{"name": "alice", "age": 30, "attrs": map[string]interface{}{"k": "v"}})
+	if err != nil || user.Name != "alice" || user.Age != 30 || user.Attrs["k"] != "v" {
+		t.Errorf("ConvertTo struct = %+v, %v", user, err)
+	}
+
+	fields, err := ConvertTo[map[string]interface{}](genericUser{Name: "bob", Age: 20})
+	if err != nil || fields["name"] != "bob" || fields["age"] != float64(20) {
+		t.Errorf("ConvertTo map = %v, %v", fields, err)
+	}
+
+	same := &genericUser{Name: "same"}
+	if result, err := ConvertTo[*genericUser](same); err != nil || result != same {
+		t.Errorf("ConvertTo same type should return input: %p %p %v", result, same, err)
+	}
+	if _, err := ConvertTo[int]("abc"); err == nil {
+		t.Error("ConvertTo incompatible type should fail")
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	type node struct {
+		Name     string
+		Children []*node
+		Parent   *node
+		Meta     map[string]interface{}
+		Created  time.Time
+		count    int
+	}
+
+	root := &node{Name: "root", Meta: map[string]interface{}{"list": []interface{}{1, "a"}}, Created: time.Now(), count: 3}
+	child := &node{Name: "child", Parent: root}
+	root.Children = []*node{child, child}
+
+	copied, err := DeepCopy(root)
+	if err != nil {
+		t.Fatalf("DeepCopy failed: %v", err)
+	}
+	if copied == root || copied.Children[0] == child || copied.count != 3 || !copied.Created.Equal(root.Created) {
+		t.Errorf("Unexpected copy: %+v", copied)
+	}
+	if copied.Children[0] != copied.Children[1] || copied.Children[0].Parent != copied {
+		t.Error("Shared and circular pointers should be preserved in the copy")
+	}
+
+	copied.Meta["list"].([]interface{})[0] = 2
+	copied.Children[0].Name = "changed"
+	if root.Meta["list"].([]interface{})[0] != 1 || child.Name != "child" {
+		t.Error("Modifying the copy should not affect the original")
+	}
+
+	users := []genericUser{{Name: "a", Tags: []string{"x"}, Attrs: map[string]string{"k": "v"}}}
+	copiedUsers := MustDeepCopy(users)
+	copiedUsers[0].Tags[0] = "y"
+	copiedUsers[0].Attrs["k"] = "w"
+	if users[0].Tags[0] != "x" || users[0].Attrs["k"] != "v" {
+		t.Errorf("Nested slices and maps should be copied: %+v", users)
+	}
+
+	var nilMap map[string]int
+	if result, err := DeepCopy(nilMap); err != nil || result != nil {
+		t.Errorf("DeepCopy nil map = %v, %v", result, err)
+	}
+}
+
+func TestDeepCopyFallback(t *testing.T) {
+	type hooks struct {
+		Name     string        `json:"name"`
+		Callback func() string `json:"-"`
+	}
+	type wrapper struct {
+		Value int `json:"value"`
+		cache []int
+	}
+
+	if reflectCopyable(reflect.TypeOf(hooks{})) || reflectCopyable(reflect.TypeOf(wrapper{})) {
+		t.Error("Types with functions or unexported references should not use the reflection path")
+	}
+	if !reflectCopyable(reflect.TypeOf(genericUser{})) || !reflectCopyable(reflect.TypeOf(time.Time{})) {
+		t.Error("Plain types should use the reflection path")
+	}
+
+	copied, err := DeepCopy(hooks{Name: "h", Callback: func() string { return "x" }})
+	if err != nil || copied.Name != "h" || copied.Callback != nil {
+		t.Errorf("JSON fallback = %+v, %v", copied, err)
+	}
+
+	w, err := DeepCopy(wrapper{Value: 1, cache: []int{1}})
+	if err != nil || w.Value != 1 || w.cache != nil {
+		t.Errorf("JSON fallback should drop unexported fields: %+v, %v", w, err)
+	}
+
+	if _, err := DeepCopy(struct{ C chan int }{C: make(chan int)}); err == nil {
+		t.Error("Uncopyable value should fail")
+	}
+}
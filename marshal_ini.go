@@ -0,0 +1,352 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// iniSection INI 节，name 为空表示全局键
+type iniSection struct {
+	name string
+	keys [][2]string
+}
+
+// INI 序列化实现：结构体/map 的标量字段为键，嵌套结构体/map 为节，多级嵌套使用点分隔节名
+// 键名取 ini 标签（其次 json 标签、字段名），标量切片以逗号连接
+func (m *MarshalExt) marshalINI(v interface{}) ([]byte, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	if !rv.IsValid() || (rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map) {
+		return nil, fmt.Errorf("ini marshal error: expected struct or map, got %T", v)
+	}
+
+	var sections []*iniSection
+	if err := iniCollect(rv, "", &sections); err != nil {
+		return nil, fmt.Errorf("ini marshal error: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, section := range sections {
+		if section.name == "" && len(section.keys) == 0 {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		if section.name != "" {
+			fmt.Fprintf(&buf, "[%s]\n", section.name)
+		}
+		for _, kv := range section.keys {
+			fmt.Fprintf(&buf, "%s = %s\n", kv[0], iniQuote(kv[1]))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// iniCollect 收集节和键，当前节总是第一个被追加，保证全局键位于最前
+func iniCollect(rv reflect.Value, name string, sections *[]*iniSection) error {
+	section := &iniSection{name: name}
+	*sections = append(*sections, section)
+
+	type entry struct {
+		key   string
+		value reflect.Value
+		omit  bool
+	}
+	var entries []entry
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(rv.Type(), "ini") {
+			field, ok := fieldByIndex(rv, f.index, false)
+			if !ok {
+				continue
+			}
+			entries = append(entries, entry{f.name, field, f.omitEmpty})
+		}
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			entries = append(entries, entry{fmt.Sprint(k.Interface()), rv.MapIndex(k), false})
+		}
+	}
+
+	var nested []entry
+	for _, e := range entries {
+		value := indirectValue(e.value)
+		if e.omit && (!value.IsValid() || value.IsZero()) {
+			continue
+		}
+		if !value.IsValid() {
+			section.keys = append(section.keys, [2]string{e.key, ""})
+			continue
+		}
+		if (value.Kind() == reflect.Struct || value.Kind() == reflect.Map) && !isScalarType(value.Type()) {
+			nested = append(nested, entry{e.key, value, false})
+			continue
+		}
+		text, err := iniFormat(value)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", e.key, err)
+		}
+		section.keys = append(section.keys, [2]string{e.key, text})
+	}
+
+	for _, e := range nested {
+		child := e.key
+		if name != "" {
+			child = name + "." + e.key
+		}
+		if err := iniCollect(e.value, child, sections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iniFormat 格式化键值，标量切片以逗号连接
+func iniFormat(v reflect.Value) (string, error) {
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !isScalarType(v.Type()) {
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			part, err := formatScalar(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return formatScalar(v)
+}
+
+// iniQuote 值包含首尾空白或注释符时加引号
+func iniQuote(s string) string {
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, ";#\"\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// parseINI 解析 INI 文本为按出现顺序排列的节
+func parseINI(data []byte) ([]*iniSection, error) {
+	sections := []*iniSection{{name: ""}}
+	current := sections[0]
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %q", lineNo, line)
+			}
+			current = &iniSection{name: strings.TrimSpace(line[1 : len(line)-1])}
+			sections = append(sections, current)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value, got %q", lineNo, line)
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			value = unquoted
+		}
+		current.keys = append(current.keys, [2]string{strings.TrimSpace(key), value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// unmarshalINI 将 INI 解析到结构体指针、*map[string]interface{} 或 *map[string]map[string]string
+// 点分隔的节名对应嵌套结构体/map 字段，*map[string]map[string]string 中全局键位于 "" 节
+func (m *MarshalExt) unmarshalINI(data []byte, v interface{}) error {
+	sections, err := parseINI(data)
+	if err != nil {
+		return fmt.Errorf("ini unmarshal error: %w", err)
+	}
+
+	switch target := v.(type) {
+	case *map[string]map[string]string:
+		result := make(map[string]map[string]string)
+		for _, section := range sections {
+			if len(section.keys) == 0 && section.name == "" {
+				continue
+			}
+			values := result[section.name]
+			if values == nil {
+				values = make(map[string]string)
+				result[section.name] = values
+			}
+			for _, kv := range section.keys {
+				values[kv[0]] = kv[1]
+			}
+		}
+		*target = result
+		return nil
+	case *map[string]interface{}:
+		result := make(map[string]interface{})
+		for _, section := range sections {
+			node := result
+			if section.name != "" {
+				for _, part := range strings.Split(section.name, ".") {
+					child, ok := node[part].(map[string]interface{})
+					if !ok {
+						child = make(map[string]interface{})
+						node[part] = child
+					}
+					node = child
+				}
+			}
+			for _, kv := range section.keys {
+				node[kv[0]] = kv[1]
+			}
+		}
+		*target = result
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ini unmarshal error: unsupported target %T", v)
+	}
+
+	for _, section := range sections {
+		var path []string
+		if section.name != "" {
+			path = strings.Split(section.name, ".")
+		}
+		if err := iniAssign(rv.Elem(), path, section.keys); err != nil {
+			return fmt.Errorf("ini unmarshal error: [%s] %w", section.name, err)
+		}
+	}
+	return nil
+}
+
+// iniAssign 按节路径找到嵌套的结构体或 map 并写入键值，必要时分配指针和 map，无法对应的节被忽略
+func iniAssign(rv reflect.Value, path []string, keys [][2]string) error {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Map {
+			rv.Set(reflect.ValueOf(make(map[string]interface{})))
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if len(path) == 0 {
+			for _, kv := range keys {
+				if err := iniSetField(rv, kv[0], kv[1]); err != nil {
+					return fmt.Errorf("%s: %w", kv[0], err)
+				}
+			}
+			return nil
+		}
+		field, ok := iniFindField(rv, path[0])
+		if !ok {
+			return nil
+		}
+		return iniAssign(field, path[1:], keys)
+	case reflect.Map:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		if len(path) == 0 {
+			for _, kv := range keys {
+				if err := iniSetMapEntry(rv, kv[0], func(elem reflect.Value) error {
+					return iniSetValue(elem, kv[1])
+				}); err != nil {
+					return fmt.Errorf("%s: %w", kv[0], err)
+				}
+			}
+			return nil
+		}
+		return iniSetMapEntry(rv, path[0], func(elem reflect.Value) error {
+			return iniAssign(elem, path[1:], keys)
+		})
+	}
+	return nil
+}
+
+// iniSetMapEntry 复制 map 中已有的元素，经 set 修改后写回
+func iniSetMapEntry(rv reflect.Value, key string, set func(elem reflect.Value) error) error {
+	k := reflect.New(rv.Type().Key()).Elem()
+	if err := setScalar(k, key); err != nil {
+		return err
+	}
+	elem := reflect.New(rv.Type().Elem()).Elem()
+	if existing := rv.MapIndex(k); existing.IsValid() {
+		elem.Set(existing)
+	}
+	if err := set(elem); err != nil {
+		return err
+	}
+	rv.SetMapIndex(k, elem)
+	return nil
+}
+
+// iniFindField 按键名查找结构体字段，大小写不敏感
+func iniFindField(rv reflect.Value, key string) (reflect.Value, bool) {
+	f := findStructField(structFields(rv.Type(), "ini"), key)
+	if f == nil {
+		return reflect.Value{}, false
+	}
+	return fieldByIndex(rv, f.index, true)
+}
+
+// iniSetField 设置字段值，未知键被忽略
+func iniSetField(rv reflect.Value, key, value string) error {
+	field, ok := iniFindField(rv, key)
+	if !ok {
+		return nil
+	}
+	return iniSetValue(field, value)
+}
+
+// iniSetValue 设置键值，标量切片按逗号拆分，空接口保存原始字符串
+func iniSetValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Interface && field.NumMethod() == 0 {
+		field.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if field.Kind() == reflect.Slice && !isScalarType(field.Type()) {
+		var parts []string
+		if value != "" {
+			parts = strings.Split(value, ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setScalar(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalar(field, value)
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+INI 序列化测试
+
+运行命令：
+go test -v -run "^TestINI.*$"
+
+测试内容：
+1. 嵌套结构体、map 字段对应节及往返一致性
+2. 注释、引号与未知键
+3. 解析到 map
+4. 全局及构建器便捷函数
+*/
+
+type iniTestConfig struct {
+	Name     string `ini:"name"`
+	Debug    bool   `ini:"debug"`
+	Database struct {
+		Host string   `ini:"host"`
+		Port int      `ini:"port"`
+		Tags []string `ini:"tags"`
+		Pool *struct {
+			Size int `ini:"size"`
+		} `ini:"pool"`
+	} `ini:"database"`
+	Note string `ini:"note,omitempty"`
+}
+
+func TestINIRoundTrip(t *testing.T) {
+	var config iniTestConfig
+	config.Name = "app"
+	config.Debug = true
+	config.Database.Host = "localhost"
+	config.Database.Port = 5432
+	config.Database.Tags = []string{"a", "b"}
+	config.Database.Pool = &struct {
+		Size int `ini:"size"`
+	}{Size: 10}
+
+	marshal := DefaultMarshalExt().SetFormat(INIFormat)
+	data, err := marshal.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := "name = app\ndebug = true\n\n" +
+		"[database]\nhost = localhost\nport = 5432\ntags = a,b\n\n" +
+		"[database.pool]\nsize = 10\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected INI:\n%s\nwant:\n%s", data, expected)
+	}
+
+	var decoded iniTestConfig
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, config) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", decoded, config)
+	}
+}
+
+func TestINIRoundTripMapFields(t *testing.T) {
+	type service struct {
+		Name   string                       `ini:"name"`
+		Extra  map[string]string            `ini:"extra"`
+		Limits map[string]int               `ini:"limits"`
+		Groups map[string]map[string]string `ini:"groups"`
+		Nodes  map[string]struct {
+			Port int `ini:"port"`
+		} `ini:"nodes"`
+	}
+	value := service{
+		Name:   "api",
+		Extra:  map[string]string{"region": "eu", "zone": "b"},
+		Limits: map[string]int{"rps": 100},
+		Groups: map[string]map[string]string{"admin": {"user": "root"}},
+		Nodes: map[string]struct {
+			Port int `ini:"port"`
+		}{"a": {Port: 8080}},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(INIFormat)
+	data, err := marshal.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded service
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v\n%s", decoded, value, data)
+	}
+
+	// map 的值类型不匹配时返回错误
+	if err := marshal.Unmarshal([]byte("[limits]\nrps = many\n"), &decoded); err == nil || !strings.Contains(err.Error(), "[limits] rps") {
+		t.Errorf("Expected error for invalid map value, got %v", err)
+	}
+}
+
+func TestINIParse(t *testing.T) {
+	input := `; comment
+# another comment
+name = "  padded ; value"
+unknown = ignored
+
+[Database]
+PORT = 3306
+
+[missing]
+key = value
+`
+	marshal := DefaultMarshalExt().SetFormat(INIFormat)
+
+	var config iniTestConfig
+	if err := marshal.UnmarshalFromString(input, &config); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if config.Name != "  padded ; value" || config.Database.Port != 3306 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	out, _ := marshal.ToINI(map[string]interface{}{"name": config.Name})
+	if out != "name = \"  padded ; value\"\n" {
+		t.Errorf("Values with comment chars should be quoted: %q", out)
+	}
+
+	for _, invalid := range []string{"[section", "no separator", "port = x\n[database]\nport = x"} {
+		if err := marshal.UnmarshalFromString(invalid, &config); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestINIUnmarshalMap(t *testing.T) {
+	input := "name = app\n\n[db]\nport = 5432\n\n[db.pool]\nsize = 10\n"
+	marshal := DefaultMarshalExt().SetFormat(INIFormat)
+
+	var sections map[string]map[string]string
+	if err := marshal.UnmarshalFromString(input, &sections); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if sections[""]["name"] != "app" || sections["db"]["port"] != "5432" || sections["db.pool"]["size"] != "10" {
+		t.Errorf("Unexpected sections: %v", sections)
+	}
+
+	var tree map[string]interface{}
+	if err := marshal.UnmarshalFromString(input, &tree); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	pool := tree["db"].(map[string]interface{})["pool"].(map[string]interface{})
+	if tree["name"] != "app" || pool["size"] != "10" {
+		t.Errorf("Unexpected tree: %v", tree)
+	}
+
+	out, err := marshal.ToINI(tree)
+	if err != nil || out != input {
+		t.Errorf("Map round trip mismatch: %q, %v", out, err)
+	}
+}
+
+func TestINIHelpers(t *testing.T) {
+	value := map[string]interface{}{"name": "app"}
+	expected := "name = app\n"
+
+	if out, err := ToINI(value); err != nil || out != expected {
+		t.Errorf("ToINI returned %q, %v", out, err)
+	}
+	if out := MustToINI(value); out != expected {
+		t.Errorf("MustToINI returned %q", out)
+	}
+	if out := NewMarshalBuilder(value).MustToINI(); out != expected {
+		t.Errorf("Builder MustToINI returned %q", out)
+	}
+	if _, err := ToINI([]int{1}); err == nil || !strings.Contains(err.Error(), "ini marshal error") {
+		t.Errorf("Expected ini marshal error, got %v", err)
+	}
+}
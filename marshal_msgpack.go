@@ -0,0 +1,366 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// msgpackTimestampType MessagePack 时间戳扩展类型 (-1) 的字节表示
+const msgpackTimestampType byte = 0xff
+
+// MessagePack 序列化实现，结构体键名取 msgpack 标签，time.Time 编码为时间戳扩展类型 (-1)
+func (m *MarshalExt) marshalMsgPack(v interface{}) ([]byte, error) {
+	w := &msgpackWriter{}
+	if err := encodeBinary(w, reflect.ValueOf(v), "msgpack", 0); err != nil {
+		return nil, fmt.Errorf("msgpack marshal error: %w", err)
+	}
+	return w.Bytes(), nil
+}
+
+// unmarshalMsgPack 解码 MessagePack，整数解码为 int64（超出范围时为 uint64），
+// map 解码为 map[string]interface{}，未知扩展类型解码为其原始字节
+func (m *MarshalExt) unmarshalMsgPack(data []byte, v interface{}) error {
+	d := &msgpackDecoder{m.newBinaryReader(data)}
+	value, err := d.decode(0)
+	if err != nil {
+		return fmt.Errorf("msgpack unmarshal error: %w", err)
+	}
+	if d.remaining() > 0 {
+		return fmt.Errorf("msgpack unmarshal error: %w: %d bytes", ErrTrailingData, d.remaining())
+	}
+	if err := assignDecoded(v, value, "msgpack"); err != nil {
+		return fmt.Errorf("msgpack unmarshal error: %w", err)
+	}
+	return nil
+}
+
+// msgpackWriter MessagePack 写入器
+type msgpackWriter struct {
+	bytes.Buffer
+}
+
+func (w *msgpackWriter) writeCode(code byte, n uint64, size int) {
+	w.WriteByte(code)
+	buf := binary.BigEndian.AppendUint64(nil, n)
+	w.Write(buf[8-size:])
+}
+
+func (w *msgpackWriter) writeNil() {
+	w.WriteByte(0xc0)
+}
+
+func (w *msgpackWriter) writeBool(b bool) {
+	if b {
+		w.WriteByte(0xc3)
+	} else {
+		w.WriteByte(0xc2)
+	}
+}
+
+func (w *msgpackWriter) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		w.writeUint(uint64(i))
+	case i >= -32:
+		w.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8:
+		w.writeCode(0xd0, uint64(uint8(int8(i))), 1)
+	case i >= math.MinInt16:
+		w.writeCode(0xd1, uint64(uint16(int16(i))), 2)
+	case i >= math.MinInt32:
+		w.writeCode(0xd2, uint64(uint32(int32(i))), 4)
+	default:
+		w.writeCode(0xd3, uint64(i), 8)
+	}
+}
+
+func (w *msgpackWriter) writeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		w.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		w.writeCode(0xcc, u, 1)
+	case u <= math.MaxUint16:
+		w.writeCode(0xcd, u, 2)
+	case u <= math.MaxUint32:
+		w.writeCode(0xce, u, 4)
+	default:
+		w.writeCode(0xcf, u, 8)
+	}
+}
+
+func (w *msgpackWriter) writeFloat32(f float32) {
+	w.writeCode(0xca, uint64(math.Float32bits(f)), 4)
+}
+
+func (w *msgpackWriter) writeFloat64(f float64) {
+	w.writeCode(0xcb, math.Float64bits(f), 8)
+}
+
+func (w *msgpackWriter) writeString(s string) {
+	n := uint64(len(s))
+	switch {
+	case n < 32:
+		w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.writeCode(0xd9, n, 1)
+	case n <= math.MaxUint16:
+		w.writeCode(0xda, n, 2)
+	default:
+		w.writeCode(0xdb, n, 4)
+	}
+	w.WriteString(s)
+}
+
+func (w *msgpackWriter) writeBytes(b []byte) {
+	n := uint64(len(b))
+	switch {
+	case n <= math.MaxUint8:
+		w.writeCode(0xc4, n, 1)
+	case n <= math.MaxUint16:
+		w.writeCode(0xc5, n, 2)
+	default:
+		w.writeCode(0xc6, n, 4)
+	}
+	w.Write(b)
+}
+
+// writeTime 按规范选择 32/64/96 位时间戳
+func (w *msgpackWriter) writeTime(t time.Time) {
+	sec := t.Unix()
+	nsec := uint64(t.Nanosecond())
+	if sec>>34 == 0 {
+		data := nsec<<34 | uint64(sec)
+		if data>>32 == 0 {
+			w.writeCode(0xd6, uint64(msgpackTimestampType), 1)
+			w.writeUint32(uint32(data))
+			return
+		}
+		w.writeCode(0xd7, uint64(msgpackTimestampType), 1)
+		w.writeUint64(data)
+		return
+	}
+	w.writeCode(0xc7, 12, 1)
+	w.WriteByte(msgpackTimestampType)
+	w.writeUint32(uint32(nsec))
+	w.writeUint64(uint64(sec))
+}
+
+func (w *msgpackWriter) writeUint32(u uint32) {
+	w.Write(binary.BigEndian.AppendUint32(nil, u))
+}
+
+func (w *msgpackWriter) writeUint64(u uint64) {
+	w.Write(binary.BigEndian.AppendUint64(nil, u))
+}
+
+func (w *msgpackWriter) writeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		w.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		w.writeCode(0xdc, uint64(n), 2)
+	default:
+		w.writeCode(0xdd, uint64(n), 4)
+	}
+}
+
+func (w *msgpackWriter) writeMapHeader(n int) {
+	switch {
+	case n < 16:
+		w.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		w.writeCode(0xde, uint64(n), 2)
+	default:
+		w.writeCode(0xdf, uint64(n), 4)
+	}
+}
+
+// msgpackDecoder MessagePack 解码器
+type msgpackDecoder struct {
+	binaryReader
+}
+
+// readUint 读取 size 字节的大端无符号整数
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > d.maxDepth {
+		return nil, ErrMaxDepthExceeded
+	}
+
+	code, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.decodeMap(uint64(code&0x0f), depth)
+	case code&0xf0 == 0x90:
+		return d.decodeArray(uint64(code&0x0f), depth)
+	case code&0xe0 == 0xa0:
+		return d.decodeString(uint64(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readUint(1 << (code - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		n, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.readUint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		n, err := d.readUint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := d.readUint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := d.readUint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := d.readUint(8)
+		return int64(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (code - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("invalid code 0x%02x at offset %d", code, d.pos-1)
+}
+
+func (d *msgpackDecoder) decodeString(n uint64) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n uint64, depth int) (interface{}, error) {
+	if err := d.checkLength(n, 1); err != nil {
+		return nil, err
+	}
+	result := make([]interface{}, n)
+	for i := range result {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n uint64, depth int) (interface{}, error) {
+	if err := d.checkLength(n, 2); err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{}, n)
+	for i := uint64(0); i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[binaryMapKey(key)] = value
+	}
+	return result, nil
+}
+
+// decodeExt 解码扩展类型，时间戳解码为 UTC 的 time.Time
+func (d *msgpackDecoder) decodeExt(n uint64) (interface{}, error) {
+	typ, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgpackTimestampType {
+		return append([]byte(nil), data...), nil
+	}
+
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(value&(1<<34-1)), int64(value>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("invalid timestamp length %d", len(data))
+}
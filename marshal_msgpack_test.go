@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+MessagePack 序列化测试
+
+运行命令：
+go test -v -run "^TestMsgPack.*$"
+
+测试内容：
+1. 基本类型编码与规范一致
+2. 时间戳扩展类型 (32/64/96 位)
+3. 结构体标签、嵌套、omitempty 及往返一致性
+4. 解码到 interface{}
+5. 非法数据、尾随数据和长度限制
+*/
+
+func TestMsgPackEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"Nil", nil, "c0"},
+		{"True", true, "c3"},
+		{"PositiveFixint", 1, "01"},
+		{"NegativeFixint", -1, "ff"},
+		{"Int8", -33, "d0df"},
+		{"Uint8", 200, "ccc8"},
+		{"Uint32", 70000, "ce00011170"},
+		{"Int64", int64(math.MinInt64), "d38000000000000000"},
+		{"Uint64", uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{"Float32", float32(1.5), "ca3fc00000"},
+		{"Float64", 1.5, "cb3ff8000000000000"},
+		{"FixStr", "abc", "a3616263"},
+		{"Str8", strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+		{"Bin", []byte{1, 2}, "c4020102"},
+		{"FixArray", []int{1, 2}, "920102"},
+		{"FixMap", map[string]int{"b": 2, "a": 1}, "82a16101a16202"},
+		{"Timestamp32", time.Unix(1, 0), "d6ff00000001"},
+		{"Timestamp64", time.Unix(1, 1), "d7ff0000000400000001"},
+		{"Timestamp96", time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(MsgPackFormat)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := marshal.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if hex.EncodeToString(data) != tt.expected {
+				t.Errorf("Got %x, want %s", data, tt.expected)
+			}
+		})
+	}
+}
+
+type msgpackTestItem struct {
+	ID   int64  `msgpack:"id"`
+	Name string `json:"name"`
+}
+
+type msgpackTestPayload struct {
+	Type      string             `msgpack:"type"`
+	Data      []byte             `msgpack:"data"`
+	CreatedAt time.Time          `msgpack:"created_at"`
+	Items     []msgpackTestItem  `msgpack:"items"`
+	Meta      map[string]float64 `msgpack:"meta"`
+	Parent    *msgpackTestItem   `msgpack:"parent,omitempty"`
+	Note      string             `msgpack:"note,omitempty"`
+	Flags     [2]bool            `msgpack:"flags"`
+	Ignored   string             `msgpack:"-"`
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	payload := msgpackTestPayload{
+		Type:      "event",
+		Data:      []byte{0, 1, 2, 255},
+		CreatedAt: time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC),
+		Items:     []msgpackTestItem{{ID: 1, Name: "a"}, {ID: math.MaxInt64, Name: "b"}},
+		Meta:      map[string]float64{"score": 0.5},
+		Parent:    &msgpackTestItem{ID: -7, Name: "root"},
+		Flags:     [2]bool{true, false},
+		Ignored:   "x",
+	}
+
+	marshal := DefaultMarshalExt().SetFormat(MsgPackFormat)
+	data, err := marshal.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded msgpackTestPayload
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	payload.Ignored = ""
+	if !reflect.DeepEqual(decoded, payload) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", decoded, payload)
+	}
+
+	var generic map[string]interface{}
+	if err := marshal.Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Unmarshal to map failed: %v", err)
+	}
+	if _, ok := generic["note"]; ok {
+		t.Error("omitempty field should be omitted")
+	}
+	items := generic["items"].([]interface{})
+	if items[1].(map[string]interface{})["id"] != int64(math.MaxInt64) {
+		t.Errorf("Integers should keep int64 precision: %v", items[1])
+	}
+	if !generic["created_at"].(time.Time).Equal(payload.CreatedAt) {
+		t.Errorf("Unexpected time: %v", generic["created_at"])
+	}
+}
+
+func TestMsgPackDecodeInterface(t *testing.T) {
+	marshal := DefaultMarshalExt().SetFormat(MsgPackFormat)
+
+	data, _ := hex.DecodeString("83a1610ca162c0a1639201cfffffffffffffffff")
+	var value interface{}
+	if err := marshal.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	expected := map[string]interface{}{
+		"a": int64(12),
+		"b": nil,
+		"c": []interface{}{int64(1), uint64(math.MaxUint64)},
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("Got %#v, want %#v", value, expected)
+	}
+
+	// 未知扩展类型返回原始字节
+	data, _ = hex.DecodeString("d40105")
+	if err := marshal.Unmarshal(data, &value); err != nil || !reflect.DeepEqual(value, []byte{5}) {
+		t.Errorf("Unknown extension: %v, %v", value, err)
+	}
+
+	var small int8
+	data, _ = hex.DecodeString("ccc8")
+	if err := marshal.Unmarshal(data, &small); err == nil {
+		t.Error("Overflowing integer should fail")
+	}
+}
+
+func TestMsgPackErrors(t *testing.T) {
+	marshal := DefaultMarshalExt().SetFormat(MsgPackFormat)
+
+	for _, input := range []string{"", "c1", "a3616", "dcffff", "dfffffffff", "0101"} {
+		data, _ := hex.DecodeString(input)
+		var value interface{}
+		if err := marshal.Unmarshal(data, &value); err == nil {
+			t.Errorf("Expected error for %q, got %v", input, value)
+		}
+	}
+
+	deep := strings.Repeat("91", binaryMaxDepth+2) + "c0"
+	data, _ := hex.DecodeString(deep)
+	var value interface{}
+	if err := marshal.Unmarshal(data, &value); err == nil || !strings.Contains(err.Error(), "depth") {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+
+	if err := marshal.Unmarshal([]byte{0x01}, value); err == nil {
+		t.Error("Non-pointer target should fail")
+	}
+	if _, err := marshal.Marshal(make(chan int)); err == nil {
+		t.Error("Unsupported type should fail")
+	}
+
+	_, err := marshal.Clone().SetMaxLength(2).SetTruncate(true).Marshal("too long")
+	if !errors.Is(err, ErrMaxLengthExceeded) {
+		t.Errorf("Binary output should not be truncated, got %v", err)
+	}
+}
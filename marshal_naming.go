@@ -0,0 +1,609 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyNaming 键名风格
+type KeyNaming int
+
+const (
+	// KeepKeyNames 保持原键名
+	KeepKeyNames KeyNaming = iota
+	// SnakeCaseKeys 下划线风格，如 user_name
+	SnakeCaseKeys
+	// CamelCaseKeys 小驼峰风格，如 userName
+	CamelCaseKeys
+	// KebabCaseKeys 短横线风格，如 user-name
+	KebabCaseKeys
+	// PascalCaseKeys 大驼峰风格，如 UserName
+	PascalCaseKeys
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// Apply 将键名转换为该风格，可识别下划线、短横线、空格分隔和驼峰形式，连续大写视为一个单词（如 userID → user_id）
+func (n KeyNaming) Apply(key string) string {
+	if n == KeepKeyNames {
+		return key
+	}
+	words := splitKeyWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	switch n {
+	case SnakeCaseKeys, KebabCaseKeys:
+		sep := "_"
+		if n == KebabCaseKeys {
+			sep = "-"
+		}
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return strings.Join(words, sep)
+	case CamelCaseKeys, PascalCaseKeys:
+		var sb strings.Builder
+		for i, w := range words {
+			if i == 0 && n == CamelCaseKeys {
+				sb.WriteString(strings.ToLower(w))
+				continue
+			}
+			runes := []rune(strings.ToLower(w))
+			runes[0] = unicode.ToUpper(runes[0])
+			sb.WriteString(string(runes))
+		}
+		return sb.String()
+	}
+	return key
+}
+
+// splitKeyWords 按分隔符和大小写变化拆分单词，数字跟随前一个单词
+func splitKeyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		boundary := unicode.IsUpper(r) &&
+			(unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// normalizeKeyName 返回与风格无关的键名：去掉分隔符并转为小写
+func normalizeKeyName(key string) string {
+	var sb strings.Builder
+	for _, r := range key {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// namedField 目标结构体中按风格无关方式匹配到的字段
+type namedField struct {
+	name string
+	typ  reflect.Type
+}
+
+// keyFieldMap 风格无关键名到字段的映射，完全相同的键名总是优先
+type keyFieldMap map[string]namedField
+
+// lookup 按键名查找字段，data 中同时存在字段的原名时不再匹配其他写法
+func (f keyFieldMap) lookup(key string, exists func(name string) bool) (namedField, bool) {
+	field, ok := f[normalizeKeyName(key)]
+	if !ok || (field.name != key && exists(field.name)) {
+		return namedField{}, false
+	}
+	return field, true
+}
+
+// elemType 去掉指针，切片和数组取元素类型（[]byte 除外）
+func elemType(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch {
+		case t.Kind() == reflect.Ptr:
+			t = t.Elem()
+		case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+	return nil
+}
+
+// isNamingStruct 判断是否为需要按字段匹配键名的结构体，自定义解码的类型除外
+func isNamingStruct(t reflect.Type, unmarshaler reflect.Type) bool {
+	return t != nil && t.Kind() == reflect.Struct && t != timeType &&
+		!reflect.PointerTo(t).Implements(unmarshaler) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// jsonKeyFields 按 encoding/json 的键名规则收集结构体字段
+func jsonKeyFields(t reflect.Type) keyFieldMap {
+	fields := make(keyFieldMap)
+	for _, f := range structFields(t, "json") {
+		fields[normalizeKeyName(f.name)] = namedField{f.name, t.FieldByIndex(f.index).Type}
+	}
+	return fields
+}
+
+// yamlKeyFields 按 yaml.v3 的键名规则收集结构体字段：yaml 标签或小写字段名，inline 字段被展开
+func yamlKeyFields(t reflect.Type, fields keyFieldMap) keyFieldMap {
+	if fields == nil {
+		fields = make(keyFieldMap)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			if ft := elemType(f.Type); ft != nil && ft.Kind() == reflect.Struct {
+				yamlKeyFields(ft, fields)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[normalizeKeyName(name)] = namedField{name, f.Type}
+	}
+	return fields
+}
+
+// encodeJSONString 编码 JSON 字符串
+func encodeJSONString(s string, escapeHTML bool) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(escapeHTML)
+	_ = encoder.Encode(s)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// renameJSONKeys 转换 JSON 中对象的键名，其余内容（包括缩进）原样保留
+func (m *MarshalExt) renameJSONKeys(data []byte, escapeHTML bool) ([]byte, error) {
+	naming := m.options.KeyNaming
+	out := make([]byte, 0, len(data))
+	var stack []byte
+	expectKey := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '"':
+			end := i + 1
+			for end < len(data) && data[end] != '"' {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(data) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			raw := data[i : end+1]
+			if expectKey {
+				var key string
+				if err := json.Unmarshal(raw, &key); err != nil {
+					return nil, err
+				}
+				raw = encodeJSONString(naming.Apply(key), escapeHTML)
+				expectKey = false
+			}
+			out = append(out, raw...)
+			i = end
+			continue
+		case '{', '[':
+			stack = append(stack, c)
+			expectKey = c == '{'
+		case '}', ']':
+			stack = stack[:len(stack)-1]
+		case ',':
+			expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// renameGenericKeys 转换通用结构中 map 的键名，返回新结构
+func (m *MarshalExt) renameGenericKeys(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			result[m.options.KeyNaming.Apply(k)] = m.renameGenericKeys(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = m.renameGenericKeys(item)
+		}
+		return result
+	}
+	return v
+}
+
+// matchJSONKeys 将 JSON 中的键名替换为目标类型中按风格无关方式匹配到的字段名
+func matchJSONKeys(data []byte, target interface{}) ([]byte, error) {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return data, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(matchGenericKeys(value, t))
+}
+
+// matchGenericKeys 按目标类型递归替换通用结构中的键名
+func matchGenericKeys(v interface{}, t reflect.Type) interface{} {
+	t = elemType(t)
+	if t == nil {
+		return v
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if t.Kind() == reflect.Map {
+			for k, item := range value {
+				value[k] = matchGenericKeys(item, t.Elem())
+			}
+			return value
+		}
+		if !isNamingStruct(t, jsonUnmarshalerType) {
+			return value
+		}
+		fields := jsonKeyFields(t)
+		exists := func(name string) bool {
+			_, ok := value[name]
+			return ok
+		}
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if field, ok := fields.lookup(k, exists); ok {
+				result[field.name] = matchGenericKeys(item, field.typ)
+				continue
+			}
+			result[k] = item
+		}
+		return result
+	case []interface{}:
+		for i, item := range value {
+			value[i] = matchGenericKeys(item, t)
+		}
+	}
+	return v
+}
+
+// renameYAMLNode 对照编码前的值转换映射节点的键名
+// yaml.v3 输出时已将未加标签的字段名转为小写，因此结构体字段按 yaml 标签名或 Go 字段名转换，其余键按输出的键名转换
+func (m *MarshalExt) renameYAMLNode(node *yaml.Node, v reflect.Value) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			m.renameYAMLNode(child, v)
+		}
+		return
+	}
+	v, _ = derefValue(v)
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			var item reflect.Value
+			if v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && i < v.Len() {
+				item = v.Index(i)
+			}
+			m.renameYAMLNode(child, item)
+		}
+	case yaml.MappingNode:
+		var fields map[string][]int
+		var values map[string]reflect.Value
+		switch {
+		case v.IsValid() && v.Kind() == reflect.Struct && !hasCustomEncoding(v.Type(), yamlMarshalerType):
+			fields = yamlFieldIndexes(v.Type(), nil, nil)
+		case v.IsValid() && v.Kind() == reflect.Map:
+			values = make(map[string]reflect.Value, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				values[fmt.Sprint(iter.Key().Interface())] = iter.Value()
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			var item reflect.Value
+			if key.Kind == yaml.ScalarNode && key.Value != "<<" {
+				name := key.Value
+				if index, ok := fields[name]; ok {
+					item, _ = fieldByIndex(v, index, false)
+					name = yamlSourceName(v.Type().FieldByIndex(index))
+				} else if values != nil {
+					item = values[name]
+				}
+				key.Value = m.options.KeyNaming.Apply(name)
+			}
+			m.renameYAMLNode(node.Content[i+1], item)
+		}
+	}
+}
+
+// yamlSourceName 返回字段的 yaml 标签名，未设置时返回 Go 字段名
+func yamlSourceName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// matchYAMLKeys 逐个文档将 YAML 中的键名替换为目标类型中按风格无关方式匹配到的字段名
+func matchYAMLKeys(data []byte, target interface{}) ([]byte, error) {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return data, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		matchYAMLNode(&node, t)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// matchYAMLNode 按目标类型递归替换节点中的键名
+func matchYAMLNode(node *yaml.Node, t reflect.Type) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			matchYAMLNode(child, t)
+		}
+		return
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			matchYAMLNode(child, elemType(t))
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		return
+	}
+
+	t = elemType(t)
+	if t == nil {
+		return
+	}
+	if t.Kind() == reflect.Map {
+		for i := 1; i < len(node.Content); i += 2 {
+			matchYAMLNode(node.Content[i], t.Elem())
+		}
+		return
+	}
+	if !isNamingStruct(t, yamlUnmarshalerType) {
+		return
+	}
+
+	fields := yamlKeyFields(t, nil)
+	exists := func(name string) bool {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.Kind != yaml.ScalarNode || key.Value == "<<" {
+			continue
+		}
+		if field, ok := fields.lookup(key.Value, exists); ok {
+			key.Value = field.name
+			matchYAMLNode(node.Content[i+1], field.typ)
+		}
+	}
+}
+
+// xmlRenamer 重写 XML 元素名和属性名
+type xmlRenamer interface {
+	startElement(name string) string // 进入元素，返回新名称
+	attr(name string) string         // 当前元素的属性，返回新名称
+	endElement()                     // 离开元素
+}
+
+// rewriteXMLNames 逐个 token 重写元素名和属性名，命名空间前缀和 xmlns 声明保持不变
+func (m *MarshalExt) rewriteXMLNames(data []byte, pretty bool, renamer xmlRenamer) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	if pretty {
+		encoder.Indent("", m.options.Indent)
+	}
+
+	var names []xml.Name
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			start := xml.StartElement{Name: prefixedXMLName(t.Name.Space, renamer.startElement(t.Name.Local))}
+			for _, a := range t.Attr {
+				local := a.Name.Local
+				if a.Name.Space != "xmlns" && !(a.Name.Space == "" && local == "xmlns") {
+					local = renamer.attr(local)
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: prefixedXMLName(a.Name.Space, local), Value: a.Value})
+			}
+			names = append(names, start.Name)
+			token = start
+		case xml.EndElement:
+			if len(names) == 0 {
+				return nil, fmt.Errorf("unexpected end element </%s>", t.Name.Local)
+			}
+			token = xml.EndElement{Name: names[len(names)-1]}
+			names = names[:len(names)-1]
+			renamer.endElement()
+		case xml.CharData:
+			if pretty && len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		}
+		if err := encoder.EncodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// prefixedXMLName 将命名空间前缀并入本地名，避免编码器重新生成命名空间声明
+func prefixedXMLName(prefix, local string) xml.Name {
+	if prefix == "" {
+		return xml.Name{Local: local}
+	}
+	return xml.Name{Local: prefix + ":" + local}
+}
+
+// xmlEncodeRenamer 编码时按 KeyNaming 转换根元素以外的元素名和属性名
+type xmlEncodeRenamer struct {
+	naming KeyNaming
+	depth  int
+}
+
+func (r *xmlEncodeRenamer) startElement(name string) string {
+	r.depth++
+	if r.depth == 1 {
+		return name
+	}
+	return r.naming.Apply(name)
+}
+
+func (r *xmlEncodeRenamer) attr(name string) string {
+	return r.naming.Apply(name)
+}
+
+func (r *xmlEncodeRenamer) endElement() {
+	r.depth--
+}
+
+// xmlKeyFields 结构体可接收的 XML 元素和属性，按风格无关键名索引
+type xmlKeyFields struct {
+	elements keyFieldMap
+	attrs    keyFieldMap
+}
+
+// xmlDecodeRenamer 解码时按目标类型将元素名和属性名替换为匹配到的字段名
+type xmlDecodeRenamer struct {
+	root  reflect.Type
+	stack []*xmlKeyFields // 为 nil 的项表示该元素不需要匹配
+}
+
+func (r *xmlDecodeRenamer) startElement(name string) string {
+	if len(r.stack) == 0 {
+		r.stack = append(r.stack, newXMLKeyFields(r.root))
+		return name
+	}
+	parent := r.stack[len(r.stack)-1]
+	if parent != nil {
+		if field, ok := parent.elements.lookup(name, func(string) bool { return false }); ok {
+			r.stack = append(r.stack, newXMLKeyFields(field.typ))
+			return field.name
+		}
+	}
+	r.stack = append(r.stack, nil)
+	return name
+}
+
+func (r *xmlDecodeRenamer) attr(name string) string {
+	if current := r.stack[len(r.stack)-1]; current != nil {
+		if field, ok := current.attrs.lookup(name, func(string) bool { return false }); ok {
+			return field.name
+		}
+	}
+	return name
+}
+
+func (r *xmlDecodeRenamer) endElement() {
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+// newXMLKeyFields 收集结构体的 XML 字段，非结构体返回 nil
+func newXMLKeyFields(t reflect.Type) *xmlKeyFields {
+	t = elemType(t)
+	if !isNamingStruct(t, reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+
+	info := &xmlStructInfo{elements: make(map[string]reflect.Type), attrs: make(map[string]bool)}
+	xmlStructFields(t, info)
+	fields := &xmlKeyFields{elements: make(keyFieldMap), attrs: make(keyFieldMap)}
+	for name, typ := range info.elements {
+		fields.elements[normalizeKeyName(name)] = namedField{name, typ}
+	}
+	for name := range info.attrs {
+		fields.attrs[normalizeKeyName(name)] = namedField{name: name}
+	}
+	return fields
+}
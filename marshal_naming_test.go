@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+/*
+键名风格测试
+
+运行命令：
+go test -v -run "^TestKeyNaming.*$"
+
+测试内容：
+1. 各风格的键名转换
+2. JSON 编码转换字段名和 map 键名并保持顺序
+3. JSON、YAML、XML 解码按风格无关方式匹配字段
+4. YAML、XML 编码转换键名，YAML 未加标签的字段按 Go 字段名转换，map 键解码时不还原
+*/
+
+type namingAddress struct {
+	StreetName string `json:"street_name" yaml:"street_name" xml:"street_name"`
+	ZipCode    string
+}
+
+type namingUser struct {
+	UserID    int               `json:"user_id"`
+	FirstName string            `json:"firstName"`
+	HomeURL   string            `json:"HomeURL"`
+	Address   namingAddress     `json:"address"`
+	Labels    map[string]string `json:"labels"`
+	Aliases   []namingAddress   `json:"aliases" xml:"aliases"`
+}
+
+func TestKeyNamingApply(t *testing.T) {
+	cases := []struct {
+		key    string
+		naming KeyNaming
+		want   string
+	}{
+		{"UserID", SnakeCaseKeys, "user_id"},
+		{"userID", KebabCaseKeys, "user-id"},
+		{"HTTPServer", SnakeCaseKeys, "http_server"},
+		{"user_name", CamelCaseKeys, "userName"},
+		{"user-name", PascalCaseKeys, "UserName"},
+		{"HomeURL", CamelCaseKeys, "homeUrl"},
+		{"address2Line", SnakeCaseKeys, "address2_line"},
+		{"already_snake", SnakeCaseKeys, "already_snake"},
+		{"Name", KeepKeyNames, "Name"},
+		{"__", SnakeCaseKeys, "__"},
+	}
+	for _, c := range cases {
+		if got := c.naming.Apply(c.key); got != c.want {
+			t.Errorf("Apply(%q, %d) = %q, want %q", c.key, c.naming, got, c.want)
+		}
+	}
+}
+
+func TestKeyNamingJSON(t *testing.T) {
+	user := namingUser{
+		UserID:    7,
+		FirstName: "Ada",
+		HomeURL:   "https://example.com/?a=<b>",
+		Address:   namingAddress{StreetName: "Main", ZipCode: "100000"},
+		Labels:    map[string]string{"team_name": "core"},
+	}
+
+	out := DefaultMarshalExt().SetKeyNaming(CamelCaseKeys).MustToJSON(user)
+	expected := `{"userId":7,"firstName":"Ada","homeUrl":"https://example.com/?a=\u003cb\u003e","address":{"streetName":"Main","zipCode":"100000"},"labels":{"teamName":"core"},"aliases":null}`
+	if out != expected {
+		t.Errorf("JSON = %s\nwant %s", out, expected)
+	}
+
+	pretty := DefaultMarshalExt().SetKeyNaming(KebabCaseKeys).MustToPrettyJSON(user.Address)
+	if pretty != "{\n  \"street-name\": \"Main\",\n  \"zip-code\": \"100000\"\n}" {
+		t.Errorf("Pretty JSON = %s", pretty)
+	}
+
+	sorted, _ := DefaultMarshalExt().SetKeyNaming(SnakeCaseKeys).SetSortKeys(true).ToJSON(user.Address)
+	if sorted != `{"street_name":"Main","zip_code":"100000"}` {
+		t.Errorf("Sorted JSON = %s", sorted)
+	}
+
+	var decoded namingUser
+	m := DefaultMarshalExt().SetKeyNaming(KebabCaseKeys).SetDisallowUnknownFields(true)
+	input := `{"user-id":7,"first-name":"Ada","home-url":"x","address":{"STREET-NAME":"Main","zip-code":"1"},"aliases":[{"streetName":"Side"}],"labels":{"team-name":"core"}}`
+	if err := m.Unmarshal([]byte(input), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.UserID != 7 || decoded.FirstName != "Ada" || decoded.HomeURL != "x" ||
+		decoded.Address.StreetName != "Main" || decoded.Address.ZipCode != "1" ||
+		len(decoded.Aliases) != 1 || decoded.Aliases[0].StreetName != "Side" {
+		t.Errorf("Unexpected result: %+v", decoded)
+	}
+	if decoded.Labels["team-name"] != "core" {
+		t.Errorf("Map keys should be kept on decode: %v", decoded.Labels)
+	}
+
+	if err := m.Unmarshal([]byte(`{"unknown_field":1}`), &decoded); err == nil {
+		t.Error("Unknown fields should still be rejected")
+	}
+}
+
+func TestKeyNamingYAML(t *testing.T) {
+	m := DefaultMarshalExt().SetFormat(YAMLFormat).SetKeyNaming(KebabCaseKeys)
+	out := m.MustToYAML(namingAddress{StreetName: "Main", ZipCode: "1"})
+	if out != "street-name: Main\nzip-code: \"1\"\n" {
+		t.Errorf("YAML = %q", out)
+	}
+
+	var decoded namingAddress
+	if err := m.Unmarshal([]byte("streetName: Main\nzip_code: \"2\"\n"), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.StreetName != "Main" || decoded.ZipCode != "2" {
+		t.Errorf("Unexpected result: %+v", decoded)
+	}
+}
+
+func TestKeyNamingYAMLFieldNames(t *testing.T) {
+	type server struct {
+		HTTPPort  int
+		CreatedAt string
+		Labels    map[string]string
+	}
+	type config struct {
+		UserID  int
+		Servers []server
+		Extra   interface{}
+	}
+	value := config{
+		UserID:  7,
+		Servers: []server{{HTTPPort: 80, CreatedAt: "today", Labels: map[string]string{"someKey": "v"}}},
+		Extra:   server{HTTPPort: 81},
+	}
+
+	m := DefaultMarshalExt().SetFormat(YAMLFormat).SetKeyNaming(SnakeCaseKeys)
+	out := m.MustToYAML(value)
+	expected := "user_id: 7\nservers:\n  - http_port: 80\n    created_at: today\n    labels:\n      some_key: v\n" +
+		"extra:\n  http_port: 81\n  created_at: \"\"\n  labels: {}\n"
+	if out != expected {
+		t.Errorf("YAML =\n%s\nwant\n%s", out, expected)
+	}
+
+	// 结构体字段可以还原，map 的键保持转换后的写法
+	var decoded config
+	if err := m.UnmarshalFromString(out, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.UserID != 7 || decoded.Servers[0].HTTPPort != 80 || decoded.Servers[0].Labels["some_key"] != "v" {
+		t.Errorf("Unexpected result: %+v", decoded)
+	}
+}
+
+func TestKeyNamingXML(t *testing.T) {
+	type config struct {
+		ServerName string `xml:"ServerName"`
+		MaxConns   int    `xml:"maxConns,attr"`
+	}
+	m := DefaultMarshalExt().SetKeyNaming(SnakeCaseKeys)
+	out := m.MustToXML(config{ServerName: "api", MaxConns: 10})
+	if out != `<config max_conns="10"><server_name>api</server_name></config>` {
+		t.Errorf("XML = %s", out)
+	}
+
+	pretty := m.MustToPrettyXML(config{ServerName: "api"})
+	if !strings.Contains(pretty, "\n  <server_name>api</server_name>\n") {
+		t.Errorf("Pretty XML = %s", pretty)
+	}
+
+	var decoded config
+	if err := m.Clone().SetFormat(XMLFormat).Unmarshal([]byte(`<config max-conns="3"><server-name>web</server-name></config>`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.ServerName != "web" || decoded.MaxConns != 3 {
+		t.Errorf("Unexpected result: %+v", decoded)
+	}
+}
@@ -0,0 +1,653 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 文档参数与查询一致，可以是 JSON 字节、通用结构或任意可 JSON 编码的值，输入不会被修改
+// MarshalExt 上的同名方法按当前格式解码和编码字节，可用于 YAML、TOML 等格式
+
+// ErrTestFailed JSON Patch 的 test 操作不匹配
+var ErrTestFailed = errors.New("patch test failed")
+
+// PatchOperation JSON Patch 操作 (RFC 6902)
+type PatchOperation struct {
+	Op    string      `json:"op" yaml:"op"` // add、remove、replace、move、copy、test
+	Path  string      `json:"path" yaml:"path"`
+	From  string      `json:"from,omitempty" yaml:"from,omitempty"` // move、copy 的来源
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// MarshalJSON add、replace、test 操作总是输出 value，即使为 null
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation struct {
+		Op    string       `json:"op"`
+		Path  string       `json:"path"`
+		From  string       `json:"from,omitempty"`
+		Value *interface{} `json:"value,omitempty"`
+	}
+	out := operation{Op: op.Op, Path: op.Path, From: op.From}
+	if op.hasValue() {
+		out.Value = &op.Value
+	}
+	return json.Marshal(out)
+}
+
+// hasValue 操作是否需要 value
+func (op PatchOperation) hasValue() bool {
+	return op.Op == "add" || op.Op == "replace" || op.Op == "test"
+}
+
+// JSONPatch JSON Patch 文档，按顺序执行的操作列表
+type JSONPatch []PatchOperation
+
+// ApplyJSONPatch 对文档的副本依次执行操作并返回结果，任一操作失败时返回错误且不产生部分结果
+func ApplyJSONPatch(doc interface{}, patch JSONPatch) (interface{}, error) {
+	root, err := queryDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	root = deepCopyGeneric(root)
+
+	for i, op := range patch {
+		if root, err = applyPatchOperation(root, op); err != nil {
+			return nil, fmt.Errorf("patch error: operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return root, nil
+}
+
+// applyPatchOperation 执行单个操作
+func applyPatchOperation(root interface{}, op PatchOperation) (interface{}, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := genericNode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, deepCopyGeneric(value))
+	case "remove":
+		root, _, err := pointerRemove(root, path)
+		return root, err
+	case "replace":
+		value, err := genericNode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if root, _, err = pointerRemove(root, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, deepCopyGeneric(value))
+	case "move":
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, fmt.Errorf("cannot move %s into its own child %s", op.From, op.Path)
+		}
+		root, value, err := pointerRemove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, value)
+	case "copy":
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := from.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, deepCopyGeneric(value))
+	case "test":
+		actual, err := path.Get(root)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := genericNode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !genericEqual(actual, expected) {
+			return nil, fmt.Errorf("%w: %s is %s", ErrTestFailed, op.Path, formatDiffValue(actual))
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// pointerAdd 在 path 处添加值：对象成员被设置或替换，数组元素被插入，"-" 表示追加到末尾
+func pointerAdd(root interface{}, path JSONPointer, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateParent(root, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			if key == "-" {
+				return append(node, value), nil
+			}
+			index, err := pointerIndex(key, len(node)+1)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, path, err)
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("%w: %s: cannot add to %T", ErrPathNotFound, path, parent)
+	})
+}
+
+// pointerRemove 删除 path 处的值并返回被删除的值
+func pointerRemove(root interface{}, path JSONPointer) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, root, nil
+	}
+	var removed interface{}
+	root, err := updateParent(root, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			}
+			removed = value
+			delete(node, key)
+			return node, nil
+		case []interface{}:
+			index, err := pointerIndex(key, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, path, err)
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: %s: cannot remove from %T", ErrPathNotFound, path, parent)
+	})
+	return root, removed, err
+}
+
+// updateParent 找到 path 的父节点并调用 update，返回更新后的根（数组可能被重新分配）
+func updateParent(node interface{}, path JSONPointer, update func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(node, path[0])
+	}
+
+	key := path[0]
+	switch parent := node.(type) {
+	case map[string]interface{}:
+		child, ok := parent[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, key)
+		}
+		updated, err := updateParent(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		parent[key] = updated
+		return parent, nil
+	case []interface{}:
+		index, err := pointerIndex(key, len(parent))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPathNotFound, err)
+		}
+		updated, err := updateParent(parent[index], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		parent[index] = updated
+		return parent, nil
+	}
+	return nil, fmt.Errorf("%w: cannot index %T with %q", ErrPathNotFound, node, key)
+}
+
+// deepCopyGeneric 深拷贝通用结构
+func deepCopyGeneric(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			result[k] = deepCopyGeneric(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = deepCopyGeneric(item)
+		}
+		return result
+	}
+	return v
+}
+
+// genericEqual 比较两个通用值，数字按数值比较
+func genericEqual(a, b interface{}) bool {
+	if x, ok := queryNumber(a); ok {
+		y, ok := queryNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, item := range x {
+			other, ok := y[k]
+			if !ok || !genericEqual(item, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !genericEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// CreateJSONPatch 生成将 from 变为 to 的 JSON Patch
+// 对象按键名比较，数组按下标比较，多出的元素从末尾开始删除，缺少的元素按下标依次添加
+func CreateJSONPatch(from, to interface{}) (JSONPatch, error) {
+	diff, err := Diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	patch := make(JSONPatch, len(diff))
+	for i, entry := range diff {
+		switch entry.Kind {
+		case DiffAdded:
+			patch[i] = PatchOperation{Op: "add", Path: entry.Path, Value: entry.New}
+		case DiffRemoved:
+			patch[i] = PatchOperation{Op: "remove", Path: entry.Path}
+		default:
+			patch[i] = PatchOperation{Op: "replace", Path: entry.Path, Value: entry.New}
+		}
+	}
+	return patch, nil
+}
+
+// ApplyMergePatch 按 JSON Merge Patch (RFC 7386) 合并：patch 中为 null 的成员被删除，对象递归合并，其余值直接替换
+func ApplyMergePatch(doc, patch interface{}) (interface{}, error) {
+	target, err := queryDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := queryDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergePatch(deepCopyGeneric(target), changes), nil
+}
+
+// mergePatch 执行合并，target 会被修改
+func mergePatch(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopyGeneric(patch)
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{})
+	}
+	for k, value := range changes {
+		if value == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = mergePatch(result[k], value)
+	}
+	return result
+}
+
+// CreateMergePatch 生成将 from 变为 to 的 JSON Merge Patch，没有变化时返回空对象
+// Merge Patch 无法表示将成员设为 null 和数组的局部修改，数组总是整体替换
+func CreateMergePatch(from, to interface{}) (interface{}, error) {
+	source, err := queryDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := queryDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	return createMergePatch(source, target), nil
+}
+
+// createMergePatch 递归生成 Merge Patch
+func createMergePatch(from, to interface{}) interface{} {
+	source, ok1 := from.(map[string]interface{})
+	target, ok2 := to.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return deepCopyGeneric(to)
+	}
+
+	patch := make(map[string]interface{})
+	for k := range source {
+		if _, ok := target[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, value := range target {
+		old, ok := source[k]
+		if ok && genericEqual(old, value) {
+			continue
+		}
+		_, oldIsMap := old.(map[string]interface{})
+		if _, newIsMap := value.(map[string]interface{}); ok && oldIsMap && newIsMap {
+			patch[k] = createMergePatch(old, value)
+			continue
+		}
+		patch[k] = deepCopyGeneric(value)
+	}
+	return patch
+}
+
+// DiffKind 差异类型
+type DiffKind int
+
+const (
+	// DiffAdded 新增
+	DiffAdded DiffKind = iota
+	// DiffRemoved 删除
+	DiffRemoved
+	// DiffChanged 修改
+	DiffChanged
+)
+
+// String 返回差异类型名称
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return fmt.Sprintf("DiffKind(%d)", int(k))
+}
+
+// DiffEntry 单个差异
+type DiffEntry struct {
+	Path string // JSON Pointer，空字符串表示根
+	Kind DiffKind
+	Old  interface{} // 删除和修改时的原值
+	New  interface{} // 新增和修改时的新值
+}
+
+// String 返回可读形式，如 "~ /server/port: 8080 -> 9090"、"+ /tags/1: \"b\""、"- /debug: true"
+func (e DiffEntry) String() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	switch e.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", path, formatDiffValue(e.New))
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", path, formatDiffValue(e.Old))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", path, formatDiffValue(e.Old), formatDiffValue(e.New))
+}
+
+// DiffResult 结构化差异
+type DiffResult []DiffEntry
+
+// String 每行一个差异，没有差异时返回空字符串
+func (d DiffResult) String() string {
+	lines := make([]string, len(d))
+	for i, entry := range d {
+		lines[i] = entry.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff 比较两个值的结构化差异，对象按键名排序输出
+func Diff(from, to interface{}) (DiffResult, error) {
+	source, err := queryDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := queryDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	result := DiffResult{}
+	diffValues(JSONPointer{}, source, target, &result)
+	return result, nil
+}
+
+// diffValues 递归比较并记录差异
+func diffValues(path JSONPointer, from, to interface{}, result *DiffResult) {
+	switch source := from.(type) {
+	case map[string]interface{}:
+		if target, ok := to.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(source)+len(target))
+			for k := range source {
+				keys = append(keys, k)
+			}
+			for k := range target {
+				if _, ok := source[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				old, inSource := source[k]
+				value, inTarget := target[k]
+				switch {
+				case !inTarget:
+					*result = append(*result, DiffEntry{Path: path.Append(k).String(), Kind: DiffRemoved, Old: old})
+				case !inSource:
+					*result = append(*result, DiffEntry{Path: path.Append(k).String(), Kind: DiffAdded, New: value})
+				default:
+					diffValues(path.Append(k), old, value, result)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if target, ok := to.([]interface{}); ok {
+			common := min(len(source), len(target))
+			for i := 0; i < common; i++ {
+				diffValues(path.Append(strconv.Itoa(i)), source[i], target[i], result)
+			}
+			for i := len(source) - 1; i >= common; i-- {
+				*result = append(*result, DiffEntry{Path: path.Append(strconv.Itoa(i)).String(), Kind: DiffRemoved, Old: source[i]})
+			}
+			for i := common; i < len(target); i++ {
+				*result = append(*result, DiffEntry{Path: path.Append(strconv.Itoa(i)).String(), Kind: DiffAdded, New: target[i]})
+			}
+			return
+		}
+	}
+
+	if !genericEqual(from, to) {
+		*result = append(*result, DiffEntry{Path: path.String(), Kind: DiffChanged, Old: from, New: to})
+	}
+}
+
+// formatDiffValue 将值格式化为紧凑 JSON
+func formatDiffValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// decodePatchDocument 将通用结构转换为 JSONPatch
+func decodePatchDocument(v interface{}) (JSONPatch, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch document must be an array, got %T", v)
+	}
+	patch := make(JSONPatch, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d must be an object, got %T", i, item)
+		}
+		op, _ := fields["op"].(string)
+		path, ok := fields["path"].(string)
+		if op == "" || !ok {
+			return nil, fmt.Errorf("operation %d requires op and path", i)
+		}
+		patch[i] = PatchOperation{Op: op, Path: path, Value: fields["value"]}
+		if _, ok := fields["value"]; !ok && patch[i].hasValue() {
+			return nil, fmt.Errorf("operation %d (%s) requires value", i, op)
+		}
+		if op == "move" || op == "copy" {
+			if patch[i].From, ok = fields["from"].(string); !ok {
+				return nil, fmt.Errorf("operation %d (%s) requires from", i, op)
+			}
+		}
+	}
+	return patch, nil
+}
+
+// encodePatchDocument 将 JSONPatch 转换为通用结构，便于按任意格式编码
+func encodePatchDocument(patch JSONPatch) []interface{} {
+	items := make([]interface{}, len(patch))
+	for i, op := range patch {
+		fields := map[string]interface{}{"op": op.Op, "path": op.Path}
+		if op.From != "" {
+			fields["from"] = op.From
+		}
+		if op.hasValue() {
+			fields["value"] = op.Value
+		}
+		items[i] = fields
+	}
+	return items
+}
+
+// decodeDocument 按当前格式将字节解码为通用结构，同时返回 XML 根元素名
+func (m *MarshalExt) decodeDocument(data []byte) (interface{}, string, error) {
+	value, root, err := m.decodeGeneric(data, m.options.Format)
+	if err != nil {
+		return nil, "", fmt.Errorf("patch decode error: %w", err)
+	}
+	return value, root, nil
+}
+
+// encodeDocument 按当前格式编码通用结构，XML 使用 root 作为根元素名
+func (m *MarshalExt) encodeDocument(v interface{}, root string) ([]byte, error) {
+	ext := m
+	if root != "" && m.options.Format == XMLFormat {
+		ext = m.Clone().SetXMLRoot(root)
+	}
+	return ext.Marshal(v)
+}
+
+// ApplyPatch 按当前格式解码文档和 JSON Patch，执行后按当前格式编码结果
+func (m *MarshalExt) ApplyPatch(data, patch []byte) ([]byte, error) {
+	doc, root, err := m.decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	ops, _, err := m.decodeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	jsonPatch, err := decodePatchDocument(ops)
+	if err != nil {
+		return nil, fmt.Errorf("patch error: %w", err)
+	}
+	result, err := ApplyJSONPatch(doc, jsonPatch)
+	if err != nil {
+		return nil, err
+	}
+	return m.encodeDocument(result, root)
+}
+
+// CreatePatch 比较两个当前格式的文档，返回按当前格式编码的 JSON Patch
+func (m *MarshalExt) CreatePatch(from, to []byte) ([]byte, error) {
+	source, _, err := m.decodeDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	target, _, err := m.decodeDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := CreateJSONPatch(source, target)
+	if err != nil {
+		return nil, err
+	}
+	return m.encodeDocument(encodePatchDocument(patch), "patch")
+}
+
+// ApplyMergePatch 按当前格式解码文档和 Merge Patch，合并后按当前格式编码结果
+func (m *MarshalExt) ApplyMergePatch(data, patch []byte) ([]byte, error) {
+	doc, root, err := m.decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	changes, _, err := m.decodeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ApplyMergePatch(doc, changes)
+	if err != nil {
+		return nil, err
+	}
+	return m.encodeDocument(result, root)
+}
+
+// CreateMergePatch 比较两个当前格式的文档，返回按当前格式编码的 Merge Patch
+func (m *MarshalExt) CreateMergePatch(from, to []byte) ([]byte, error) {
+	source, _, err := m.decodeDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	target, root, err := m.decodeDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := CreateMergePatch(source, target)
+	if err != nil {
+		return nil, err
+	}
+	return m.encodeDocument(patch, root)
+}
+
+// Diff 比较两个当前格式的文档
+func (m *MarshalExt) Diff(from, to []byte) (DiffResult, error) {
+	source, _, err := m.decodeDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	target, _, err := m.decodeDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(source, target)
+}
@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+JSON Patch、Merge Patch 与结构化差异测试
+
+运行命令：
+go test -v -run "^Test(JSONPatch|MergePatch|Diff|FormatPatch).*$"
+
+测试内容：
+1. RFC 6902 附录中的 JSON Patch 示例
+2. 失败的操作不修改输入且返回错误
+3. 生成 JSON Patch 并应用后得到目标文档
+4. RFC 7386 附录中的 Merge Patch 示例及生成
+5. 可读的结构化差异
+6. 按 YAML 格式读写文档和补丁
+*/
+
+// mustGeneric 将 JSON 字符串解码为通用结构
+func mustGeneric(t *testing.T, s string) interface{} {
+	t.Helper()
+	value, err := queryDocument([]byte(s))
+	if err != nil {
+		t.Fatalf("Invalid JSON %s: %v", s, err)
+	}
+	return value
+}
+
+func TestJSONPatchApply(t *testing.T) {
+	cases := []struct {
+		doc, patch, expected string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"foo":"bar"}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"baz":"bar","foo":"bar"}`},
+		{`{"foo":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+	for _, c := range cases {
+		var patch JSONPatch
+		if err := json.Unmarshal([]byte(c.patch), &patch); err != nil {
+			t.Fatalf("Invalid patch %s: %v", c.patch, err)
+		}
+		result, err := ApplyJSONPatch([]byte(c.doc), patch)
+		if err != nil {
+			t.Errorf("ApplyJSONPatch(%s, %s) failed: %v", c.doc, c.patch, err)
+			continue
+		}
+		if !genericEqual(result, mustGeneric(t, c.expected)) {
+			t.Errorf("ApplyJSONPatch(%s, %s) = %s, want %s", c.doc, c.patch, formatDiffValue(result), c.expected)
+		}
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	doc := map[string]interface{}{"foo": "bar", "list": []interface{}{1, 2}}
+	cases := []struct {
+		patch JSONPatch
+		err   error
+	}{
+		{JSONPatch{{Op: "add", Path: "/baz/bat", Value: "qux"}}, ErrPathNotFound},
+		{JSONPatch{{Op: "remove", Path: "/missing"}}, ErrPathNotFound},
+		{JSONPatch{{Op: "add", Path: "/list/5", Value: 1}}, ErrPathNotFound},
+		{JSONPatch{{Op: "test", Path: "/foo", Value: "baz"}}, ErrTestFailed},
+		{JSONPatch{{Op: "add", Path: "/new", Value: 1}, {Op: "test", Path: "/list/0", Value: "1"}}, ErrTestFailed},
+		{JSONPatch{{Op: "move", From: "/list", Path: "/list/0"}}, nil},
+		{JSONPatch{{Op: "bogus", Path: "/foo"}}, nil},
+	}
+	for _, c := range cases {
+		_, err := ApplyJSONPatch(doc, c.patch)
+		if err == nil || (c.err != nil && !errors.Is(err, c.err)) {
+			t.Errorf("ApplyJSONPatch(%v) error = %v, want %v", c.patch, err, c.err)
+		}
+	}
+	if _, ok := doc["new"]; ok || len(doc) != 2 {
+		t.Errorf("Input should not be modified: %v", doc)
+	}
+
+	data, _ := json.Marshal(JSONPatch{{Op: "add", Path: "/a", Value: nil}, {Op: "remove", Path: "/b"}})
+	if string(data) != `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"}]` {
+		t.Errorf("Patch JSON = %s", data)
+	}
+}
+
+func TestJSONPatchCreate(t *testing.T) {
+	from := `{"name":"app","port":8080,"tags":["a","b","c"],"db":{"host":"localhost","user":"root"},"debug":true}`
+	to := `{"name":"app","port":9090,"tags":["a","x"],"db":{"host":"db.internal","user":"root","pool":10}}`
+
+	patch, err := CreateJSONPatch([]byte(from), []byte(to))
+	if err != nil {
+		t.Fatalf("CreateJSONPatch failed: %v", err)
+	}
+	result, err := ApplyJSONPatch([]byte(from), patch)
+	if err != nil {
+		t.Fatalf("ApplyJSONPatch failed: %v", err)
+	}
+	if !genericEqual(result, mustGeneric(t, to)) {
+		t.Errorf("Patched = %s, want %s", formatDiffValue(result), to)
+	}
+
+	grown, err := CreateJSONPatch([]int{1}, []int{1, 2, 3})
+	if err != nil || len(grown) != 2 || grown[0].Path != "/1" || grown[1].Path != "/2" {
+		t.Errorf("Unexpected patch: %+v, %v", grown, err)
+	}
+	if same, _ := CreateJSONPatch([]byte(from), []byte(from)); len(same) != 0 {
+		t.Errorf("Identical documents should produce empty patch: %+v", same)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		result, err := ApplyMergePatch([]byte(c.target), []byte(c.patch))
+		if err != nil {
+			t.Errorf("ApplyMergePatch(%s, %s) failed: %v", c.target, c.patch, err)
+			continue
+		}
+		if !genericEqual(result, mustGeneric(t, c.expected)) {
+			t.Errorf("ApplyMergePatch(%s, %s) = %s, want %s", c.target, c.patch, formatDiffValue(result), c.expected)
+		}
+	}
+
+	from := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"text"}`
+	to := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"text","phoneNumber":"+01-123-456-7890"}`
+	patch, err := CreateMergePatch([]byte(from), []byte(to))
+	if err != nil {
+		t.Fatalf("CreateMergePatch failed: %v", err)
+	}
+	expected := `{"author":{"familyName":null},"phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`
+	if formatDiffValue(patch) != expected {
+		t.Errorf("CreateMergePatch = %s, want %s", formatDiffValue(patch), expected)
+	}
+	result, _ := ApplyMergePatch([]byte(from), patch)
+	if !genericEqual(result, mustGeneric(t, to)) {
+		t.Errorf("Merge round trip = %s", formatDiffValue(result))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type config struct {
+		Name  string   `json:"name"`
+		Port  int      `json:"port"`
+		Tags  []string `json:"tags"`
+		Debug bool     `json:"debug,omitempty"`
+	}
+	diff, err := Diff(config{Name: "app", Port: 8080, Tags: []string{"a"}, Debug: true}, config{Name: "app", Port: 9090, Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected := "- /debug: true\n~ /port: 8080 -> 9090\n+ /tags/1: \"b\""
+	if diff.String() != expected {
+		t.Errorf("Diff =\n%s\nwant\n%s", diff, expected)
+	}
+	if diff[1].Kind != DiffChanged || diff[1].Kind.String() != "changed" || diff[1].Old != int64(8080) {
+		t.Errorf("Unexpected entry: %+v", diff[1])
+	}
+
+	if same, _ := Diff(map[string]interface{}{"a": 1}, []byte(`{"a":1.0}`)); len(same) != 0 {
+		t.Errorf("Numbers should compare by value: %v", same)
+	}
+	if root, _ := Diff("a", 1); root.String() != `~ (root): "a" -> 1` {
+		t.Errorf("Root diff = %s", root)
+	}
+}
+
+func TestFormatPatch(t *testing.T) {
+	m := DefaultMarshalExt().SetFormat(YAMLFormat)
+	from := []byte("name: app\nport: 8080\ntags:\n  - a\n")
+	to := []byte("name: app\nport: 9090\ntags:\n  - a\n  - b\n")
+
+	patch, err := m.CreatePatch(from, to)
+	if err != nil {
+		t.Fatalf("CreatePatch failed: %v", err)
+	}
+	if !strings.Contains(string(patch), "op: replace") || !strings.Contains(string(patch), "path: /tags/1") {
+		t.Errorf("Unexpected YAML patch:\n%s", patch)
+	}
+
+	result, err := m.ApplyPatch(from, patch)
+	if err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if diff, _ := m.Diff(result, to); len(diff) != 0 {
+		t.Errorf("Patched document differs: %s", diff)
+	}
+
+	merge, err := m.CreateMergePatch(from, to)
+	if err != nil {
+		t.Fatalf("CreateMergePatch failed: %v", err)
+	}
+	merged, err := m.ApplyMergePatch(from, merge)
+	if err != nil {
+		t.Fatalf("ApplyMergePatch failed: %v", err)
+	}
+	var cfg map[string]interface{}
+	if err := m.Unmarshal(merged, &cfg); err != nil || cfg["port"] != 9090 {
+		t.Errorf("Merged document = %v, %v", cfg, err)
+	}
+
+	if _, err := m.ApplyPatch(from, []byte("op: add\n")); err == nil {
+		t.Error("Patch that is not a list should fail")
+	}
+	if _, err := m.ApplyPatch(from, []byte("- op: add\n  path: /x\n")); err == nil {
+		t.Error("Add without value should fail")
+	}
+
+	xml := DefaultMarshalExt().SetFormat(XMLFormat)
+	out, err := xml.ApplyMergePatch([]byte(`<config><port>8080</port></config>`), []byte(`<patch><port>9090</port></patch>`))
+	if err != nil || string(out) != `<config><port>9090</port></config>` {
+		t.Errorf("XML merge = %s, %v", out, err)
+	}
+	if !reflect.DeepEqual(DiffResult{}.String(), "") {
+		t.Error("Empty diff should render as empty string")
+	}
+}
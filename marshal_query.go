@@ -0,0 +1,936 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrPathNotFound JSON Pointer 指向的值不存在或 JSONPath 没有匹配结果
+var ErrPathNotFound = errors.New("path not found")
+
+// 查询的文档可以是 JSON 字节（[]byte、json.RawMessage）、已解码的通用结构（map[string]interface{}、[]interface{} 和标量），
+// 或任意可 JSON 编码的值（结构体等会先转换为通用结构）；JSON 中的数字解码为 int64、uint64 或 float64，超出 uint64 的整数保留为 json.Number
+
+// queryDocument 将文档转换为通用结构
+func queryDocument(doc interface{}) (interface{}, error) {
+	switch value := doc.(type) {
+	case []byte:
+		return decodeQueryJSON(value)
+	case json.RawMessage:
+		return decodeQueryJSON(value)
+	}
+	return genericNode(doc)
+}
+
+// decodeQueryJSON 解码 JSON 字节，数字保留精度
+func decodeQueryJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("query decode error: %w", err)
+	}
+	return normalizeGeneric(value), nil
+}
+
+// genericNode 容器类型的非通用值（结构体、类型化的 map 和切片等）经 JSON 转换为通用结构，其余原样返回
+func genericNode(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}, string, bool, float64, int64, json.Number:
+		return v, nil
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		normalized, err := normalizeJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("query decode error: %w", err)
+		}
+		return normalizeGeneric(normalized), nil
+	}
+	return v, nil
+}
+
+// convertQueryResult 将查询结果转换为类型 T，类型不同时经 JSON 转换
+func convertQueryResult[T any](v interface{}) (T, error) {
+	if result, ok := v.(T); ok {
+		return result, nil
+	}
+	var result T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return result, fmt.Errorf("query convert error: %w", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("query convert error: %w", err)
+	}
+	return result, nil
+}
+
+// JSONPointer JSON Pointer (RFC 6901)，每项为一个未转义的引用标记
+type JSONPointer []string
+
+// ParseJSONPointer 解析 JSON Pointer，空字符串表示整个文档，支持 URI 片段形式（如 #/a%20b）
+func ParseJSONPointer(s string) (JSONPointer, error) {
+	if fragment, ok := strings.CutPrefix(s, "#"); ok {
+		unescaped, err := url.PathUnescape(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json pointer %q: %w", s, err)
+		}
+		s = unescaped
+	}
+	if s == "" {
+		return JSONPointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q: must start with /", s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid json pointer %q: bad escape in %q", s, token)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return JSONPointer(tokens), nil
+}
+
+// String 返回转义后的 JSON Pointer 字符串
+func (p JSONPointer) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteByte('/')
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// Append 返回追加了引用标记的新 Pointer
+func (p JSONPointer) Append(tokens ...string) JSONPointer {
+	result := make(JSONPointer, 0, len(p)+len(tokens))
+	result = append(result, p...)
+	return append(result, tokens...)
+}
+
+// Get 返回 Pointer 指向的值，不存在时返回包装了 ErrPathNotFound 的错误
+func (p JSONPointer) Get(doc interface{}) (interface{}, error) {
+	current, err := queryDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range p {
+		if current, err = genericNode(current); err != nil {
+			return nil, err
+		}
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, p[:i+1])
+			}
+			current = value
+		case []interface{}:
+			index, err := pointerIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrPathNotFound, p[:i+1], err)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%w: %s: cannot index %T", ErrPathNotFound, p[:i+1], current)
+		}
+	}
+	return current, nil
+}
+
+// pointerIndex 解析数组下标，下标必须是无前导零的十进制数且小于 length
+func pointerIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index >= length {
+		return 0, fmt.Errorf("array index %s out of range", token)
+	}
+	return index, nil
+}
+
+// GetPointer 按 JSON Pointer 取值并转换为类型 T
+func GetPointer[T any](doc interface{}, pointer string) (T, error) {
+	var zero T
+	p, err := ParseJSONPointer(pointer)
+	if err != nil {
+		return zero, err
+	}
+	value, err := p.Get(doc)
+	if err != nil {
+		return zero, err
+	}
+	return convertQueryResult[T](value)
+}
+
+// JSONPath 编译后的 JSONPath 表达式
+// 支持的子集：$ 根节点、.name 和 ['name'] 成员、[n] 下标（负数从末尾计）、* 通配、[start:end:step] 切片、
+// [a,b] 并集、..name 递归下降，以及 [?(@.price < 10 && @.tag)] 过滤（==、!=、<、<=、>、>=、&&、||、!、存在性判断）
+// 对象成员按键名排序后遍历，以保证结果顺序稳定
+type JSONPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+// pathSegment 路径中的一段
+type pathSegment struct {
+	descendant bool // .. 递归下降
+	selectors  []pathSelector
+}
+
+// selectorKind 选择器类型
+type selectorKind int
+
+const (
+	nameSelector selectorKind = iota
+	indexSelector
+	wildcardSelector
+	sliceSelector
+	filterSelector
+)
+
+// pathSelector 选择器
+type pathSelector struct {
+	kind   selectorKind
+	name   string
+	index  int
+	slice  [3]*int // start、end、step
+	filter filterExpr
+}
+
+// CompileJSONPath 编译 JSONPath 表达式
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &pathParser{src: expr}
+	p.skipSpace()
+	if !p.consume('$') {
+		return nil, p.errorf("path must start with $")
+	}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.rest())
+	}
+	return &JSONPath{expr: expr, segments: segments}, nil
+}
+
+// MustCompileJSONPath 编译 JSONPath 表达式，出错时 panic
+func MustCompileJSONPath(expr string) *JSONPath {
+	path, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// String 返回原始表达式
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Select 返回所有匹配的值，没有匹配时返回空切片
+func (p *JSONPath) Select(doc interface{}) ([]interface{}, error) {
+	root, err := queryDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return selectSegments(root, root, p.segments)
+}
+
+// selectSegments 从 current 开始依次应用各段，root 供过滤表达式中的 $ 使用
+func selectSegments(root, current interface{}, segments []pathSegment) ([]interface{}, error) {
+	nodes := []interface{}{current}
+	for _, segment := range segments {
+		var next []interface{}
+		for _, node := range nodes {
+			targets := []interface{}{node}
+			if segment.descendant {
+				var err error
+				if targets, err = descendants(node, nil); err != nil {
+					return nil, err
+				}
+			}
+			for _, target := range targets {
+				for _, selector := range segment.selectors {
+					selected, err := selector.apply(root, target)
+					if err != nil {
+						return nil, err
+					}
+					next = append(next, selected...)
+				}
+			}
+		}
+		nodes = next
+	}
+	if nodes == nil {
+		nodes = []interface{}{}
+	}
+	return nodes, nil
+}
+
+// descendants 返回节点自身及所有后代，先序遍历
+func descendants(node interface{}, result []interface{}) ([]interface{}, error) {
+	node, err := genericNode(node)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, node)
+	for _, child := range children(node) {
+		if result, err = descendants(child, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// children 返回容器节点的子节点，对象按键名排序
+func children(node interface{}) []interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		result := make([]interface{}, len(keys))
+		for i, k := range keys {
+			result[i] = value[k]
+		}
+		return result
+	case []interface{}:
+		return value
+	}
+	return nil
+}
+
+// apply 对单个节点应用选择器
+func (s pathSelector) apply(root, node interface{}) ([]interface{}, error) {
+	node, err := genericNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.kind {
+	case nameSelector:
+		if m, ok := node.(map[string]interface{}); ok {
+			if value, ok := m[s.name]; ok {
+				return []interface{}{value}, nil
+			}
+		}
+	case wildcardSelector:
+		return children(node), nil
+	case indexSelector:
+		if arr, ok := node.([]interface{}); ok {
+			index := s.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}, nil
+			}
+		}
+	case sliceSelector:
+		if arr, ok := node.([]interface{}); ok {
+			return sliceArray(arr, s.slice), nil
+		}
+	case filterSelector:
+		var result []interface{}
+		for _, child := range children(node) {
+			matched, err := s.filter.test(root, child)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				result = append(result, child)
+			}
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// sliceArray 按 [start:end:step] 取数组元素，语义与 RFC 9535 一致
+func sliceArray(arr []interface{}, bounds [3]*int) []interface{} {
+	n := len(arr)
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i < 0 {
+			return i + n
+		}
+		return i
+	}
+	var start, end int
+	if step > 0 {
+		start, end = 0, n
+		if bounds[0] != nil {
+			start = min(max(normalize(*bounds[0]), 0), n)
+		}
+		if bounds[1] != nil {
+			end = min(max(normalize(*bounds[1]), 0), n)
+		}
+	} else {
+		start, end = n-1, -1
+		if bounds[0] != nil {
+			start = min(max(normalize(*bounds[0]), -1), n-1)
+		}
+		if bounds[1] != nil {
+			end = min(max(normalize(*bounds[1]), -1), n-1)
+		}
+	}
+
+	var result []interface{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		result = append(result, arr[i])
+	}
+	return result
+}
+
+// filterExpr 过滤表达式
+type filterExpr interface {
+	test(root, current interface{}) (bool, error)
+}
+
+// logicalExpr && 或 || 表达式
+type logicalExpr struct {
+	and         bool
+	left, right filterExpr
+}
+
+func (e logicalExpr) test(root, current interface{}) (bool, error) {
+	left, err := e.left.test(root, current)
+	if err != nil || left != e.and {
+		return left, err
+	}
+	return e.right.test(root, current)
+}
+
+// notExpr ! 表达式
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e notExpr) test(root, current interface{}) (bool, error) {
+	result, err := e.expr.test(root, current)
+	return !result, err
+}
+
+// existsExpr 路径存在性判断
+type existsExpr struct {
+	path filterOperand
+}
+
+func (e existsExpr) test(root, current interface{}) (bool, error) {
+	values, err := e.path.values(root, current)
+	return len(values) > 0, err
+}
+
+// compareExpr 比较表达式
+type compareExpr struct {
+	op          string
+	left, right filterOperand
+}
+
+func (e compareExpr) test(root, current interface{}) (bool, error) {
+	left, err := e.left.values(root, current)
+	if err != nil {
+		return false, err
+	}
+	right, err := e.right.values(root, current)
+	if err != nil {
+		return false, err
+	}
+	// 操作数必须是单个值；两侧都不存在时视为相等
+	if len(left) != 1 || len(right) != 1 {
+		empty := len(left) == 0 && len(right) == 0
+		switch e.op {
+		case "==", "<=", ">=":
+			return empty, nil
+		case "!=":
+			return !empty, nil
+		}
+		return false, nil
+	}
+	return compareValues(e.op, left[0], right[0]), nil
+}
+
+// filterOperand 过滤表达式的操作数：字面量或以 @、$ 开头的路径
+type filterOperand struct {
+	literal  interface{}
+	relative bool // @ 开头
+	absolute bool // $ 开头
+	segments []pathSegment
+}
+
+func (o filterOperand) values(root, current interface{}) ([]interface{}, error) {
+	switch {
+	case o.relative:
+		return selectSegments(root, current, o.segments)
+	case o.absolute:
+		return selectSegments(root, root, o.segments)
+	}
+	return []interface{}{o.literal}, nil
+}
+
+// compareValues 比较两个值：数字按数值比较，字符串按字典序比较，其余类型只支持 == 和 !=
+func compareValues(op string, left, right interface{}) bool {
+	if l, ok := queryNumber(left); ok {
+		if r, ok := queryNumber(right); ok {
+			return compareOrdered(op, l, r)
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compareOrdered(op, l, r)
+		}
+	}
+
+	equal := reflect.DeepEqual(left, right)
+	switch op {
+	case "==", "<=", ">=":
+		return equal
+	case "!=":
+		return !equal
+	}
+	return false
+}
+
+// compareOrdered 比较可排序的值
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+// queryNumber 将数字转换为 float64
+func queryNumber(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// pathParser JSONPath 解析器
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid json path %q at offset %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *pathParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *pathParser) rest() string {
+	return p.src[p.pos:]
+}
+
+func (p *pathParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *pathParser) consume(c byte) bool {
+	if p.peek() == c && !p.done() {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) consumeString(s string) bool {
+	if strings.HasPrefix(p.rest(), s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) skipSpace() {
+	for !p.done() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// parseSegments 解析连续的 .name、..name 和 [...] 段
+func (p *pathParser) parseSegments() ([]pathSegment, error) {
+	var segments []pathSegment
+	for {
+		switch {
+		case p.consumeString(".."):
+			segment, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+			segment.descendant = true
+			segments = append(segments, segment)
+		case p.consume('.'):
+			segment, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		case p.peek() == '[':
+			segment, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		default:
+			return segments, nil
+		}
+	}
+}
+
+// parseDotSegment 解析 . 或 .. 之后的成员名、* 或 [...]
+func (p *pathParser) parseDotSegment() (pathSegment, error) {
+	if p.peek() == '[' {
+		return p.parseBracket()
+	}
+	if p.consume('*') {
+		return pathSegment{selectors: []pathSelector{{kind: wildcardSelector}}}, nil
+	}
+	start := p.pos
+	for !p.done() {
+		r, size := utf8.DecodeRuneInString(p.rest())
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return pathSegment{}, p.errorf("expected member name")
+	}
+	return pathSegment{selectors: []pathSelector{{kind: nameSelector, name: p.src[start:p.pos]}}}, nil
+}
+
+// parseBracket 解析 [...]，可包含以逗号分隔的多个选择器
+func (p *pathParser) parseBracket() (pathSegment, error) {
+	p.consume('[')
+	var segment pathSegment
+	for {
+		p.skipSpace()
+		selector, err := p.parseSelector()
+		if err != nil {
+			return pathSegment{}, err
+		}
+		segment.selectors = append(segment.selectors, selector)
+		p.skipSpace()
+		if p.consume(']') {
+			return segment, nil
+		}
+		if !p.consume(',') {
+			return pathSegment{}, p.errorf("expected , or ]")
+		}
+	}
+}
+
+// parseSelector 解析方括号中的单个选择器
+func (p *pathParser) parseSelector() (pathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseQuoted()
+		if err != nil {
+			return pathSelector{}, err
+		}
+		return pathSelector{kind: nameSelector, name: name}, nil
+	case c == '*':
+		p.pos++
+		return pathSelector{kind: wildcardSelector}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		filter, err := p.parseOr()
+		if err != nil {
+			return pathSelector{}, err
+		}
+		return pathSelector{kind: filterSelector, filter: filter}, nil
+	}
+
+	// 下标或切片
+	var bounds [3]*int
+	part := 0
+	for {
+		p.skipSpace()
+		if n, ok := p.parseInt(); ok {
+			bounds[part] = &n
+		}
+		p.skipSpace()
+		if p.peek() != ':' || part == 2 {
+			break
+		}
+		p.pos++
+		part++
+	}
+	if part == 0 {
+		if bounds[0] == nil {
+			return pathSelector{}, p.errorf("invalid selector")
+		}
+		return pathSelector{kind: indexSelector, index: *bounds[0]}, nil
+	}
+	return pathSelector{kind: sliceSelector, slice: bounds}, nil
+}
+
+// parseInt 解析可带负号的整数
+func (p *pathParser) parseInt() (int, bool) {
+	start := p.pos
+	p.consume('-')
+	for !p.done() && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return n, true
+}
+
+// parseQuoted 解析单引号或双引号字符串，支持反斜杠转义
+func (p *pathParser) parseQuoted() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for !p.done() {
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return sb.String(), nil
+		case '\\':
+			if p.done() {
+				return "", p.errorf("unterminated string")
+			}
+			escaped := p.src[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				sb.WriteByte(escaped)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseOr 解析 || 表达式
+func (p *pathParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consumeString("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: false, left: left, right: right}
+	}
+}
+
+// parseAnd 解析 && 表达式
+func (p *pathParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consumeString("&&") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+}
+
+// parseUnary 解析 !、括号和比较表达式
+func (p *pathParser) parseUnary() (filterExpr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && !strings.HasPrefix(p.rest(), "!=") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if p.consume('(') {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(')') {
+			return nil, p.errorf("expected )")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consumeString(op) {
+			p.skipSpace()
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	if !left.relative && !left.absolute {
+		return nil, p.errorf("literal must be compared")
+	}
+	return existsExpr{left}, nil
+}
+
+// parseOperand 解析路径或字面量
+func (p *pathParser) parseOperand() (filterOperand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return filterOperand{}, err
+		}
+		return filterOperand{relative: c == '@', absolute: c == '$', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseQuoted()
+		return filterOperand{literal: s}, err
+	case p.consumeString("true"):
+		return filterOperand{literal: true}, nil
+	case p.consumeString("false"):
+		return filterOperand{literal: false}, nil
+	case p.consumeString("null"):
+		return filterOperand{literal: nil}, nil
+	}
+
+	start := p.pos
+	for !p.done() && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return filterOperand{literal: n}, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return filterOperand{literal: f}, nil
+	}
+	p.pos = start
+	return filterOperand{}, p.errorf("expected operand")
+}
+
+// QueryPath 按 JSONPath 查询并将每个结果转换为类型 T
+func QueryPath[T any](doc interface{}, path string) ([]T, error) {
+	compiled, err := CompileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := compiled.Select(doc)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]T, len(values))
+	for i, value := range values {
+		if result[i], err = convertQueryResult[T](value); err != nil {
+			return nil, fmt.Errorf("result %d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+// QueryFirst 按 JSONPath 查询第一个结果并转换为类型 T，没有结果时返回包装了 ErrPathNotFound 的错误
+func QueryFirst[T any](doc interface{}, path string) (T, error) {
+	var zero T
+	compiled, err := CompileJSONPath(path)
+	if err != nil {
+		return zero, err
+	}
+	values, err := compiled.Select(doc)
+	if err != nil {
+		return zero, err
+	}
+	if len(values) == 0 {
+		return zero, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+	return convertQueryResult[T](values[0])
+}
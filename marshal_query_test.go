@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+/*
+JSON Pointer 与 JSONPath 查询测试
+
+运行命令：
+go test -v -run "^Test(JSONPointer|JSONPath|Query).*$"
+
+测试内容：
+1. JSON Pointer 解析、转义和取值（RFC 6901 示例）
+2. JSONPath 成员、下标、通配、切片、并集、递归下降
+3. JSONPath 过滤表达式
+4. 泛型结果转换，查询字节、通用结构和结构体
+5. HTTPResponse 查询
+*/
+
+var queryStore = []byte(`{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	},
+	"expensive": 10
+}`)
+
+func TestJSONPointer(t *testing.T) {
+	doc := []byte(`{"foo": ["bar", "baz"], "": 0, "a/b": 1, "c%d": 2, "e^f": 3, "g|h": 4, "i\\j": 5, "k\"l": 6, " ": 7, "m~n": 8}`)
+	cases := map[string]interface{}{
+		"/foo":    []interface{}{"bar", "baz"},
+		"/foo/0":  "bar",
+		"/":       int64(0),
+		"/a~1b":   int64(1),
+		"/c%d":    int64(2),
+		"/e^f":    int64(3),
+		"/g|h":    int64(4),
+		"/i\\j":   int64(5),
+		"/k\"l":   int64(6),
+		"/ ":      int64(7),
+		"/m~0n":   int64(8),
+		"#/c%25d": int64(2),
+		"#/a~1b":  int64(1),
+	}
+	for pointer, expected := range cases {
+		p, err := ParseJSONPointer(pointer)
+		if err != nil {
+			t.Errorf("ParseJSONPointer(%q) failed: %v", pointer, err)
+			continue
+		}
+		value, err := p.Get(doc)
+		if err != nil || !reflect.DeepEqual(value, expected) {
+			t.Errorf("Get(%q) = %#v, %v, want %#v", pointer, value, err, expected)
+		}
+	}
+
+	if p, _ := ParseJSONPointer("/m~0n/a~1b"); p.String() != "/m~0n/a~1b" || p[0] != "m~n" || p[1] != "a/b" {
+		t.Errorf("Unexpected pointer: %q %v", p.String(), []string(p))
+	}
+	for _, invalid := range []string{"foo", "/a~2", "/a~"} {
+		if _, err := ParseJSONPointer(invalid); err == nil {
+			t.Errorf("ParseJSONPointer(%q) should fail", invalid)
+		}
+	}
+	for _, missing := range []string{"/missing", "/foo/2", "/foo/01", "/foo/-", "/foo/0/x"} {
+		if _, err := GetPointer[interface{}](doc, missing); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("GetPointer(%q) error = %v, want ErrPathNotFound", missing, err)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"$.store.book[0].author", []interface{}{"Nigel Rees"}},
+		{"$['store']['bicycle']['color']", []interface{}{"red"}},
+		{"$.store.book[-1].title", []interface{}{"The Lord of the Rings"}},
+		{"$.store.book[*].author", []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{"$.store.book[0,2].price", []interface{}{8.95, 8.99}},
+		{"$.store.book[1:3].title", []interface{}{"Sword of Honour", "Moby Dick"}},
+		{"$.store.book[::-2].title", []interface{}{"The Lord of the Rings", "Sword of Honour"}},
+		{"$.store.book[:1].title", []interface{}{"Sayings of the Century"}},
+		{"$..isbn", []interface{}{"0-553-21311-3", "0-395-19395-8"}},
+		{"$.store.*.color", []interface{}{"red"}},
+		{"$..book[?(@.isbn)].title", []interface{}{"Moby Dick", "The Lord of the Rings"}},
+		{"$..book[?(@.price < 10)].title", []interface{}{"Sayings of the Century", "Moby Dick"}},
+		{"$..book[?@.price > $.expensive && @.category == 'fiction'].title", []interface{}{"Sword of Honour", "The Lord of the Rings"}},
+		{"$..book[?(!@.isbn || @.author == \"Herman Melville\")].price", []interface{}{8.95, 12.99, 8.99}},
+		{"$.store.book[?(@.price >= 22.99)].author", []interface{}{"J. R. R. Tolkien"}},
+		{"$.store.book[?(@.missing == null)].title", []interface{}{}},
+		{"$.store.bicycle.price", []interface{}{int64(399)}},
+		{"$.missing", []interface{}{}},
+	}
+	for _, c := range cases {
+		result, err := QueryPath[interface{}](queryStore, c.path)
+		if err != nil {
+			t.Errorf("QueryPath(%q) failed: %v", c.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("QueryPath(%q) = %#v, want %#v", c.path, result, c.expected)
+		}
+	}
+
+	for _, invalid := range []string{"store", "$.", "$[", "$[?(@.a ==)]", "$['a'", "$.a b"} {
+		if _, err := CompileJSONPath(invalid); err == nil {
+			t.Errorf("CompileJSONPath(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestQueryTyped(t *testing.T) {
+	type book struct {
+		Author string  `json:"author"`
+		Price  float64 `json:"price"`
+	}
+
+	books, err := QueryPath[book](queryStore, "$.store.book[?(@.category == 'fiction')]")
+	if err != nil || len(books) != 3 || books[0].Author != "Evelyn Waugh" {
+		t.Errorf("QueryPath[book] = %+v, %v", books, err)
+	}
+
+	price, err := GetPointer[int](queryStore, "/store/bicycle/price")
+	if err != nil || price != 399 {
+		t.Errorf("GetPointer[int] = %d, %v", price, err)
+	}
+
+	first, err := QueryFirst[string](queryStore, "$..author")
+	if err != nil || first != "Nigel Rees" {
+		t.Errorf("QueryFirst = %q, %v", first, err)
+	}
+	if _, err := QueryFirst[string](queryStore, "$.nothing"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("QueryFirst error = %v, want ErrPathNotFound", err)
+	}
+	if _, err := GetPointer[int](queryStore, "/store/bicycle/color"); err == nil {
+		t.Error("Converting string to int should fail")
+	}
+
+	// 超出 int64 的整数保留精度
+	if id, err := GetPointer[uint64]([]byte(`{"id": 18446744073709551615}`), "/id"); err != nil || id != math.MaxUint64 {
+		t.Errorf("GetPointer[uint64] = %d, %v", id, err)
+	}
+	big, err := GetPointer[json.Number]([]byte(`{"id": 123456789012345678901234567890}`), "/id")
+	if err != nil || big.String() != "123456789012345678901234567890" {
+		t.Errorf("GetPointer[json.Number] = %s, %v", big, err)
+	}
+
+	// 通用结构和结构体
+	tree := map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}}}
+	ids, err := QueryPath[int](tree, "$.items[*].id")
+	if err != nil || !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("QueryPath on tree = %v, %v", ids, err)
+	}
+	author, err := GetPointer[string](struct {
+		Books []book `json:"books"`
+	}{Books: []book{{Author: "A"}}}, "/books/0/author")
+	if err != nil || author != "A" {
+		t.Errorf("GetPointer on struct = %q, %v", author, err)
+	}
+}
+
+func TestQueryHTTPResponse(t *testing.T) {
+	resp := &HTTPResponse{StatusCode: 200, Body: queryStore}
+	value, err := resp.Pointer("/store/bicycle/color")
+	if err != nil || value != "red" {
+		t.Errorf("Pointer = %v, %v", value, err)
+	}
+	titles, err := resp.Query("$.store.book[?(@.price < 9)].title")
+	if err != nil || len(titles) != 2 {
+		t.Errorf("Query = %v, %v", titles, err)
+	}
+
+	failed := &HTTPResponse{Error: errors.New("request failed")}
+	if _, err := failed.Query("$"); err == nil {
+		t.Error("Query should return response error")
+	}
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// DefaultRedactKeys 常见敏感键名模式，可用于 SetRedactKeys(DefaultRedactKeys...)
+var DefaultRedactKeys = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*api_key*",
+	"*apikey*",
+	"authorization",
+	"cookie",
+}
+
+// defaultRedactMask RedactMask 为空时使用的替换文本
+const defaultRedactMask = "******"
+
+// maskRule 脱敏规则，keep 为保留的字符数，keepLast 表示保留末尾字符
+type maskRule struct {
+	keep     int
+	keepLast bool
+}
+
+// fullMask 完全替换
+var fullMask = maskRule{}
+
+// parseMaskRule 解析字段标签中的脱敏规则
+// redact:"true" 完全替换；mask:"full"、mask:"last4"、mask:"first2" 完全替换或保留首尾若干字符，无法识别的规则按完全替换处理
+func parseMaskRule(tag reflect.StructTag) (maskRule, bool) {
+	if spec, ok := tag.Lookup("mask"); ok {
+		spec = strings.ToLower(strings.TrimSpace(spec))
+		for prefix, keepLast := range map[string]bool{"last": true, "first": false} {
+			if digits, ok := strings.CutPrefix(spec, prefix); ok {
+				if n, err := strconv.Atoi(digits); err == nil && n > 0 {
+					return maskRule{keep: n, keepLast: keepLast}, true
+				}
+			}
+		}
+		return fullMask, true
+	}
+	if redact, err := strconv.ParseBool(tag.Get("redact")); err == nil && redact {
+		return fullMask, true
+	}
+	return maskRule{}, false
+}
+
+// redactEnabled 是否需要脱敏
+func (m *MarshalExt) redactEnabled() bool {
+	return m.options.Redact || len(m.options.RedactKeys) > 0
+}
+
+// redact 返回脱敏后的副本，类型与输入相同，不修改输入
+func (m *MarshalExt) redact(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return v, nil
+	}
+	redacted, err := m.redactValue(rv, 0)
+	if err != nil {
+		return nil, fmt.Errorf("redact error: %w", err)
+	}
+	return redacted.Interface(), nil
+}
+
+// redactValue 递归复制值，按标签和键名替换敏感值
+func (m *MarshalExt) redactValue(v reflect.Value, depth int) (reflect.Value, error) {
+	if depth > binaryMaxDepth {
+		return reflect.Value{}, ErrMaxDepthExceeded
+	}
+
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := m.redactValue(v.Elem(), depth+1)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		result := reflect.New(t.Elem())
+		result.Elem().Set(elem)
+		return result, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := m.redactValue(v.Elem(), depth+1)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		result := reflect.New(t).Elem()
+		result.Set(elem)
+		return result, nil
+	case reflect.Struct:
+		if t == timeType {
+			return v, nil
+		}
+		// 先整体复制以保留未导出字段，再替换导出字段
+		result := reflect.New(t).Elem()
+		result.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			field := result.Field(i)
+			if !f.IsExported() {
+				// 与 encoding/json 一致，未导出的嵌入结构体的导出字段同样会被输出，需要递归脱敏
+				if !f.Anonymous || f.Type.Kind() != reflect.Struct {
+					continue
+				}
+				field = reflect.NewAt(f.Type, unsafe.Pointer(field.UnsafeAddr())).Elem()
+			}
+			if rule, ok := parseMaskRule(f.Tag); ok {
+				field.Set(m.maskValue(field, rule))
+				continue
+			}
+			redacted, err := m.redactValue(field, depth+1)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			field.Set(redacted)
+		}
+		return result, nil
+	case reflect.Slice:
+		if v.IsNil() || t.Elem().Kind() == reflect.Uint8 {
+			return v, nil
+		}
+		result := reflect.MakeSlice(t, v.Len(), v.Len())
+		return result, m.redactElements(result, v, depth)
+	case reflect.Array:
+		result := reflect.New(t).Elem()
+		return result, m.redactElements(result, v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		result := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			if key.Kind() == reflect.String && m.isRedactKey(key.String()) {
+				result.SetMapIndex(key, m.maskValue(value, fullMask))
+				continue
+			}
+			redacted, err := m.redactValue(value, depth+1)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.SetMapIndex(key, redacted)
+		}
+		return result, nil
+	}
+	return v, nil
+}
+
+// redactElements 将 src 的元素脱敏后写入 dst
+func (m *MarshalExt) redactElements(dst, src reflect.Value, depth int) error {
+	for i := 0; i < src.Len(); i++ {
+		redacted, err := m.redactValue(src.Index(i), depth+1)
+		if err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+		dst.Index(i).Set(redacted)
+	}
+	return nil
+}
+
+// isRedactKey 判断键名是否匹配 RedactKeys 中的模式
+func (m *MarshalExt) isRedactKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range m.options.RedactKeys {
+		pattern = strings.ToLower(pattern)
+		if matched, err := path.Match(pattern, key); matched || (err != nil && pattern == key) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskValue 返回替换后的值：字符串和 []byte 替换为掩码，interface{} 替换为掩码字符串，其他类型置零，nil 保持不变
+func (m *MarshalExt) maskValue(v reflect.Value, rule maskRule) reflect.Value {
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		result := reflect.New(t.Elem())
+		result.Elem().Set(m.maskValue(v.Elem(), rule))
+		return result
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		masked := reflect.ValueOf(m.maskString(fmt.Sprint(v.Elem().Interface()), rule))
+		if !masked.Type().AssignableTo(t) {
+			return reflect.Zero(t)
+		}
+		result := reflect.New(t).Elem()
+		result.Set(masked)
+		return result
+	case reflect.String:
+		return reflect.ValueOf(m.maskString(v.String(), rule)).Convert(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !v.IsNil() {
+			return reflect.ValueOf([]byte(m.maskString(string(v.Bytes()), rule))).Convert(t)
+		}
+	}
+	return reflect.Zero(t)
+}
+
+// maskString 按规则替换字符串，保留的字符数不少于原字符串长度时完全替换
+func (m *MarshalExt) maskString(s string, rule maskRule) string {
+	mask := m.options.RedactMask
+	if mask == "" {
+		mask = defaultRedactMask
+	}
+
+	n := utf8.RuneCountInString(s)
+	if rule.keep <= 0 || rule.keep >= n {
+		return mask
+	}
+	runes := []rune(s)
+	if rule.keepLast {
+		return mask + string(runes[n-rule.keep:])
+	}
+	return string(runes[:rule.keep]) + mask
+}
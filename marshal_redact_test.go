@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+/*
+脱敏测试
+
+运行命令：
+go test -v -run "^TestRedact.*$"
+
+测试内容：
+1. redact/mask 标签脱敏结构体字段
+2. 按键名模式脱敏 map
+3. JSON、YAML、XML 输出均被脱敏
+4. 不修改输入
+5. 未启用时原样输出
+6. 未导出的嵌入结构体中的字段同样被脱敏
+*/
+
+type redactCredentials struct {
+	User     string `json:"user" yaml:"user" xml:"user"`
+	Password string `json:"password" yaml:"password" xml:"password" redact:"true"`
+	Card     string `json:"card" yaml:"card" xml:"card" mask:"last4"`
+	PIN      int    `json:"pin" yaml:"pin" xml:"pin" redact:"true"`
+	Token    *string
+}
+
+type redactRequest struct {
+	Credentials redactCredentials      `json:"credentials" yaml:"credentials" xml:"credentials"`
+	Headers     map[string]string      `json:"headers" yaml:"headers" xml:"-"`
+	Extra       map[string]interface{} `json:"extra" yaml:"extra" xml:"-"`
+	History     []redactCredentials    `json:"history" yaml:"history" xml:"history"`
+}
+
+func newRedactRequest() *redactRequest {
+	token := "tok-123"
+	creds := redactCredentials{
+		User:     "alice",
+		Password: "s3cret",
+		Card:     "4111111111111111",
+		PIN:      1234,
+		Token:    &token,
+	}
+	return &redactRequest{
+		Credentials: creds,
+		Headers:     map[string]string{"Authorization": "Bearer abc", "Accept": "application/json"},
+		Extra:       map[string]interface{}{"api_key": 42, "nested": map[string]interface{}{"db_password": "pw"}},
+		History:     []redactCredentials{creds},
+	}
+}
+
+func TestRedactTags(t *testing.T) {
+	m := DefaultMarshalExt().SetRedact(true)
+	req := newRedactRequest()
+
+	out := m.MustToJSON(req.Credentials)
+	expected := `{"user":"alice","password":"******","card":"******1111","pin":0,"Token":"tok-123"}`
+	if out != expected {
+		t.Errorf("JSON = %s, want %s", out, expected)
+	}
+
+	out = m.MustToJSON(req)
+	if strings.Contains(out, "s3cret") || strings.Contains(out, "4111111111111111") {
+		t.Errorf("Nested fields should be redacted: %s", out)
+	}
+	if !strings.Contains(out, "Bearer abc") {
+		t.Errorf("Map keys should not be redacted without RedactKeys: %s", out)
+	}
+
+	if req.Credentials.Password != "s3cret" || req.History[0].Card != "4111111111111111" || *req.Credentials.Token != "tok-123" {
+		t.Error("Input should not be modified")
+	}
+
+	plain := DefaultMarshalExt().MustToJSON(req.Credentials)
+	if !strings.Contains(plain, "s3cret") {
+		t.Errorf("Redaction should be disabled by default: %s", plain)
+	}
+}
+
+func TestRedactEmbedded(t *testing.T) {
+	type secret struct {
+		Password string `json:"password" redact:"true"`
+		Nested   map[string]string
+	}
+	type account struct {
+		secret
+		User string `json:"user"`
+	}
+	value := account{secret: secret{Password: "hunter2", Nested: map[string]string{"token": "t"}}, User: "bob"}
+
+	out, err := DefaultMarshalExt().SetRedact(true).SetRedactKeys("token").MarshalToString(value)
+	if err != nil || out != `{"password":"******","Nested":{"token":"******"},"user":"bob"}` {
+		t.Errorf("Embedded = %s, %v", out, err)
+	}
+	if value.Password != "hunter2" || value.Nested["token"] != "t" {
+		t.Error("Input should not be modified")
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	m := DefaultMarshalExt().SetRedactKeys(DefaultRedactKeys...).SetRedactMask("[REDACTED]")
+	req := newRedactRequest()
+
+	out := m.MustToJSON(req)
+	for _, secret := range []string{"Bearer abc", `"api_key":42`, `"pw"`} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Output should not contain %s: %s", secret, out)
+		}
+	}
+	for _, expected := range []string{`"Authorization":"[REDACTED]"`, `"api_key":"[REDACTED]"`, `"db_password":"[REDACTED]"`, `"Accept":"application/json"`} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Output should contain %s: %s", expected, out)
+		}
+	}
+	if req.Headers["Authorization"] != "Bearer abc" || req.Extra["api_key"] != 42 {
+		t.Error("Input maps should not be modified")
+	}
+}
+
+func TestRedactFormats(t *testing.T) {
+	m := DefaultMarshalExt().SetRedact(true).SetRedactKeys("authorization")
+	req := newRedactRequest()
+
+	yamlOut := m.MustToYAML(req)
+	if strings.Contains(yamlOut, "s3cret") || strings.Contains(yamlOut, "Bearer abc") {
+		t.Errorf("YAML should be redacted: %s", yamlOut)
+	}
+	if !strings.Contains(yamlOut, "card: '******1111'") {
+		t.Errorf("YAML should keep last 4 digits: %s", yamlOut)
+	}
+
+	xmlOut := m.MustToXML(req)
+	if strings.Contains(xmlOut, "s3cret") || !strings.Contains(xmlOut, "<password>******</password>") {
+		t.Errorf("XML should be redacted: %s", xmlOut)
+	}
+
+	mapOut := m.MustToXML(map[string]interface{}{"Authorization": "Bearer abc", "user": "alice"})
+	if strings.Contains(mapOut, "Bearer abc") {
+		t.Errorf("XML map should be redacted: %s", mapOut)
+	}
+}
+
+func TestRedactMaskRules(t *testing.T) {
+	type masked struct {
+		First string  `json:"first" mask:"first2"`
+		Short string  `json:"short" mask:"last4"`
+		Full  string  `json:"full" mask:"full"`
+		Bytes []byte  `json:"bytes" redact:"true"`
+		Nil   *string `json:"nil" redact:"true"`
+		Off   string  `json:"off" redact:"false"`
+	}
+	out := DefaultMarshalExt().SetRedact(true).SetRedactMask("***").MustToJSON(masked{
+		First: "张三丰",
+		Short: "123",
+		Full:  "value",
+		Bytes: []byte("raw"),
+		Off:   "visible",
+	})
+	expected := `{"first":"张三***","short":"***","full":"***","bytes":"Kioq","nil":null,"off":"visible"}`
+	if out != expected {
+		t.Errorf("JSON = %s, want %s", out, expected)
+	}
+}
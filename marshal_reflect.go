@@ -0,0 +1,488 @@
+package utils
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 文本类格式（CSV、INI 等）共用的反射工具
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structField 结构体字段及其键名
+type structField struct {
+	name      string // 键名
+	index     []int  // 字段索引
+	omitEmpty bool   // 是否为 omitempty
+}
+
+// structFields 返回结构体的导出字段，键名依次取 tagName 标签、json 标签、字段名
+// 标签为 "-" 的字段被忽略，匿名结构体字段被展开
+func structFields(t reflect.Type, tagName string) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// 与 encoding/json 一致，未导出的嵌入结构体的导出字段同样被提升
+		if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup(tagName)
+		if !ok {
+			tag = f.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				for _, sub := range structFields(ft, tagName) {
+					sub.index = append([]int{i}, sub.index...)
+					fields = append(fields, sub)
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// fieldByIndex 按索引获取字段，alloc 为 true 时为 nil 的嵌入指针分配内存
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isScalarType 判断类型是否可以表示为单个字符串
+func isScalarType(t reflect.Type) bool {
+	if t == timeType || t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		return isScalarType(t.Elem())
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatScalar 将标量值格式化为字符串
+func formatScalar(v reflect.Value) (string, error) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// setScalar 将字符串解析到标量值
+func setScalar(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setScalar(v.Elem(), s)
+	}
+
+	if v.Type() == timeType {
+		if s == "" {
+			v.Set(reflect.Zero(timeType))
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(s))
+		return nil
+	}
+
+	// 数值和布尔类型的空字符串视为零值
+	if s == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// assignGeneric 将解码出的通用值（map[string]interface{}、[]interface{}、int64、uint64、float64、
+// string、[]byte、bool、time.Time、nil）写入 dst，结构体字段按 tagName 标签匹配，大小写不敏感
+func assignGeneric(dst reflect.Value, src interface{}, tagName string) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignGeneric(dst.Elem(), src, tagName)
+	}
+
+	sv := reflect.ValueOf(src)
+	if dst.Kind() == reflect.Interface {
+		if !sv.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+		}
+		dst.Set(sv)
+		return nil
+	}
+	if dst.Type() == timeType {
+		switch value := src.(type) {
+		case time.Time:
+			dst.Set(sv)
+			return nil
+		case string:
+			return setScalar(dst, value)
+		}
+		return fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+	}
+	if s, ok := src.(string); ok && dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch dst.Kind() {
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := genericInt(src); ok {
+			if dst.OverflowInt(n) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := genericUint(src); ok {
+			if dst.OverflowUint(n) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := genericFloat(src); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+			return nil
+		case []byte:
+			dst.SetString(string(s))
+			return nil
+		}
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch b := src.(type) {
+			case []byte:
+				dst.SetBytes(append([]byte(nil), b...))
+				return nil
+			case string:
+				dst.SetBytes([]byte(b))
+				return nil
+			}
+		}
+		if items, ok := src.([]interface{}); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+			for i, item := range items {
+				if err := assignGeneric(slice.Index(i), item, tagName); err != nil {
+					return fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+	case reflect.Array:
+		if b, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(dst, reflect.ValueOf(b))
+			return nil
+		}
+		if items, ok := src.([]interface{}); ok {
+			dst.Set(reflect.Zero(dst.Type()))
+			for i := 0; i < len(items) && i < dst.Len(); i++ {
+				if err := assignGeneric(dst.Index(i), items[i], tagName); err != nil {
+					return fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if values, ok := src.(map[string]interface{}); ok {
+			result := reflect.MakeMapWithSize(dst.Type(), len(values))
+			for k, item := range values {
+				key := reflect.New(dst.Type().Key()).Elem()
+				if err := setScalar(key, k); err != nil {
+					return fmt.Errorf("key %s: %w", k, err)
+				}
+				value := reflect.New(dst.Type().Elem()).Elem()
+				if err := assignGeneric(value, item, tagName); err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+				result.SetMapIndex(key, value)
+			}
+			dst.Set(result)
+			return nil
+		}
+	case reflect.Struct:
+		if values, ok := src.(map[string]interface{}); ok {
+			fields := structFields(dst.Type(), tagName)
+			for k, item := range values {
+				f := findStructField(fields, k)
+				if f == nil {
+					continue
+				}
+				field, _ := fieldByIndex(dst, f.index, true)
+				if err := assignGeneric(field, item, tagName); err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot decode %T into %s", src, dst.Type())
+}
+
+// findStructField 按键名查找字段，优先精确匹配，其次大小写不敏感匹配
+func findStructField(fields []structField, name string) *structField {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// genericInt 将通用数值转换为 int64
+func genericInt(src interface{}) (int64, bool) {
+	switch n := src.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64
+	}
+	return 0, false
+}
+
+// genericUint 将通用数值转换为 uint64
+func genericUint(src interface{}) (uint64, bool) {
+	switch n := src.(type) {
+	case int64:
+		return uint64(n), n >= 0
+	case uint64:
+		return n, true
+	case float64:
+		return uint64(n), n == math.Trunc(n) && n >= 0 && n < math.MaxUint64
+	}
+	return 0, false
+}
+
+// genericFloat 将通用数值转换为 float64
+func genericFloat(src interface{}) (float64, bool) {
+	switch n := src.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// copyKey 深拷贝中已复制指针的标识
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// deepCopyValue 深拷贝值：指针、切片、map 和接口指向新的副本，同一指针只复制一次（支持循环引用）
+// 结构体先整体按值复制，再替换可设置的导出字段，未导出字段中的引用仍与原值共享
+func deepCopyValue(v reflect.Value, seen map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Pointer(), v.Type()}
+		if copied, ok := seen[key]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		seen[key] = copied
+		copied.Elem().Set(deepCopyValue(v.Elem(), seen))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopyValue(v.Elem(), seen))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copyElements(copied, v, seen)
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		copyElements(copied, v, seen)
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(deepCopyValue(iter.Key(), seen), deepCopyValue(iter.Value(), seen))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(deepCopyValue(v.Field(i), seen))
+			}
+		}
+		return copied
+	}
+	return v
+}
+
+// copyElements 复制切片或数组的元素，元素不含引用时直接整体复制
+func copyElements(dst, src reflect.Value, seen map[copyKey]reflect.Value) {
+	switch src.Type().Elem().Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		reflect.Copy(dst, src)
+		return
+	}
+	for i := 0; i < src.Len(); i++ {
+		dst.Index(i).Set(deepCopyValue(src.Index(i), seen))
+	}
+}
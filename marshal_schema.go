@@ -0,0 +1,809 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// JSON Schema (draft 2020-12) 子集：type、enum、minimum/maximum、exclusiveMinimum/exclusiveMaximum、
+// minLength/maxLength、pattern、items、minItems/maxItems、uniqueItems、properties、required、
+// additionalProperties、anyOf、$defs 和文档内的 $ref；format、title、description 仅作为注解保留
+// pattern 使用 Go 的 RE2 语法，不支持回溯引用等 ECMA 262 特性
+
+// ErrSchemaValidation 输入不满足 JSON Schema
+var ErrSchemaValidation = errors.New("schema validation failed")
+
+// maxSchemaRefDepth 未进入子值时连续解析 $ref 的最大次数，超过视为循环引用
+const maxSchemaRefDepth = 32
+
+// SchemaTypes 类型约束，JSON 中为单个字符串或字符串数组
+type SchemaTypes []string
+
+// UnmarshalJSON 支持 "string" 和 ["string", "null"] 两种形式
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = multiple
+	return nil
+}
+
+// MarshalJSON 只有一个类型时编码为字符串
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// JSONSchema JSON Schema 文档，使用 ParseJSONSchema 解析或 GenerateJSONSchema 从结构体生成
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	ID          string                 `json:"$id,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"` // 文档内引用，如 "#/$defs/address"
+	Defs        map[string]*JSONSchema `json:"$defs,omitempty"`
+	Definitions map[string]*JSONSchema `json:"definitions,omitempty"` // 旧版本的 $defs
+
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Format      string `json:"format,omitempty"`
+
+	Type  SchemaTypes   `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	Items       *JSONSchema `json:"items,omitempty"`
+	MinItems    *int        `json:"minItems,omitempty"`
+	MaxItems    *int        `json:"maxItems,omitempty"`
+	UniqueItems bool        `json:"uniqueItems,omitempty"`
+
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+
+	boolean *bool // 布尔 schema：true 接受任意值，false 拒绝任意值
+}
+
+// jsonSchemaFields 用于编解码 JSONSchema 的字段，避免递归调用自定义方法
+type jsonSchemaFields JSONSchema
+
+// UnmarshalJSON 解析 schema，支持布尔 schema，enum 中的数字解码为 int64 或 float64
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true", "false":
+		b := bytes.Equal(bytes.TrimSpace(data), []byte("true"))
+		*s = JSONSchema{boolean: &b}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields jsonSchemaFields
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+	for i, item := range fields.Enum {
+		fields.Enum[i] = normalizeGeneric(item)
+	}
+	*s = JSONSchema(fields)
+	return nil
+}
+
+// MarshalJSON 编码 schema，布尔 schema 编码为 true 或 false
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return json.Marshal(*s.boolean)
+	}
+	return json.Marshal(jsonSchemaFields(s))
+}
+
+// ParseJSONSchema 解析 JSON 格式的 schema，并检查 pattern 和 $ref 是否有效
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var schema JSONSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("schema parse error: %w", err)
+	}
+	if err := schema.Check(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// MustParseJSONSchema 解析 schema，出错时 panic，适用于包级变量初始化
+func MustParseJSONSchema(data []byte) *JSONSchema {
+	schema, err := ParseJSONSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// String 返回 schema 的 JSON 形式
+func (s *JSONSchema) String() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Check 检查 schema 自身是否有效：类型名、pattern 正则和文档内 $ref
+func (s *JSONSchema) Check() error {
+	if err := s.check(s, JSONPointer{}); err != nil {
+		return fmt.Errorf("schema error: %w", err)
+	}
+	return nil
+}
+
+// check 递归检查子 schema，location 为子 schema 在 schema 文档中的位置
+func (s *JSONSchema) check(root *JSONSchema, location JSONPointer) error {
+	if s == nil || s.boolean != nil {
+		return nil
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "null", "boolean", "integer", "number", "string", "array", "object":
+		default:
+			return fmt.Errorf("%s: unknown type %q", location, t)
+		}
+	}
+	if s.Pattern != "" {
+		if _, err := schemaPattern(s.Pattern); err != nil {
+			return fmt.Errorf("%s: %w", location, err)
+		}
+	}
+	if s.Ref != "" {
+		if _, err := root.resolveRef(s.Ref); err != nil {
+			return fmt.Errorf("%s: %w", location, err)
+		}
+	}
+
+	for _, group := range []struct {
+		keyword string
+		schemas map[string]*JSONSchema
+	}{{"$defs", s.Defs}, {"definitions", s.Definitions}, {"properties", s.Properties}} {
+		for _, name := range sortedSchemaKeys(group.schemas) {
+			if err := group.schemas[name].check(root, location.Append(group.keyword, name)); err != nil {
+				return err
+			}
+		}
+	}
+	for i, item := range s.AnyOf {
+		if err := item.check(root, location.Append("anyOf", strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	if err := s.Items.check(root, location.Append("items")); err != nil {
+		return err
+	}
+	return s.AdditionalProperties.check(root, location.Append("additionalProperties"))
+}
+
+// resolveRef 在 schema 文档内解析 $ref，支持 $defs、definitions、properties、items 和 additionalProperties 路径
+func (s *JSONSchema) resolveRef(ref string) (*JSONSchema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the document are supported", ref)
+	}
+	pointer, err := ParseJSONPointer(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	node := s
+	for i := 0; i < len(pointer) && node != nil; i++ {
+		switch pointer[i] {
+		case "$defs", "definitions", "properties":
+			if i+1 >= len(pointer) {
+				return nil, fmt.Errorf("invalid $ref %q", ref)
+			}
+			schemas := map[string]map[string]*JSONSchema{"$defs": node.Defs, "definitions": node.Definitions, "properties": node.Properties}
+			node = schemas[pointer[i]][pointer[i+1]]
+			i++
+		case "items":
+			node = node.Items
+		case "additionalProperties":
+			node = node.AdditionalProperties
+		default:
+			return nil, fmt.Errorf("unsupported $ref %q", ref)
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("unresolved $ref %q", ref)
+	}
+	return node, nil
+}
+
+// schemaPatterns 已编译的 pattern 缓存
+var schemaPatterns sync.Map
+
+// schemaPattern 编译 pattern，结果会被缓存
+func schemaPattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := schemaPatterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	schemaPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// sortedSchemaKeys 返回排序后的键名
+func sortedSchemaKeys(schemas map[string]*JSONSchema) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SchemaViolation 单条校验失败
+type SchemaViolation struct {
+	Path    string // 失败值在输入中的 JSON Pointer 位置，空字符串表示根
+	Keyword string // 失败的关键字，如 type、required、minimum
+	Message string
+}
+
+// String 返回可读形式，如 "/age: must be >= 0"
+func (v SchemaViolation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + v.Message
+}
+
+// SchemaError 校验失败，包含全部违规项，可用 errors.Is(err, ErrSchemaValidation) 判断
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error 返回全部违规项
+func (e *SchemaError) Error() string {
+	items := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		items[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrSchemaValidation, strings.Join(items, "; "))
+}
+
+// Is 使 errors.Is(err, ErrSchemaValidation) 成立
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaValidation
+}
+
+// Validate 校验文档，文档形式同 JSONPointer.Get；不满足时返回包含全部违规项的 *SchemaError
+func (s *JSONSchema) Validate(doc interface{}) error {
+	value, err := queryDocument(doc)
+	if err != nil {
+		return err
+	}
+	v := &schemaValidator{root: s}
+	v.validate(s, value, JSONPointer{}, 0)
+	if len(v.violations) > 0 {
+		return &SchemaError{Violations: v.violations}
+	}
+	return nil
+}
+
+// schemaValidator 校验状态，收集全部违规项
+type schemaValidator struct {
+	root       *JSONSchema
+	violations []SchemaViolation
+}
+
+// fail 记录一条违规
+func (v *schemaValidator) fail(path JSONPointer, keyword, format string, args ...interface{}) {
+	v.violations = append(v.violations, SchemaViolation{Path: path.String(), Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate 按 schema 校验值，refs 为未进入子值时连续解析 $ref 的次数
+func (v *schemaValidator) validate(s *JSONSchema, value interface{}, path JSONPointer, refs int) {
+	if s == nil {
+		return
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			v.fail(path, "false", "value is not allowed")
+		}
+		return
+	}
+
+	if s.Ref != "" {
+		target, err := v.root.resolveRef(s.Ref)
+		switch {
+		case err != nil:
+			v.fail(path, "$ref", "%v", err)
+		case refs >= maxSchemaRefDepth:
+			v.fail(path, "$ref", "circular $ref %q", s.Ref)
+		default:
+			v.validate(target, value, path, refs+1)
+		}
+	}
+
+	if len(s.AnyOf) > 0 && !v.matchesAny(s.AnyOf, value, path, refs) {
+		v.fail(path, "anyOf", "must match at least one schema in anyOf")
+	}
+
+	valueType := schemaTypeOf(value)
+	if len(s.Type) > 0 && !schemaTypeMatches(s.Type, value, valueType) {
+		v.fail(path, "type", "expected %s, got %s", strings.Join(s.Type, " or "), valueType)
+		return
+	}
+	if len(s.Enum) > 0 && !schemaEnumContains(s.Enum, value) {
+		v.fail(path, "enum", "must be one of %s", formatDiffValue(s.Enum))
+	}
+
+	switch valueType {
+	case "integer", "number":
+		n, _ := queryNumber(value)
+		v.validateNumber(s, n, path)
+	case "string":
+		v.validateString(s, value.(string), path)
+	case "array":
+		v.validateArray(s, value.([]interface{}), path)
+	case "object":
+		v.validateObject(s, value.(map[string]interface{}), path)
+	}
+}
+
+// matchesAny 判断值是否满足任一子 schema，子 schema 的违规项不计入结果
+func (v *schemaValidator) matchesAny(schemas []*JSONSchema, value interface{}, path JSONPointer, refs int) bool {
+	for _, s := range schemas {
+		sub := &schemaValidator{root: v.root}
+		sub.validate(s, value, path, refs)
+		if len(sub.violations) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNumber 校验数值范围
+func (v *schemaValidator) validateNumber(s *JSONSchema, n float64, path JSONPointer) {
+	if s.Minimum != nil && n < *s.Minimum {
+		v.fail(path, "minimum", "must be >= %v", *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		v.fail(path, "maximum", "must be <= %v", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		v.fail(path, "exclusiveMinimum", "must be > %v", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		v.fail(path, "exclusiveMaximum", "must be < %v", *s.ExclusiveMaximum)
+	}
+}
+
+// validateString 校验字符串长度（按字符计）和 pattern
+func (v *schemaValidator) validateString(s *JSONSchema, str string, path JSONPointer) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(path, "minLength", "length must be >= %d", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, "maxLength", "length must be <= %d", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := schemaPattern(s.Pattern)
+		switch {
+		case err != nil:
+			v.fail(path, "pattern", "%v", err)
+		case !re.MatchString(str):
+			v.fail(path, "pattern", "must match pattern %q", s.Pattern)
+		}
+	}
+}
+
+// validateArray 校验数组长度、唯一性和元素
+func (v *schemaValidator) validateArray(s *JSONSchema, arr []interface{}, path JSONPointer) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		v.fail(path, "minItems", "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		v.fail(path, "maxItems", "must have at most %d items", *s.MaxItems)
+	}
+	if s.UniqueItems {
+	unique:
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if genericEqual(arr[i], arr[j]) {
+					v.fail(path, "uniqueItems", "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+	if s.Items != nil {
+		for i, item := range arr {
+			v.validate(s.Items, item, path.Append(strconv.Itoa(i)), 0)
+		}
+	}
+}
+
+// validateObject 校验必需属性、属性和额外属性，缺少的必需属性报告在该属性的位置
+func (v *schemaValidator) validateObject(s *JSONSchema, obj map[string]interface{}, path JSONPointer) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(path.Append(name), "required", "is required")
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if property, ok := s.Properties[k]; ok {
+			v.validate(property, obj[k], path.Append(k), 0)
+		} else if s.AdditionalProperties != nil {
+			if b := s.AdditionalProperties.boolean; b != nil && !*b {
+				v.fail(path.Append(k), "additionalProperties", "is not allowed")
+				continue
+			}
+			v.validate(s.AdditionalProperties, obj[k], path.Append(k), 0)
+		}
+	}
+}
+
+// schemaTypeOf 返回通用值的 JSON Schema 类型名，整数值为 integer
+func schemaTypeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		if n, ok := queryNumber(value); ok {
+			if n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaTypeMatches 判断值是否满足类型约束，integer 也满足 number
+func schemaTypeMatches(types SchemaTypes, value interface{}, valueType string) bool {
+	for _, t := range types {
+		if t == valueType || (t == "number" && valueType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaEnumContains 判断值是否在枚举中，数字按数值比较
+func schemaEnumContains(enum []interface{}, value interface{}) bool {
+	for _, item := range enum {
+		if genericEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSchema 按当前格式将输入解码为通用结构后用 Schema 校验
+func (m *MarshalExt) validateSchema(data []byte, format MarshalFormat) error {
+	value, _, err := m.decodeGeneric(data, format)
+	if err != nil {
+		return err
+	}
+	return m.options.Schema.Validate(value)
+}
+
+// GenerateJSONSchema 从 Go 值的类型生成 schema，v 可以是零值或类型化的 nil 指针，如 (*Config)(nil)
+//
+// 属性名取 json 标签；没有 omitempty 的非指针字段为必需属性，指针字段允许 null。具名结构体放入 $defs 并以 $ref 引用，
+// 支持递归类型。字段可用 jsonschema 标签补充约束，多项以逗号分隔，例如：
+//
+//	Name string   `json:"name" jsonschema:"minLength=1,maxLength=64,pattern=^[a-z]+$"`
+//	Port int      `json:"port" jsonschema:"minimum=1,maximum=65535"`
+//	Mode string   `json:"mode,omitempty" jsonschema:"enum=dev|prod,description=运行模式"`
+//	Tags []string `json:"tags" jsonschema:"optional,uniqueItems"`
+//
+// 支持的项：title、description、format、minimum、maximum、exclusiveMinimum、exclusiveMaximum、
+// minLength、maxLength、pattern、minItems、maxItems、uniqueItems、enum（以 | 分隔）、required、optional
+func GenerateJSONSchema(v interface{}) (*JSONSchema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return &JSONSchema{}, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := &schemaGenerator{names: map[reflect.Type]string{}, defs: map[string]*JSONSchema{}}
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		g.names[t] = ""
+	}
+	schema, err := g.generate(t, true)
+	if err != nil {
+		return nil, fmt.Errorf("schema generate error: %w", err)
+	}
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+	return schema, nil
+}
+
+// JSONSchemaFor 从类型 T 生成 schema，见 GenerateJSONSchema
+func JSONSchemaFor[T any]() (*JSONSchema, error) {
+	return GenerateJSONSchema((*T)(nil))
+}
+
+// jsonMarshalerType 实现了 json.Marshaler 的类型无法推断结构，生成空 schema
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// schemaGenerator 从反射类型生成 schema
+type schemaGenerator struct {
+	names map[reflect.Type]string // 具名结构体在 $defs 中的名称，根类型为空字符串
+	defs  map[string]*JSONSchema
+}
+
+// generate 生成类型的 schema，inline 为 true 时具名结构体直接展开（用于根类型）
+func (g *schemaGenerator) generate(t reflect.Type, inline bool) (*JSONSchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: SchemaTypes{"string"}, Format: "date-time"}, nil
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &JSONSchema{}, nil
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &JSONSchema{Type: SchemaTypes{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: SchemaTypes{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: SchemaTypes{"integer"}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &JSONSchema{Type: SchemaTypes{"integer"}, Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: SchemaTypes{"number"}}, nil
+	case reflect.String:
+		return &JSONSchema{Type: SchemaTypes{"string"}}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: SchemaTypes{"string"}}, nil
+		}
+		items, err := g.generate(t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		schema := &JSONSchema{Type: SchemaTypes{"array"}, Items: items}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			schema.MinItems, schema.MaxItems = &n, &n
+		}
+		return schema, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) &&
+			!isIntegerKind(t.Key().Kind()) {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := g.generate(t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: SchemaTypes{"object"}, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.generateStruct(t, inline)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// isIntegerKind 判断是否为整数类型，JSON 对象键可以是整数
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// generateStruct 生成结构体的 schema，具名结构体放入 $defs 并返回 $ref
+func (g *schemaGenerator) generateStruct(t reflect.Type, inline bool) (*JSONSchema, error) {
+	if t.Name() != "" && !inline {
+		if name, ok := g.names[t]; ok {
+			return &JSONSchema{Ref: schemaDefRef(name)}, nil
+		}
+		name := t.Name()
+		for i := 2; g.defs[name] != nil; i++ {
+			name = t.Name() + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		g.defs[name] = &JSONSchema{} // 占位，防止同名类型重复使用该名称
+		schema, err := g.generateStruct(t, true)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = schema
+		return &JSONSchema{Ref: schemaDefRef(name)}, nil
+	}
+
+	schema := &JSONSchema{Type: SchemaTypes{"object"}, Properties: map[string]*JSONSchema{}}
+	for _, field := range structFields(t, "json") {
+		f := t.FieldByIndex(field.index)
+		property, err := g.generate(f.Type, false)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		required := !field.omitEmpty && f.Type.Kind() != reflect.Ptr
+		if tag, ok := f.Tag.Lookup("jsonschema"); ok {
+			if required, err = applySchemaTag(property, tag, required); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+		if f.Type.Kind() == reflect.Ptr {
+			property = nullableSchema(property)
+		}
+		schema.Properties[field.name] = property
+		if required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema, nil
+}
+
+// nullableSchema 使 schema 同时接受 null，$ref 等无类型约束的 schema 使用 anyOf 包装
+func nullableSchema(s *JSONSchema) *JSONSchema {
+	if len(s.Type) == 0 {
+		if s.Ref == "" && len(s.Enum) == 0 && len(s.AnyOf) == 0 {
+			return s
+		}
+		return &JSONSchema{AnyOf: []*JSONSchema{s, {Type: SchemaTypes{"null"}}}}
+	}
+	s.Type = append(s.Type, "null")
+	if len(s.Enum) > 0 {
+		s.Enum = append(s.Enum, nil)
+	}
+	return s
+}
+
+// schemaDefRef 返回 $defs 中名称的引用，空名称表示根
+func schemaDefRef(name string) string {
+	if name == "" {
+		return "#"
+	}
+	return "#" + JSONPointer{"$defs", name}.String()
+}
+
+// applySchemaTag 将 jsonschema 标签中的约束应用到 schema，返回字段是否必需
+func applySchemaTag(s *JSONSchema, tag string, required bool) (bool, error) {
+	for _, item := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		var err error
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "title":
+			s.Title = value
+		case "description":
+			s.Description = value
+		case "format":
+			s.Format = value
+		case "pattern":
+			s.Pattern = value
+			_, err = schemaPattern(value)
+		case "uniqueItems":
+			s.UniqueItems = true
+		case "minimum":
+			s.Minimum, err = parseSchemaFloat(value)
+		case "maximum":
+			s.Maximum, err = parseSchemaFloat(value)
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum, err = parseSchemaFloat(value)
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum, err = parseSchemaFloat(value)
+		case "minLength":
+			s.MinLength, err = parseSchemaInt(value)
+		case "maxLength":
+			s.MaxLength, err = parseSchemaInt(value)
+		case "minItems":
+			s.MinItems, err = parseSchemaInt(value)
+		case "maxItems":
+			s.MaxItems, err = parseSchemaInt(value)
+		case "enum":
+			s.Enum, err = parseSchemaEnum(s.Type, value)
+		default:
+			err = fmt.Errorf("unknown jsonschema option %q", key)
+		}
+		if err != nil {
+			return required, err
+		}
+	}
+	return required, nil
+}
+
+// parseSchemaFloat 解析标签中的数值
+func parseSchemaFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", value)
+	}
+	return &f, nil
+}
+
+// parseSchemaInt 解析标签中的非负整数
+func parseSchemaInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid non-negative integer %q", value)
+	}
+	return &n, nil
+}
+
+// parseSchemaEnum 按字段类型解析以 | 分隔的枚举值
+func parseSchemaEnum(types SchemaTypes, value string) ([]interface{}, error) {
+	var kind string
+	if len(types) > 0 {
+		kind = types[0]
+	}
+	items := strings.Split(value, "|")
+	enum := make([]interface{}, len(items))
+	for i, item := range items {
+		var err error
+		switch kind {
+		case "integer":
+			enum[i], err = strconv.ParseInt(item, 10, 64)
+		case "number":
+			enum[i], err = strconv.ParseFloat(item, 64)
+		case "boolean":
+			enum[i], err = strconv.ParseBool(item)
+		default:
+			enum[i] = item
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid enum value %q for type %s", item, kind)
+		}
+	}
+	return enum, nil
+}
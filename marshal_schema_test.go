@@ -0,0 +1,284 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+JSON Schema 校验与生成测试
+
+运行命令：
+go test -v -run "^Test(Schema|JSONSchema).*$"
+
+测试内容：
+1. 类型、枚举、数值范围、字符串长度和 pattern、数组、对象的校验
+2. 一次返回全部违规项及其 JSON Pointer 位置
+3. $defs、$ref、anyOf、递归引用和布尔 schema
+4. 无效 schema 的解析错误
+5. MarshalExt.Unmarshal 解码前校验（JSON、YAML）
+6. 从结构体生成 schema
+*/
+
+var userSchema = []byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"role": {"enum": ["admin", "user", 1]},
+		"score": {"type": ["number", "null"], "maximum": 100},
+		"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 3, "uniqueItems": true},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string"}, "zip": {"type": "string", "pattern": "^[0-9]{6}$"}}
+		}
+	}
+}`)
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseJSONSchema(userSchema)
+	if err != nil {
+		t.Fatalf("ParseJSONSchema failed: %v", err)
+	}
+
+	valid := []string{
+		`{"name":"alice","age":30}`,
+		`{"name":"bob","age":0,"role":1,"score":null,"tags":["a","b"],"address":{"city":"x","zip":"100000"}}`,
+		`{"name":"carol","age":1.0,"score":99.5}`,
+	}
+	for _, doc := range valid {
+		if err := schema.Validate([]byte(doc)); err != nil {
+			t.Errorf("Validate(%s) failed: %v", doc, err)
+		}
+	}
+
+	cases := []struct {
+		doc        string
+		violations []string
+	}{
+		{`[]`, []string{"(root): expected object, got array"}},
+		{`{}`, []string{"/name: is required", "/age: is required"}},
+		{`{"name":"A","age":-1.5}`, []string{"/age: expected integer, got number", "/name: length must be >= 2", `/name: must match pattern "^[a-z]+$"`}},
+		{`{"name":"toolongname","age":150}`, []string{"/age: must be < 150", "/name: length must be <= 8"}},
+		{`{"name":"ab","age":1,"role":"guest","score":"high"}`, []string{`/role: must be one of ["admin","user",1]`, "/score: expected number or null, got string"}},
+		{`{"name":"ab","age":1,"tags":[]}`, []string{"/tags: must have at least 1 items"}},
+		{`{"name":"ab","age":1,"tags":["a","b","a",1]}`, []string{"/tags: must have at most 3 items", "/tags: items 0 and 2 are equal", "/tags/3: expected string, got integer"}},
+		{`{"name":"ab","age":1,"address":{"zip":"12"},"extra":true}`, []string{"/address/city: is required", `/address/zip: must match pattern "^[0-9]{6}$"`, "/extra: is not allowed"}},
+	}
+	for _, c := range cases {
+		err := schema.Validate([]byte(c.doc))
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) || !errors.Is(err, ErrSchemaValidation) {
+			t.Errorf("Validate(%s) error = %v, want *SchemaError", c.doc, err)
+			continue
+		}
+		var got []string
+		for _, v := range schemaErr.Violations {
+			got = append(got, v.String())
+		}
+		if !reflect.DeepEqual(got, c.violations) {
+			t.Errorf("Validate(%s) violations =\n%q\nwant\n%q", c.doc, got, c.violations)
+		}
+	}
+
+	err = schema.Validate(map[string]interface{}{"name": "ab"})
+	if err == nil || !strings.Contains(err.Error(), "schema validation failed: /age: is required") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if v := err.(*SchemaError).Violations[0]; v.Path != "/age" || v.Keyword != "required" {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+}
+
+func TestSchemaRefs(t *testing.T) {
+	tree := MustParseJSONSchema([]byte(`{
+		"$ref": "#/$defs/node",
+		"$defs": {
+			"node": {
+				"type": "object",
+				"properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#/$defs/node"}}},
+				"additionalProperties": {"type": "string"}
+			}
+		}
+	}`))
+	if err := tree.Validate([]byte(`{"value":1,"note":"x","children":[{"value":2,"children":[]}]}`)); err != nil {
+		t.Errorf("Recursive schema failed: %v", err)
+	}
+	err := tree.Validate([]byte(`{"children":[{"children":[{"value":"3","note":1}]}]}`))
+	if err == nil || !strings.Contains(err.Error(), "/children/0/children/0/note: expected string, got integer") ||
+		!strings.Contains(err.Error(), "/children/0/children/0/value: expected integer, got string") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	loop := MustParseJSONSchema([]byte(`{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`))
+	if err := loop.Validate([]byte(`1`)); err == nil || !strings.Contains(err.Error(), "circular $ref") {
+		t.Errorf("Circular reference error = %v", err)
+	}
+
+	anyOf := MustParseJSONSchema([]byte(`{"anyOf":[{"type":"string","maxLength":2},{"type":"integer"}]}`))
+	if err := anyOf.Validate([]byte(`"ab"`)); err != nil {
+		t.Errorf("anyOf failed: %v", err)
+	}
+	if err := anyOf.Validate([]byte(`"abc"`)); err == nil || err.Error() != "schema validation failed: (root): must match at least one schema in anyOf" {
+		t.Errorf("anyOf error = %v", err)
+	}
+
+	boolean := MustParseJSONSchema([]byte(`{"properties":{"any":true,"none":false}}`))
+	if err := boolean.Validate([]byte(`{"any":[1,{}]}`)); err != nil {
+		t.Errorf("True schema failed: %v", err)
+	}
+	if err := boolean.Validate([]byte(`{"none":null}`)); err == nil {
+		t.Error("False schema should reject")
+	}
+	if boolean.String() != `{"properties":{"any":true,"none":false}}` {
+		t.Errorf("String() = %s", boolean.String())
+	}
+
+	for _, invalid := range []string{
+		`{"type":"text"}`,
+		`{"pattern":"("}`,
+		`{"$ref":"#/$defs/missing"}`,
+		`{"$ref":"other.json#/a"}`,
+		`{"properties":{"a":{"items":{"type":1}}}}`,
+		`[]`,
+	} {
+		if _, err := ParseJSONSchema([]byte(invalid)); err == nil {
+			t.Errorf("ParseJSONSchema(%s) should fail", invalid)
+		}
+	}
+}
+
+func TestSchemaUnmarshal(t *testing.T) {
+	type user struct {
+		Name string `json:"name" yaml:"name"`
+		Age  int    `json:"age" yaml:"age"`
+	}
+	schema := MustParseJSONSchema(userSchema)
+	m := DefaultMarshalExt().SetSchema(schema)
+
+	var u user
+	if err := m.Unmarshal([]byte(`{"name":"alice","age":30}`), &u); err != nil || u.Name != "alice" {
+		t.Errorf("Unmarshal = %+v, %v", u, err)
+	}
+
+	u = user{Name: "keep"}
+	err := m.Unmarshal([]byte(`{"name":"Alice","age":-1}`), &u)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Violations) != 2 || u.Name != "keep" {
+		t.Errorf("Unmarshal error = %v, target = %+v", err, u)
+	}
+
+	yaml := m.Clone().SetFormat(YAMLFormat)
+	if err := yaml.Unmarshal([]byte("name: bob\nage: 20\n"), &u); err != nil || u.Age != 20 {
+		t.Errorf("YAML Unmarshal = %+v, %v", u, err)
+	}
+	if err := yaml.Unmarshal([]byte("name: bob\nage: old\n"), &u); !errors.Is(err, ErrSchemaValidation) {
+		t.Errorf("YAML Unmarshal error = %v, want ErrSchemaValidation", err)
+	}
+	if err := m.Unmarshal([]byte(`{"name":`), &u); err == nil || errors.Is(err, ErrSchemaValidation) {
+		t.Errorf("Malformed input error = %v", err)
+	}
+}
+
+func TestJSONSchemaGenerate(t *testing.T) {
+	type address struct {
+		City string `json:"city" jsonschema:"minLength=1"`
+		Zip  string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{6}$"`
+	}
+	type category struct {
+		Name     string      `json:"name"`
+		Children []*category `json:"children,omitempty"`
+	}
+	type config struct {
+		Name     string            `json:"name" jsonschema:"title=名称,maxLength=16"`
+		Port     uint16            `json:"port" jsonschema:"minimum=1,maximum=65535"`
+		Mode     string            `json:"mode,omitempty" jsonschema:"enum=dev|prod"`
+		Level    int               `json:"level" jsonschema:"enum=1|2|3,optional"`
+		Ratio    float64           `json:"ratio,omitempty"`
+		Tags     []string          `json:"tags" jsonschema:"uniqueItems,maxItems=4"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Home     address           `json:"home"`
+		Work     *address          `json:"work"`
+		Root     category          `json:"root"`
+		Created  time.Time         `json:"created"`
+		Data     []byte            `json:"data,omitempty"`
+		Extra    interface{}       `json:"extra,omitempty"`
+		Internal string            `json:"-"`
+	}
+
+	schema, err := JSONSchemaFor[config]()
+	if err != nil {
+		t.Fatalf("JSONSchemaFor failed: %v", err)
+	}
+	if err := schema.Check(); err != nil {
+		t.Fatalf("Generated schema is invalid: %v\n%s", err, schema)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"name", "port", "tags", "home", "root", "created"}) {
+		t.Errorf("Required = %v", schema.Required)
+	}
+	if _, ok := schema.Properties["Internal"]; ok {
+		t.Error("Ignored field should not appear")
+	}
+	if p := schema.Properties["port"]; *p.Minimum != 1 || *p.Maximum != 65535 || p.Type[0] != "integer" {
+		t.Errorf("Port schema = %s", p)
+	}
+	if p := schema.Properties["level"]; !reflect.DeepEqual(p.Enum, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Errorf("Level enum = %v", p.Enum)
+	}
+	if schema.Properties["home"].Ref != "#/$defs/address" || schema.Properties["work"].AnyOf[0].Ref != "#/$defs/address" {
+		t.Errorf("Struct fields should reference $defs: %s", schema)
+	}
+	if items := schema.Defs["category"].Properties["children"].Items; items.Ref != "#/$defs/category" {
+		t.Errorf("Recursive type should reference itself: %s", items)
+	}
+	if p := schema.Properties["work"].AnyOf; len(p) != 2 || p[1].Type[0] != "null" {
+		t.Errorf("Pointer field should accept null: %s", schema.Properties["work"])
+	}
+	if p := schema.Properties["created"]; p.Format != "date-time" {
+		t.Errorf("Time schema = %s", p)
+	}
+
+	valid := config{Name: "svc", Port: 80, Level: 1, Tags: []string{"a"}, Home: address{City: "x"}, Root: category{Name: "r", Children: []*category{{Name: "c"}}}}
+	if err := schema.Validate(valid); err != nil {
+		t.Errorf("Validate(valid) failed: %v", err)
+	}
+
+	err = schema.Validate([]byte(`{"name":"svc","port":0,"mode":"test","level":4,"tags":["a","a"],"home":{"city":"","zip":"1"},"root":{"name":1},"created":"now"}`))
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Validate error = %v", err)
+	}
+	paths := map[string]bool{}
+	for _, v := range schemaErr.Violations {
+		paths[v.Path] = true
+	}
+	for _, path := range []string{"/port", "/mode", "/level", "/tags", "/home/city", "/home/zip", "/root/name"} {
+		if !paths[path] {
+			t.Errorf("Missing violation at %s: %v", path, err)
+		}
+	}
+
+	if _, err := GenerateJSONSchema(struct {
+		N int `jsonschema:"minimum=abc"`
+	}{}); err == nil {
+		t.Error("Invalid tag should fail")
+	}
+	if _, err := GenerateJSONSchema(map[bool]int{}); err == nil {
+		t.Error("Unsupported map key should fail")
+	}
+
+	reparsed, err := ParseJSONSchema([]byte(schema.String()))
+	if err != nil || reparsed.String() != schema.String() {
+		t.Errorf("Schema round trip failed: %v\n%s\n%s", err, schema, reparsed)
+	}
+}
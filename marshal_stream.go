@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// StreamMode 流式编解码的格式
+type StreamMode int
+
+const (
+	// NDJSONStream 每行一个 JSON 值 (Newline Delimited JSON)
+	NDJSONStream StreamMode = iota
+	// JSONArrayStream 顶层 JSON 数组，逐个元素编解码
+	JSONArrayStream
+)
+
+// ErrEncoderClosed 编码器已关闭
+var ErrEncoderClosed = errors.New("encoder closed")
+
+// Encoder 流式编码器，逐个写入元素，不在内存中保留已写入的数据
+type Encoder struct {
+	w       io.Writer
+	marshal *MarshalExt
+	mode    StreamMode
+	count   int
+	closed  bool
+}
+
+// NewEncoder 创建流式编码器，元素按当前 JSON 选项编码（Pretty 和 Color 被忽略）
+// 使用 JSONArrayStream 时必须调用 Close 写入结尾的 ]
+func (m *MarshalExt) NewEncoder(w io.Writer, mode StreamMode) *Encoder {
+	return &Encoder{
+		w:       w,
+		marshal: m.Clone().SetFormat(JSONFormat).SetPretty(false).SetColor(ColorNever),
+		mode:    mode,
+	}
+}
+
+// Encode 写入一个元素
+func (e *Encoder) Encode(v interface{}) error {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+
+	data, err := e.marshal.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var buf []byte
+	switch e.mode {
+	case JSONArrayStream:
+		if e.count == 0 {
+			buf = append(buf, '[')
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, data...)
+	default:
+		buf = append(data, '\n')
+	}
+
+	if _, err := e.w.Write(buf); err != nil {
+		return fmt.Errorf("stream write error: %w", err)
+	}
+	e.count++
+	return nil
+}
+
+// Count 返回已写入的元素个数
+func (e *Encoder) Count() int {
+	return e.count
+}
+
+// Close 结束流，JSONArrayStream 写入结尾的 ]（未写入元素时写入 []），不关闭底层 Writer
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.mode != JSONArrayStream {
+		return nil
+	}
+	end := "]"
+	if e.count == 0 {
+		end = "[]"
+	}
+	if _, err := io.WriteString(e.w, end); err != nil {
+		return fmt.Errorf("stream write error: %w", err)
+	}
+	return nil
+}
+
+// Decoder 流式解码器，每次只在内存中保留当前元素
+type Decoder struct {
+	dec     *json.Decoder
+	mode    StreamMode
+	started bool
+	count   int
+	err     error
+}
+
+// NewDecoder 创建流式解码器，DisallowUnknownFields 和 UseNumber 选项对每个元素生效
+func (m *MarshalExt) NewDecoder(r io.Reader, mode StreamMode) *Decoder {
+	dec := json.NewDecoder(r)
+	if m.options.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if m.options.UseNumber {
+		dec.UseNumber()
+	}
+	return &Decoder{
+		dec:  dec,
+		mode: mode,
+	}
+}
+
+// Decode 解码下一个元素到 v，没有更多元素时返回 io.EOF，出错后总是返回同一错误
+func (d *Decoder) Decode(v interface{}) error {
+	if d.err != nil {
+		return d.err
+	}
+	if err := d.next(v); err != nil {
+		if err != io.EOF {
+			err = fmt.Errorf("stream decode error: element %d: %w", d.count, err)
+		}
+		d.err = err
+		return err
+	}
+	d.count++
+	return nil
+}
+
+// next 读取下一个元素
+func (d *Decoder) next(v interface{}) error {
+	if d.mode != JSONArrayStream {
+		return d.dec.Decode(v)
+	}
+
+	if !d.started {
+		d.started = true
+		token, err := d.dec.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if token != json.Delim('[') {
+			return fmt.Errorf("expected JSON array, got %v", token)
+		}
+	}
+
+	if !d.dec.More() {
+		token, err := d.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if token != json.Delim(']') {
+			return fmt.Errorf("expected end of array, got %v", token)
+		}
+		return io.EOF
+	}
+	return d.dec.Decode(v)
+}
+
+// Count 返回已解码的元素个数
+func (d *Decoder) Count() int {
+	return d.count
+}
+
+// All 返回原始元素的迭代器，出错时产出错误后结束
+func (d *Decoder) All() iter.Seq2[json.RawMessage, error] {
+	return DecodeEach[json.RawMessage](d)
+}
+
+// DecodeEach 返回按类型 T 解码元素的迭代器，出错时产出错误后结束
+func DecodeEach[T any](d *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			var item T
+			err := d.Decode(&item)
+			if err == io.EOF {
+				return
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// NewEncoder 使用默认序列化器创建流式编码器
+func NewEncoder(w io.Writer, mode StreamMode) *Encoder {
+	return DefaultMarshal.NewEncoder(w, mode)
+}
+
+// NewDecoder 使用默认序列化器创建流式解码器
+func NewDecoder(r io.Reader, mode StreamMode) *Decoder {
+	return DefaultMarshal.NewDecoder(r, mode)
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+/*
+流式编解码测试
+
+运行命令：
+go test -v -run "^TestStream.*$"
+
+测试内容：
+1. NDJSON 编码与解码
+2. JSON 数组流编码与解码（含空数组）
+3. 泛型迭代器及提前结束
+4. 大量元素的流式处理（写入端与读取端通过管道连接）
+5. 错误处理
+*/
+
+type streamTestRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := DefaultMarshalExt().SetPretty(true).SetEscapeHTML(false).NewEncoder(&buf, NDJSONStream)
+	for i := 1; i <= 3; i++ {
+		if err := encoder.Encode(streamTestRecord{ID: i, Name: fmt.Sprintf("<%d>", i)}); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	encoder.Close()
+
+	expected := "{\"id\":1,\"name\":\"<1>\"}\n{\"id\":2,\"name\":\"<2>\"}\n{\"id\":3,\"name\":\"<3>\"}\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected NDJSON:\n%s", buf.String())
+	}
+	if encoder.Count() != 3 {
+		t.Errorf("Expected count 3, got %d", encoder.Count())
+	}
+	if err := encoder.Encode(1); !errors.Is(err, ErrEncoderClosed) {
+		t.Errorf("Encode after Close should fail, got %v", err)
+	}
+
+	decoder := NewDecoder(&buf, NDJSONStream)
+	var ids []int
+	for record, err := range DecodeEach[streamTestRecord](decoder) {
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		ids = append(ids, record.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" || decoder.Count() != 3 {
+		t.Errorf("Unexpected ids %v (count %d)", ids, decoder.Count())
+	}
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf, JSONArrayStream)
+	encoder.Encode(map[string]int{"a": 1})
+	encoder.Encode([]string{"x"})
+	encoder.Encode(nil)
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if buf.String() != `[{"a":1},["x"],null]` {
+		t.Errorf("Unexpected array: %s", buf.String())
+	}
+
+	var raw []string
+	for item, err := range NewDecoder(strings.NewReader(" [ {\"a\":1} , [\"x\"] , null ] "), JSONArrayStream).All() {
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		raw = append(raw, string(item))
+	}
+	if strings.Join(raw, "|") != `{"a":1}|["x"]|null` {
+		t.Errorf("Unexpected elements: %v", raw)
+	}
+
+	var empty bytes.Buffer
+	emptyEncoder := NewEncoder(&empty, JSONArrayStream)
+	emptyEncoder.Close()
+	if empty.String() != "[]" {
+		t.Errorf("Empty array should be [], got %q", empty.String())
+	}
+	var value interface{}
+	if err := NewDecoder(&empty, JSONArrayStream).Decode(&value); err != io.EOF {
+		t.Errorf("Empty array should yield io.EOF, got %v", err)
+	}
+}
+
+func TestStreamEarlyBreak(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("[1,2,3,4]"), JSONArrayStream)
+	var seen []int
+	for n, err := range DecodeEach[int](decoder) {
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		seen = append(seen, n)
+		if n == 2 {
+			break
+		}
+	}
+
+	var next int
+	if err := decoder.Decode(&next); err != nil || next != 3 {
+		t.Errorf("Decoding should resume after break, got %d, %v", next, err)
+	}
+	if fmt.Sprint(seen) != "[1 2]" {
+		t.Errorf("Unexpected elements %v", seen)
+	}
+}
+
+func TestStreamLarge(t *testing.T) {
+	const total = 50000
+	reader, writer := io.Pipe()
+	go func() {
+		encoder := NewEncoder(writer, JSONArrayStream)
+		for i := 0; i < total; i++ {
+			if err := encoder.Encode(streamTestRecord{ID: i, Name: "item"}); err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+		writer.CloseWithError(encoder.Close())
+	}()
+
+	sum := 0
+	decoder := NewDecoder(reader, JSONArrayStream)
+	for record, err := range DecodeEach[streamTestRecord](decoder) {
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		sum += record.ID
+	}
+	if decoder.Count() != total || sum != total*(total-1)/2 {
+		t.Errorf("Unexpected count %d or sum %d", decoder.Count(), sum)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		mode  StreamMode
+	}{
+		{"NotArray", `{"a":1}`, JSONArrayStream},
+		{"EmptyInput", ``, JSONArrayStream},
+		{"Unterminated", `[1,2`, JSONArrayStream},
+		{"BadElement", `[1,}`, JSONArrayStream},
+		{"BadLine", "{\"id\":1}\n{oops}\n", NDJSONStream},
+		{"WrongType", `{"id":"x"}`, NDJSONStream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.input), tt.mode)
+			var lastErr error
+			for _, err := range DecodeEach[streamTestRecord](decoder) {
+				lastErr = err
+			}
+			if lastErr == nil {
+				t.Fatal("Expected an error")
+			}
+			var record streamTestRecord
+			if err := decoder.Decode(&record); err != lastErr {
+				t.Errorf("Error should be sticky: %v vs %v", err, lastErr)
+			}
+		})
+	}
+
+	encoder := NewEncoder(io.Discard, NDJSONStream)
+	if err := encoder.Encode(make(chan int)); err == nil {
+		t.Error("Unsupported value should fail")
+	}
+}
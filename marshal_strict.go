@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 严格解码选项（DisallowUnknownFields、DisallowTrailingData、MaxDepth、MaxSize）的检查实现
+
+var (
+	// ErrUnknownField 输入包含目标结构体中不存在的字段
+	ErrUnknownField = errors.New("unknown field")
+	// ErrTrailingData 值之后存在多余数据
+	ErrTrailingData = errors.New("trailing data")
+	// ErrMaxDepthExceeded 嵌套深度超过限制
+	ErrMaxDepthExceeded = errors.New("max depth exceeded")
+	// ErrMaxSizeExceeded 输入大小超过限制
+	ErrMaxSizeExceeded = errors.New("max size exceeded")
+)
+
+// checkJSONDepth 扫描 JSON 文本，对象和数组的嵌套深度超过 maxDepth 时返回错误
+func checkJSONDepth(data []byte, maxDepth int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: limit %d", ErrMaxDepthExceeded, maxDepth)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// wrapUnknownJSONField 将 encoding/json 的未知字段错误包装为 ErrUnknownField
+func wrapUnknownJSONField(err error) error {
+	const prefix = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, prefix) {
+		return fmt.Errorf("json unmarshal error: %w: %s", ErrUnknownField, strings.TrimPrefix(msg, prefix))
+	}
+	return err
+}
+
+// isUnknownYAMLField 判断是否为 yaml.v3 KnownFields 产生的未知字段错误
+func isUnknownYAMLField(err error) bool {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return false
+	}
+	for _, msg := range typeErr.Errors {
+		if strings.Contains(msg, " not found in type ") {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlDepth 返回 YAML 节点中映射和序列的最大嵌套深度，别名不展开
+func yamlDepth(node *yaml.Node) int {
+	depth := 0
+	for _, child := range node.Content {
+		depth = max(depth, yamlDepth(child))
+	}
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		depth++
+	}
+	return depth
+}
+
+// checkXMLStructure 扫描 XML，检查元素嵌套深度，以及禁止多余数据时根元素之后是否还有元素或文本
+func (m *MarshalExt) checkXMLStructure(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("xml unmarshal error: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if roots > 1 && m.options.DisallowTrailingData {
+					return fmt.Errorf("xml unmarshal error: %w: extra root element <%s>", ErrTrailingData, t.Name.Local)
+				}
+			}
+			depth++
+			if m.options.MaxDepth > 0 && depth > m.options.MaxDepth {
+				return fmt.Errorf("xml unmarshal error: %w: limit %d", ErrMaxDepthExceeded, m.options.MaxDepth)
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && roots > 0 && m.options.DisallowTrailingData && len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("xml unmarshal error: %w: text after root element", ErrTrailingData)
+			}
+		}
+	}
+}
+
+// xmlStructInfo 结构体可接收的 XML 元素和属性
+type xmlStructInfo struct {
+	elements map[string]reflect.Type // 元素名到字段类型，类型为 nil 时不检查子元素
+	attrs    map[string]bool
+	anyElem  bool // 存在 ,any 或 ,innerxml 字段
+	anyAttr  bool // 存在 ,any,attr 字段
+}
+
+// xmlStructFields 按 encoding/xml 的标签规则收集结构体字段
+func xmlStructFields(t reflect.Type, info *xmlStructInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if !f.IsExported() || tag == "-" || f.Name == "XMLName" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		flags := strings.Split(opts, ",")
+		has := func(flag string) bool {
+			for _, f := range flags {
+				if f == flag {
+					return true
+				}
+			}
+			return false
+		}
+
+		if f.Anonymous && tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				xmlStructFields(ft, info)
+				continue
+			}
+		}
+
+		// 去掉命名空间前缀
+		if i := strings.LastIndex(name, " "); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		switch {
+		case has("chardata"), has("cdata"), has("comment"):
+		case has("innerxml"):
+			info.anyElem = true
+		case has("any") && has("attr"):
+			info.anyAttr = true
+		case has("any"):
+			info.anyElem = true
+		case has("attr"):
+			info.attrs[name] = true
+		case strings.Contains(name, ">"):
+			parent, _, _ := strings.Cut(name, ">")
+			info.elements[parent] = nil
+		default:
+			info.elements[name] = f.Type
+		}
+	}
+}
+
+// checkXMLFields 将解析出的通用结构与目标类型比较，返回第一个未知的元素或属性，忽略命名空间声明
+func (m *MarshalExt) checkXMLFields(t reflect.Type, value interface{}, path string) error {
+	for t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	// 标量字段只接收文本，属性和子元素都是未知的
+	if t.Kind() != reflect.Struct || t == timeType {
+		for key := range values {
+			if key == m.xmlTextKey() {
+				continue
+			}
+			if name, ok := strings.CutPrefix(key, m.xmlAttrPrefix()); ok {
+				if isXMLNamespaceDecl(name) {
+					continue
+				}
+				key = "@" + name
+			}
+			return fmt.Errorf("xml unmarshal error: %w: %s/%s", ErrUnknownField, path, key)
+		}
+		return nil
+	}
+
+	info := &xmlStructInfo{elements: make(map[string]reflect.Type), attrs: make(map[string]bool)}
+	xmlStructFields(t, info)
+
+	for key, child := range values {
+		if key == m.xmlTextKey() {
+			continue
+		}
+		if name, ok := strings.CutPrefix(key, m.xmlAttrPrefix()); ok {
+			// 命名空间声明不对应字段，带前缀的属性按本地名匹配
+			if isXMLNamespaceDecl(name) {
+				continue
+			}
+			if !info.attrs[name] && !info.anyAttr {
+				return fmt.Errorf("xml unmarshal error: %w: %s/@%s", ErrUnknownField, path, name)
+			}
+			continue
+		}
+
+		fieldType, ok := info.elements[key]
+		if !ok {
+			if info.anyElem {
+				continue
+			}
+			return fmt.Errorf("xml unmarshal error: %w: %s/%s", ErrUnknownField, path, key)
+		}
+		if fieldType == nil {
+			continue
+		}
+		items, ok := child.([]interface{})
+		if !ok {
+			items = []interface{}{child}
+		}
+		for _, item := range items {
+			if err := m.checkXMLFields(fieldType, item, path+"/"+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
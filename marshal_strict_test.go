@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+/*
+严格解码测试
+
+运行命令：
+go test -v -run "^TestStrict.*$"
+
+测试内容：
+1. DisallowUnknownFields 对 JSON、YAML、XML 生效
+2. UseNumber 保留大整数精度
+3. DisallowTrailingData 拒绝多余数据
+4. MaxDepth、MaxSize 限制
+5. 默认行为保持不变
+*/
+
+type strictTestConfig struct {
+	Name  string `json:"name" yaml:"name" xml:"name"`
+	Port  int    `json:"port" yaml:"port" xml:"port,attr"`
+	Inner struct {
+		Value string `json:"value" yaml:"value" xml:"value"`
+	} `json:"inner" yaml:"inner" xml:"inner"`
+}
+
+func TestStrictUnknownFields(t *testing.T) {
+	tests := []struct {
+		format MarshalFormat
+		valid  string
+		nested string
+		top    string
+	}{
+		{JSONFormat,
+			`{"name":"a","port":1,"inner":{"value":"x"}}`,
+			`{"name":"a","inner":{"value":"x","extra":1}}`,
+			`{"name":"a","extra":1}`},
+		{YAMLFormat,
+			"name: a\nport: 1\ninner:\n  value: x\n",
+			"name: a\ninner:\n  value: x\n  extra: 1\n",
+			"name: a\nextra: 1\n"},
+		{XMLFormat,
+			`<config port="1"><name>a</name><inner><value>x</value></inner></config>`,
+			`<config><name>a</name><inner><value>x</value><extra>1</extra></inner></config>`,
+			`<config debug="true"><name>a</name></config>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			lenient := DefaultMarshalExt().SetFormat(tt.format)
+			strict := lenient.Clone().SetDisallowUnknownFields(true)
+
+			var config strictTestConfig
+			if err := strict.UnmarshalFromString(tt.valid, &config); err != nil {
+				t.Fatalf("Valid input failed: %v", err)
+			}
+			if config.Name != "a" || config.Port != 1 || config.Inner.Value != "x" {
+				t.Errorf("Unexpected config: %+v", config)
+			}
+
+			for _, input := range []string{tt.nested, tt.top} {
+				if err := lenient.UnmarshalFromString(input, &config); err != nil {
+					t.Errorf("Lenient decoding should ignore unknown fields: %v", err)
+				}
+				err := strict.UnmarshalFromString(input, &config)
+				if !errors.Is(err, ErrUnknownField) {
+					t.Errorf("Expected ErrUnknownField for %q, got %v", input, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictXMLFieldRules(t *testing.T) {
+	type item struct {
+		ID string `xml:"id,attr"`
+	}
+	type document struct {
+		Items []item   `xml:"list>item"`
+		Tags  []string `xml:"tag"`
+		Text  string   `xml:",chardata"`
+		Extra []struct {
+			Any string `xml:",innerxml"`
+		} `xml:"extra"`
+	}
+
+	strict := DefaultMarshalExt().SetFormat(XMLFormat).SetDisallowUnknownFields(true)
+	var doc document
+	input := `<doc>text<list><item id="1"/><item id="2"/></list><tag>a</tag><tag>b</tag><extra><anything/></extra></doc>`
+	if err := strict.UnmarshalFromString(input, &doc); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(doc.Items) != 2 || len(doc.Tags) != 2 {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	err := strict.UnmarshalFromString(`<doc><tag>a</tag><tag x="1">b</tag></doc>`, &doc)
+	if !errors.Is(err, ErrUnknownField) || !strings.Contains(err.Error(), "/doc/tag/@x") {
+		t.Errorf("Expected unknown attribute with path, got %v", err)
+	}
+
+	// 命名空间声明被忽略，带前缀的属性按本地名匹配
+	type named struct {
+		Lang string `xml:"lang,attr"`
+		Name string `xml:"name"`
+	}
+	var n named
+	input = `<doc xmlns="urn:x" xmlns:xml2="urn:y" xml2:lang="en"><name xmlns="urn:z">a</name></doc>`
+	if err := strict.UnmarshalFromString(input, &n); err != nil {
+		t.Fatalf("Namespace declarations should be accepted: %v", err)
+	}
+	if n.Name != "a" || n.Lang != "en" {
+		t.Errorf("Unexpected namespaced document: %+v", n)
+	}
+	err = strict.UnmarshalFromString(`<doc xmlns:p="urn:y" p:other="1"><name>a</name></doc>`, &n)
+	if !errors.Is(err, ErrUnknownField) || !strings.Contains(err.Error(), "/doc/@other") {
+		t.Errorf("Expected unknown namespaced attribute, got %v", err)
+	}
+}
+
+func TestStrictUseNumber(t *testing.T) {
+	input := `{"id":9007199254740993,"ratio":0.5}`
+
+	var lenient map[string]interface{}
+	DefaultMarshalExt().UnmarshalFromString(input, &lenient)
+	if _, ok := lenient["id"].(float64); !ok {
+		t.Fatalf("Default decoding should produce float64, got %T", lenient["id"])
+	}
+
+	var precise map[string]interface{}
+	if err := DefaultMarshalExt().SetUseNumber(true).UnmarshalFromString(input, &precise); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	id, ok := precise["id"].(json.Number)
+	if !ok || id.String() != "9007199254740993" {
+		t.Errorf("Expected exact json.Number, got %#v", precise["id"])
+	}
+
+	var items []interface{}
+	for item, err := range DefaultMarshalExt().SetUseNumber(true).NewDecoder(strings.NewReader(`[9007199254740993]`), JSONArrayStream).All() {
+		if err != nil {
+			t.Fatalf("Stream decode failed: %v", err)
+		}
+		items = append(items, string(item))
+	}
+	if len(items) != 1 || items[0] != "9007199254740993" {
+		t.Errorf("Unexpected stream items: %v", items)
+	}
+}
+
+func TestStrictTrailingData(t *testing.T) {
+	tests := []struct {
+		format MarshalFormat
+		input  string
+	}{
+		{YAMLFormat, "name: a\n---\nname: b\n"},
+		{XMLFormat, `<config><name>a</name></config><config/>`},
+		{XMLFormat, `<config><name>a</name></config>garbage`},
+	}
+	for _, tt := range tests {
+		var config strictTestConfig
+		if err := DefaultMarshalExt().SetFormat(tt.format).UnmarshalFromString(tt.input, &config); err != nil {
+			t.Errorf("%s: default decoding should accept trailing data: %v", tt.format, err)
+		}
+		err := DefaultMarshalExt().SetFormat(tt.format).SetDisallowTrailingData(true).UnmarshalFromString(tt.input, &config)
+		if !errors.Is(err, ErrTrailingData) {
+			t.Errorf("%s: expected ErrTrailingData for %q, got %v", tt.format, tt.input, err)
+		}
+	}
+
+	var value interface{}
+	err := DefaultMarshalExt().SetStrict(true).UnmarshalFromString(`{"a":1} {"b":2}`, &value)
+	if !errors.Is(err, ErrTrailingData) {
+		t.Errorf("JSON trailing data should fail, got %v", err)
+	}
+	if err := DefaultMarshalExt().UnmarshalFromString(`{"a":1} x`, &value); err == nil {
+		t.Error("JSON should always reject trailing data")
+	}
+	if err := DefaultMarshalExt().SetStrict(true).UnmarshalFromString("{\"a\":1}\n", &value); err != nil {
+		t.Errorf("Trailing whitespace should be accepted: %v", err)
+	}
+}
+
+func TestStrictLimits(t *testing.T) {
+	deepJSON := strings.Repeat("[", 5) + strings.Repeat("]", 5)
+	deepYAML := "a:\n  b:\n    c:\n      d: 1\n"
+	deepXML := "<a><b><c><d>1</d></c></b></a>"
+	tricky := `{"s":"[[[[[[[[\"]]]"}`
+
+	tests := []struct {
+		format MarshalFormat
+		input  string
+		ok     int
+		fail   int
+	}{
+		{JSONFormat, deepJSON, 5, 4},
+		{JSONFormat, tricky, 1, 0},
+		{YAMLFormat, deepYAML, 4, 3},
+		{XMLFormat, deepXML, 4, 3},
+	}
+	for _, tt := range tests {
+		var value interface{}
+		marshal := DefaultMarshalExt().SetFormat(tt.format)
+		if err := marshal.Clone().SetMaxDepth(tt.ok).UnmarshalFromString(tt.input, &value); err != nil {
+			t.Errorf("%s depth %d should pass: %v", tt.format, tt.ok, err)
+		}
+		if tt.fail == 0 {
+			continue
+		}
+		err := marshal.Clone().SetMaxDepth(tt.fail).UnmarshalFromString(tt.input, &value)
+		if !errors.Is(err, ErrMaxDepthExceeded) {
+			t.Errorf("%s depth %d: expected ErrMaxDepthExceeded, got %v", tt.format, tt.fail, err)
+		}
+	}
+
+	msgpack := DefaultMarshalExt().SetFormat(MsgPackFormat)
+	data, _ := msgpack.Marshal([]interface{}{[]interface{}{1}})
+	var value interface{}
+	if err := msgpack.Clone().SetMaxDepth(1).Unmarshal(data, &value); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("MaxDepth should apply to binary formats, got %v", err)
+	}
+
+	limited := DefaultMarshalExt().SetMaxSize(8)
+	if err := limited.UnmarshalFromString(`{"a":1}`, &value); err != nil {
+		t.Errorf("Small input should pass: %v", err)
+	}
+	if err := limited.UnmarshalFromString(`{"a":12345}`, &value); !errors.Is(err, ErrMaxSizeExceeded) {
+		t.Errorf("Expected ErrMaxSizeExceeded, got %v", err)
+	}
+	reader := strings.NewReader(`{"a":"` + strings.Repeat("x", 1<<20) + `"}`)
+	if err := limited.UnmarshalFromReader(reader, &value); !errors.Is(err, ErrMaxSizeExceeded) {
+		t.Errorf("Expected ErrMaxSizeExceeded from reader, got %v", err)
+	}
+	if reader.Len() < 1<<19 {
+		t.Error("Reader should not be consumed beyond the limit")
+	}
+}
@@ -597,6 +597,79 @@ func TestMustMethods(t *testing.T) {
 	})
 }
 
+func TestMarshalExtEscapeAndSort(t *testing.T) {
+	type Item struct {
+		Zeta  string            `json:"zeta" yaml:"zeta" xml:"zeta"`
+		Alpha string            `json:"alpha" yaml:"alpha" xml:"alpha"`
+		Meta  map[string]string `json:"meta" yaml:"meta" xml:"-"`
+	}
+	item := Item{Zeta: "<b>&</b>", Alpha: "a", Meta: map[string]string{"y": "1", "x": "2"}}
+
+	t.Run("EscapeHTML", func(t *testing.T) {
+		escaped := NewMarshalExt(MarshalOptions{Format: JSONFormat, EscapeHTML: true}).MustMarshalToString(item)
+		if !strings.Contains(escaped, `\u003cb\u003e\u0026`) {
+			t.Errorf("Expected escaped HTML, got %s", escaped)
+		}
+
+		raw := DefaultMarshalExt().SetEscapeHTML(false).MustToJSON(item)
+		if !strings.Contains(raw, `"<b>&</b>"`) {
+			t.Errorf("Expected unescaped HTML, got %s", raw)
+		}
+	})
+
+	t.Run("SortKeysJSON", func(t *testing.T) {
+		unsorted := DefaultMarshalExt().MustToJSON(item)
+		if !strings.HasPrefix(unsorted, `{"zeta"`) {
+			t.Errorf("Struct field order should be kept by default, got %s", unsorted)
+		}
+
+		sorted := DefaultMarshalExt().SetSortKeys(true).SetEscapeHTML(false).MustToJSON(item)
+		expected := `{"alpha":"a","meta":{"x":"2","y":"1"},"zeta":"<b>&</b>"}`
+		if sorted != expected {
+			t.Errorf("Unexpected sorted JSON:\n got: %s\nwant: %s", sorted, expected)
+		}
+
+		pretty := DefaultMarshalExt().SetSortKeys(true).MustToPrettyJSON(item)
+		if !strings.HasPrefix(pretty, "{\n  \"alpha\"") {
+			t.Errorf("Unexpected sorted pretty JSON:\n%s", pretty)
+		}
+	})
+
+	t.Run("SortKeysYAML", func(t *testing.T) {
+		out := DefaultMarshalExt().SetSortKeys(true).MustToYAML(item)
+		expected := "alpha: a\nmeta:\n  x: \"2\"\n  \"y\": \"1\"\nzeta: <b>&</b>\n"
+		if out != expected {
+			t.Errorf("Unexpected sorted YAML:\n got: %q\nwant: %q", out, expected)
+		}
+	})
+
+	t.Run("SortKeysXML", func(t *testing.T) {
+		out := DefaultMarshalExt().SetSortKeys(true).MustToXML(item)
+		expected := "<Item><alpha>a</alpha><zeta>&lt;b&gt;&amp;&lt;/b&gt;</zeta></Item>"
+		if out != expected {
+			t.Errorf("Unexpected sorted XML:\n got: %s\nwant: %s", out, expected)
+		}
+	})
+
+	t.Run("CanonicalJSON", func(t *testing.T) {
+		a := map[string]interface{}{"b": []interface{}{1, 2.5, "x"}, "a": item, "big": int64(9007199254740993)}
+		b := map[string]interface{}{"big": int64(9007199254740993), "a": item, "b": []interface{}{1, 2.5, "x"}}
+
+		ca, err := ToCanonicalJSON(a)
+		if err != nil {
+			t.Fatalf("ToCanonicalJSON failed: %v", err)
+		}
+		cb, _ := DefaultMarshalExt().SetPretty(true).ToCanonicalJSON(b)
+		if ca != cb {
+			t.Errorf("Canonical JSON should be identical:\n%s\n%s", ca, cb)
+		}
+		expected := `{"a":{"alpha":"a","meta":{"x":"2","y":"1"},"zeta":"<b>&</b>"},"b":[1,2.5,"x"],"big":9007199254740993}`
+		if ca != expected {
+			t.Errorf("Unexpected canonical JSON:\n got: %s\nwant: %s", ca, expected)
+		}
+	})
+}
+
 func BenchmarkMarshalExt(b *testing.B) {
 	user := map[string]interface{}{
 		"id":        1,
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOML 序列化实现，字段名取 toml 标签
+func (m *MarshalExt) marshalTOML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = m.options.Indent
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("toml marshal error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *MarshalExt) unmarshalTOML(data []byte, v interface{}) error {
+	if err := toml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("toml unmarshal error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+TOML 序列化测试
+
+运行命令：
+go test -v -run "^TestTOML.*$"
+
+测试内容：
+1. toml 标签、嵌套表及往返一致性
+2. 解析到 map
+3. 全局及构建器便捷函数
+*/
+
+type tomlTestConfig struct {
+	Title   string        `toml:"title"`
+	Timeout time.Duration `toml:"timeout"`
+	Server  struct {
+		Host  string   `toml:"host"`
+		Ports []int    `toml:"ports"`
+		Tags  []string `toml:"tags"`
+	} `toml:"server"`
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	var config tomlTestConfig
+	config.Title = "demo"
+	config.Timeout = 5 * time.Second
+	config.Server.Host = "localhost"
+	config.Server.Ports = []int{8080, 8081}
+	config.Server.Tags = []string{"a", "b"}
+
+	marshal := DefaultMarshalExt().SetFormat(TOMLFormat)
+	data, err := marshal.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, want := range []string{`title = "demo"`, "[server]", `host = "localhost"`, "ports = [8080, 8081]"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("TOML output should contain %q:\n%s", want, data)
+		}
+	}
+
+	var decoded tomlTestConfig
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Title != "demo" || decoded.Timeout != 5*time.Second || decoded.Server.Host != "localhost" ||
+		len(decoded.Server.Ports) != 2 || decoded.Server.Tags[1] != "b" {
+		t.Errorf("Round trip mismatch: %+v", decoded)
+	}
+}
+
+func TestTOMLUnmarshalMap(t *testing.T) {
+	input := "name = \"app\"\n\n[db]\nport = 5432\n"
+	var result map[string]interface{}
+	if err := DefaultMarshalExt().SetFormat(TOMLFormat).UnmarshalFromString(input, &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if result["name"] != "app" || result["db"].(map[string]interface{})["port"] != int64(5432) {
+		t.Errorf("Unexpected result: %v", result)
+	}
+
+	if err := DefaultMarshalExt().SetFormat(TOMLFormat).UnmarshalFromString("name = ", &result); err == nil {
+		t.Error("Invalid TOML should fail")
+	}
+}
+
+func TestTOMLHelpers(t *testing.T) {
+	value := map[string]interface{}{"name": "app"}
+	expected := "name = \"app\"\n"
+
+	if out, err := ToTOML(value); err != nil || out != expected {
+		t.Errorf("ToTOML returned %q, %v", out, err)
+	}
+	if out := MustToTOML(value); out != expected {
+		t.Errorf("MustToTOML returned %q", out)
+	}
+	if out := NewMarshalBuilder(value).MustToTOML(); out != expected {
+		t.Errorf("Builder MustToTOML returned %q", out)
+	}
+}
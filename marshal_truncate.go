@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateMode 截断方式
+type TruncateMode int
+
+const (
+	// TruncateBytes 按字节截断，截断点对齐到 UTF-8 字符边界
+	TruncateBytes TruncateMode = iota
+	// TruncateStructure 缩短过长的字符串和数组，输出仍为有效 JSON，缩短后仍超长时返回 *MaxLengthError
+	TruncateStructure
+)
+
+const (
+	defaultMaxStringLength = 256 // 结构化截断时字符串默认最大字符数
+	defaultMaxArrayItems   = 50  // 结构化截断时数组默认最大元素数
+	minStringLength        = 8   // 结构化截断时字符串最小保留字符数
+)
+
+// ErrMaxLengthExceeded 输出超过 MaxLength 且未开启截断
+var ErrMaxLengthExceeded = errors.New("marshal output exceeds max length")
+
+// MaxLengthError 输出超长错误，可用 errors.Is(err, ErrMaxLengthExceeded) 判断
+type MaxLengthError struct {
+	Length    int // 实际长度
+	MaxLength int // 最大长度
+}
+
+// Error 实现 error 接口
+func (e *MaxLengthError) Error() string {
+	return fmt.Sprintf("%s: %d > %d bytes", ErrMaxLengthExceeded.Error(), e.Length, e.MaxLength)
+}
+
+// Unwrap 返回 ErrMaxLengthExceeded
+func (e *MaxLengthError) Unwrap() error {
+	return ErrMaxLengthExceeded
+}
+
+// truncate 处理超过 MaxLength 的输出
+func (m *MarshalExt) truncate(v interface{}, data []byte) ([]byte, error) {
+	// 二进制格式截断后无法解码，总是返回错误
+	if !m.options.Truncate || m.options.Format == MsgPackFormat || m.options.Format == CBORFormat {
+		return nil, &MaxLengthError{Length: len(data), MaxLength: m.options.MaxLength}
+	}
+
+	// 结构化截断无法满足长度限制时返回错误，不按字节截断以免输出无效 JSON
+	if m.options.TruncateMode == TruncateStructure && m.options.Format == JSONFormat {
+		if out, ok := m.truncateStructure(v); ok {
+			return out, nil
+		}
+		return nil, &MaxLengthError{Length: len(data), MaxLength: m.options.MaxLength}
+	}
+
+	return truncateBytes(data, m.options.MaxLength, m.options.TruncateSuffix), nil
+}
+
+// truncateBytes 在 UTF-8 字符边界处截断，并在长度限制内追加截断标记
+func truncateBytes(data []byte, limit int, suffix string) []byte {
+	cut := limit
+	for cut >= 0 {
+		end := runeBoundary(data, cut)
+		marker := truncateMarker(suffix, len(data)-end)
+		if end+len(marker) <= limit {
+			out := make([]byte, 0, end+len(marker))
+			out = append(out, data[:end]...)
+			return append(out, marker...)
+		}
+		// 标记长度随截断字节数变化，缩短保留部分后重试
+		cut = min(limit-len(marker), end-1)
+	}
+	// 标记本身超过长度限制时不追加标记
+	return append([]byte(nil), data[:runeBoundary(data, limit)]...)
+}
+
+// runeBoundary 返回不超过 n 的最近 UTF-8 字符边界
+func runeBoundary(data []byte, n int) int {
+	if n >= len(data) {
+		return len(data)
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return n
+}
+
+// truncateMarker 生成截断标记
+func truncateMarker(suffix string, truncated int) string {
+	if suffix == "" {
+		return ""
+	}
+	if strings.Contains(suffix, "%d") {
+		return fmt.Sprintf(suffix, truncated)
+	}
+	return suffix
+}
+
+// truncateStructure 逐步缩短字符串和数组直到 JSON 输出不超过 MaxLength
+func (m *MarshalExt) truncateStructure(v interface{}) ([]byte, bool) {
+	if m.encodingEnabled() {
+		encoded, err := m.encodeJSONWithEncodings(v, false)
+		if err != nil {
+			return nil, false
+		}
+		v = json.RawMessage(encoded)
+	}
+	normalized, err := normalizeJSON(v)
+	if err != nil {
+		return nil, false
+	}
+
+	maxString := m.options.MaxStringLength
+	if maxString <= 0 {
+		maxString = defaultMaxStringLength
+	}
+	maxItems := m.options.MaxArrayItems
+	if maxItems <= 0 {
+		maxItems = defaultMaxArrayItems
+	}
+
+	for {
+		shortened := m.shortenValue(normalized, maxString, maxItems)
+		data, err := m.marshalJSON(shortened)
+		if err != nil {
+			return nil, false
+		}
+		if len(data) <= m.options.MaxLength {
+			return data, true
+		}
+		if maxString <= minStringLength && maxItems <= 1 {
+			return nil, false
+		}
+		maxString = max(maxString/2, minStringLength)
+		maxItems = max(maxItems/2, 1)
+	}
+}
+
+// shortenValue 返回缩短了字符串和数组的副本，不修改输入
+func (m *MarshalExt) shortenValue(v interface{}, maxString, maxItems int) interface{} {
+	switch val := v.(type) {
+	case string:
+		if utf8.RuneCountInString(val) <= maxString {
+			return val
+		}
+		end := 0
+		for i := 0; i < maxString; i++ {
+			_, size := utf8.DecodeRuneInString(val[end:])
+			end += size
+		}
+		return val[:end] + truncateMarker(m.options.TruncateSuffix, len(val)-end)
+	case []interface{}:
+		n := min(len(val), maxItems)
+		result := make([]interface{}, 0, n+1)
+		for _, item := range val[:n] {
+			result = append(result, m.shortenValue(item, maxString, maxItems))
+		}
+		if len(val) > n {
+			result = append(result, fmt.Sprintf("…(%d more items)", len(val)-n))
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = m.shortenValue(item, maxString, maxItems)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+/*
+长度限制与截断测试
+
+运行命令：
+go test -v -run "^Test.*Truncate.*$|^TestMaxLength.*$"
+
+测试内容：
+1. 超长且未开启截断时返回 ErrMaxLengthExceeded
+2. UTF-8 字符边界截断及截断标记
+3. 结构化截断保持 JSON 有效，无法缩短到限制内时返回 ErrMaxLengthExceeded
+*/
+
+func TestMaxLengthExceeded(t *testing.T) {
+	marshal := NewMarshalExt(MarshalOptions{Format: JSONFormat, MaxLength: 5})
+	_, err := marshal.Marshal("too long value")
+	if !errors.Is(err, ErrMaxLengthExceeded) {
+		t.Fatalf("Expected ErrMaxLengthExceeded, got %v", err)
+	}
+	var lengthErr *MaxLengthError
+	if !errors.As(err, &lengthErr) || lengthErr.Length != 16 || lengthErr.MaxLength != 5 {
+		t.Errorf("Unexpected error detail %+v", lengthErr)
+	}
+
+	if _, err := marshal.Marshal(1); err != nil {
+		t.Errorf("Short output should not fail: %v", err)
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	value := strings.Repeat("中文", 10)
+
+	t.Run("RuneBoundary", func(t *testing.T) {
+		for limit := 1; limit < 30; limit++ {
+			out, err := DefaultMarshalExt().
+				SetFormat(StringFormat).
+				SetMaxLength(limit).
+				SetTruncate(true).
+				SetTruncateSuffix("").
+				Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if len(out) > limit || !utf8.Valid(out) {
+				t.Errorf("limit %d: invalid output %q", limit, out)
+			}
+		}
+	})
+
+	t.Run("Suffix", func(t *testing.T) {
+		out, err := DefaultMarshalExt().
+			SetFormat(StringFormat).
+			SetMaxLength(30).
+			SetTruncate(true).
+			Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		expected := "中文…(truncated 54 bytes)"
+		if string(out) != expected {
+			t.Errorf("Unexpected output %q, want %q", out, expected)
+		}
+		if len(out) > 30 {
+			t.Errorf("Output length %d exceeds 30", len(out))
+		}
+	})
+
+	t.Run("SuffixLongerThanLimit", func(t *testing.T) {
+		out, _ := DefaultMarshalExt().
+			SetFormat(StringFormat).
+			SetMaxLength(4).
+			SetTruncate(true).
+			Marshal(value)
+		if string(out) != "中" {
+			t.Errorf("Expected plain truncation, got %q", out)
+		}
+	})
+}
+
+func TestTruncateStructure(t *testing.T) {
+	value := map[string]interface{}{
+		"id":      42,
+		"message": strings.Repeat("x", 500),
+		"items":   []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		"nested":  map[string]interface{}{"text": strings.Repeat("日", 100)},
+	}
+
+	marshal := DefaultMarshalExt().
+		SetMaxLength(200).
+		SetTruncate(true).
+		SetTruncateMode(TruncateStructure)
+	out, err := marshal.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if len(out) > 200 {
+		t.Errorf("Output length %d exceeds 200: %s", len(out), out)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Structured truncation should keep valid JSON: %v\n%s", err, out)
+	}
+	if decoded["id"].(float64) != 42 {
+		t.Errorf("Short fields should be kept: %v", decoded["id"])
+	}
+	if !strings.Contains(decoded["message"].(string), "truncated") {
+		t.Errorf("Long string should carry truncation marker: %v", decoded["message"])
+	}
+	items := decoded["items"].([]interface{})
+	if !strings.Contains(items[len(items)-1].(string), "more items") {
+		t.Errorf("Long array should carry truncation marker: %v", items)
+	}
+
+	// 输入不应被修改
+	if len(value["message"].(string)) != 500 {
+		t.Error("Input should not be mutated")
+	}
+
+	custom := DefaultMarshalExt().
+		SetMaxLength(100).
+		SetTruncate(true).
+		SetTruncateMode(TruncateStructure)
+	custom.options.MaxArrayItems = 3
+	out, _ = custom.Marshal(map[string]interface{}{"items": make([]int, 400)})
+	if string(out) != `{"items":[0,0,0,"…(397 more items)"]}` {
+		t.Errorf("Unexpected output with MaxArrayItems: %s", out)
+	}
+
+	// 缩短到最小仍超长时返回错误，而不是输出无效 JSON
+	small := DefaultMarshalExt().
+		SetMaxLength(40).
+		SetTruncate(true).
+		SetTruncateMode(TruncateStructure)
+	out, err = small.Marshal(map[string]interface{}{"a": strings.Repeat("0123456789", 10), "b": "x", "c": "y", "d": "z"})
+	var lengthErr *MaxLengthError
+	if !errors.As(err, &lengthErr) || lengthErr.MaxLength != 40 || out != nil {
+		t.Errorf("Expected *MaxLengthError, got %q, %v", out, err)
+	}
+}
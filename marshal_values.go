@@ -0,0 +1,627 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 结构体与 url.Values、map[string]interface{} 之间的转换
+//
+// url.Values 的键名依次取 url 标签、json 标签、字段名，标签选项：
+//   - omitempty：零值（空切片、空 map）不编码
+//   - comma：切片编码为逗号分隔的单个值，默认编码为重复的键
+//   - unix、unixmilli：time.Time 编码为 Unix 秒或毫秒，默认使用 RFC 3339，也可用 layout 标签指定格式
+//
+// 嵌套结构体和 map 的键以点号连接，如 filter.status；nil 指针不编码
+
+// valueField 结构体字段的 url 编码规则
+type valueField struct {
+	structField
+	comma  bool
+	unix   string // 空、"unix" 或 "unixmilli"
+	layout string
+}
+
+// valueFields 返回结构体字段及其 url 标签选项
+func valueFields(t reflect.Type) []valueField {
+	fields := structFields(t, "url")
+	result := make([]valueField, len(fields))
+	for i, f := range fields {
+		sf := t.FieldByIndex(f.index)
+		tag, ok := sf.Tag.Lookup("url")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		_, opts, _ := strings.Cut(tag, ",")
+		result[i] = valueField{structField: f, layout: sf.Tag.Get("layout")}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "comma":
+				result[i].comma = true
+			case "unix", "unixmilli":
+				result[i].unix = opt
+			}
+		}
+	}
+	return result
+}
+
+// cloneValues 深复制查询参数
+func cloneValues(src map[string][]string) url.Values {
+	values := make(url.Values, len(src))
+	for k, items := range src {
+		values[k] = append([]string(nil), items...)
+	}
+	return values
+}
+
+// EncodeValues 将结构体、map[string]string、map[string][]string、map[string]interface{} 或 url.Values 编码为 url.Values
+// 返回的总是新的 url.Values，修改它不会影响输入
+func EncodeValues(v interface{}) (url.Values, error) {
+	switch value := v.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return cloneValues(value), nil
+	case map[string][]string:
+		return cloneValues(value), nil
+	case map[string]string:
+		values := url.Values{}
+		for k, item := range value {
+			values.Set(k, item)
+		}
+		return values, nil
+	}
+
+	rv := indirectValue(reflect.ValueOf(v))
+	values := url.Values{}
+	if !rv.IsValid() {
+		return values, nil
+	}
+	var err error
+	switch {
+	case rv.Kind() == reflect.Struct && rv.Type() != timeType:
+		err = encodeStructValues(values, "", rv)
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		err = encodeMapValues(values, "", rv)
+	default:
+		err = fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode values error: %w", err)
+	}
+	return values, nil
+}
+
+// EncodeQuery 将值编码为查询字符串，键按字典序排列
+func EncodeQuery(v interface{}) (string, error) {
+	values, err := EncodeValues(v)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// encodeStructValues 编码结构体字段，prefix 为嵌套字段的键名前缀
+func encodeStructValues(values url.Values, prefix string, v reflect.Value) error {
+	for _, f := range valueFields(v.Type()) {
+		field, ok := fieldByIndex(v, f.index, false)
+		if !ok || (f.omitEmpty && isEmptyValue(field)) {
+			continue
+		}
+		if err := encodeValue(values, prefix+f.name, field, f); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// encodeMapValues 编码字符串键的 map，键按字典序处理
+func encodeMapValues(values url.Values, prefix string, v reflect.Value) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		if err := encodeValue(values, prefix+k.String(), v.MapIndex(k), valueField{}); err != nil {
+			return fmt.Errorf("%s: %w", k.String(), err)
+		}
+	}
+	return nil
+}
+
+// encodeValue 编码单个值：标量写入一个值，切片写入多个值，结构体和 map 按点号展开
+func encodeValue(values url.Values, key string, v reflect.Value, f valueField) error {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+
+	switch {
+	case isScalarType(t):
+		s, err := formatValueString(v, f)
+		if err != nil {
+			return err
+		}
+		values.Add(key, s)
+		return nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		values.Add(key, string(v.Bytes()))
+		return nil
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item := indirectValue(v.Index(i))
+			if !item.IsValid() {
+				continue
+			}
+			if !isScalarType(item.Type()) {
+				return fmt.Errorf("unsupported slice element type %s", item.Type())
+			}
+			s, err := formatValueString(item, f)
+			if err != nil {
+				return err
+			}
+			items = append(items, s)
+		}
+		if f.comma {
+			values.Add(key, strings.Join(items, ","))
+			return nil
+		}
+		values[key] = append(values[key], items...)
+		return nil
+	case t.Kind() == reflect.Struct:
+		return encodeStructValues(values, key+".", v)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return encodeMapValues(values, key+".", v)
+	}
+	return fmt.Errorf("unsupported type %s", t)
+}
+
+// formatValueString 格式化标量，time.Time 按字段选项格式化，time.Duration 使用 time.ParseDuration 格式
+func formatValueString(v reflect.Value, f valueField) (string, error) {
+	switch v.Type() {
+	case timeType:
+		return formatValueTime(v.Interface().(time.Time), f), nil
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	}
+	return formatScalar(v)
+}
+
+// formatValueTime 按字段选项格式化时间
+func formatValueTime(t time.Time, f valueField) string {
+	switch {
+	case f.unix == "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case f.unix == "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case f.layout != "":
+		return t.Format(f.layout)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// isEmptyValue 判断值是否为 omitempty 意义上的空值
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// DecodeValues 将 url.Values 解码到结构体指针，键名规则同 EncodeValues
+// 标量取第一个值，切片取全部值（comma 选项时拆分逗号），输入中不存在的字段保持不变
+func DecodeValues(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("decode values error: target must be a non-nil pointer, got %T", v)
+	}
+	rv = rv.Elem()
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	var err error
+	switch {
+	case rv.Kind() == reflect.Struct && rv.Type() != timeType:
+		_, err = decodeStructValues(values, "", rv)
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		_, err = decodeMapValues(values, "", rv)
+	default:
+		err = fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("decode values error: %w", err)
+	}
+	return nil
+}
+
+// DecodeQuery 解析查询字符串（可带前导 ?）并解码到结构体指针
+func DecodeQuery(query string, v interface{}) error {
+	values, err := url.ParseQuery(strings.TrimPrefix(query, "?"))
+	if err != nil {
+		return fmt.Errorf("decode values error: %w", err)
+	}
+	return DecodeValues(values, v)
+}
+
+// DecodeForm 解析请求的查询参数和表单（包括 multipart 表单的文本字段）并解码到结构体指针
+func DecodeForm(r *http.Request, v interface{}) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return fmt.Errorf("decode values error: %w", err)
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("decode values error: %w", err)
+	}
+	return DecodeValues(r.Form, v)
+}
+
+// decodeStructValues 解码结构体字段，返回是否有字段被设置
+func decodeStructValues(values url.Values, prefix string, v reflect.Value) (bool, error) {
+	changed := false
+	for _, f := range valueFields(v.Type()) {
+		ft := v.Type().FieldByIndex(f.index).Type
+		key := prefix + f.name
+		if !hasValuesWithKey(values, key, ft) {
+			continue
+		}
+		field, _ := fieldByIndex(v, f.index, true)
+		fieldChanged, err := decodeValue(values, key, field, f)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", key, err)
+		}
+		changed = changed || fieldChanged
+	}
+	return changed, nil
+}
+
+// hasValuesWithKey 判断输入中是否有该键或以该键为前缀的嵌套键
+func hasValuesWithKey(values url.Values, key string, t reflect.Type) bool {
+	if _, ok := values[key]; ok {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Struct && t != timeType) || t.Kind() == reflect.Map {
+		for k := range values {
+			if strings.HasPrefix(k, key+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeMapValues 将以 prefix 开头的键解码为 map 元素
+func decodeMapValues(values url.Values, prefix string, v reflect.Value) (bool, error) {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	elemType := v.Type().Elem()
+	changed := false
+	nested := map[string]bool{}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name, ok := strings.CutPrefix(k, prefix)
+		if !ok || name == "" {
+			continue
+		}
+		if sub, _, isNested := strings.Cut(name, "."); isNested && !isScalarType(elemType) {
+			name = sub
+			if nested[name] {
+				continue
+			}
+			nested[name] = true
+		}
+		item := reflect.New(elemType).Elem()
+		if existing := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); existing.IsValid() {
+			item.Set(existing)
+		}
+		if _, err := decodeValue(values, prefix+name, item, valueField{}); err != nil {
+			return false, fmt.Errorf("%s: %w", name, err)
+		}
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), item)
+		changed = true
+	}
+	return changed, nil
+}
+
+// decodeValue 解码单个值
+func decodeValue(values url.Values, key string, v reflect.Value, f valueField) (bool, error) {
+	if v.Kind() == reflect.Ptr {
+		target := v
+		if v.IsNil() {
+			target = reflect.New(v.Type().Elem())
+		}
+		changed, err := decodeValue(values, key, target.Elem(), f)
+		if changed {
+			v.Set(target)
+		}
+		return changed, err
+	}
+
+	t := v.Type()
+	items, ok := values[key]
+	switch {
+	case t == timeType || isScalarType(t) || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8):
+		if !ok || len(items) == 0 {
+			return false, nil
+		}
+		return true, setValueString(v, items[0], f)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if !ok {
+			return false, nil
+		}
+		if f.comma {
+			var split []string
+			for _, item := range items {
+				if item != "" {
+					split = append(split, strings.Split(item, ",")...)
+				}
+			}
+			items = split
+		}
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		} else {
+			v.Set(reflect.Zero(t))
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			if err := setValueString(v.Index(i), items[i], f); err != nil {
+				return true, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return true, nil
+	case t.Kind() == reflect.Struct:
+		return decodeStructValues(values, key+".", v)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return decodeMapValues(values, key+".", v)
+	case t.Kind() == reflect.Interface:
+		if !ok || len(items) == 0 {
+			return false, nil
+		}
+		if len(items) == 1 {
+			v.Set(reflect.ValueOf(items[0]))
+		} else {
+			v.Set(reflect.ValueOf(append([]string(nil), items...)))
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported type %s", t)
+}
+
+// setValueString 解析单个字符串值，time.Time 按字段选项解析
+func setValueString(v reflect.Value, s string, f valueField) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValueString(v.Elem(), s, f)
+	}
+	if v.Type() != timeType || s == "" {
+		return setConfigValue(v, s)
+	}
+
+	var t time.Time
+	switch {
+	case f.unix != "":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if f.unix == "unixmilli" {
+			t = time.UnixMilli(n)
+		} else {
+			t = time.Unix(n, 0)
+		}
+	case f.layout != "":
+		parsed, err := time.Parse(f.layout, s)
+		if err != nil {
+			return err
+		}
+		t = parsed
+	default:
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		t = parsed
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// StructToMap 将结构体转换为 map[string]interface{}，键名取 json 标签并支持 omitempty
+// 标量、time.Time 和非结构体元素的切片保持原类型，嵌套结构体（包括切片和 map 中的）转换为 map
+func StructToMap(v interface{}) (map[string]interface{}, error) {
+	rv := indirectValue(reflect.ValueOf(v))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct || rv.Type() == timeType {
+		return nil, fmt.Errorf("struct to map error: expected struct, got %T", v)
+	}
+	return structToMap(rv), nil
+}
+
+// structToMap 转换结构体
+func structToMap(v reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, f := range structFields(v.Type(), "json") {
+		field, ok := fieldByIndex(v, f.index, false)
+		if !ok || (f.omitEmpty && isEmptyValue(field)) {
+			continue
+		}
+		result[f.name] = mapValue(field)
+	}
+	return result
+}
+
+// mapValue 转换字段值，只展开包含结构体的值
+func mapValue(v reflect.Value) interface{} {
+	if !containsStruct(v.Type(), map[reflect.Type]bool{}) {
+		return v.Interface()
+	}
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return structToMap(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}(nil)
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = mapValue(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return map[string]interface{}(nil)
+		}
+		items := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, _ := formatScalar(iter.Key())
+			items[key] = mapValue(iter.Value())
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// containsStruct 判断类型是否包含需要展开的结构体（time.Time 和实现了 TextMarshaler 的类型除外）
+func containsStruct(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] || isScalarType(t) {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsStruct(t.Elem(), visiting)
+	}
+	return false
+}
+
+// MapToStruct 将 map 写入结构体指针，键名按 json 标签匹配（优先精确匹配，其次大小写不敏感）
+// 类型可直接赋值或数值间可转换时直接写入，字符串按文本解析（如 "8080"、"1m30s"、RFC 3339 时间），嵌套 map 写入嵌套结构体
+func MapToStruct(m map[string]interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("map to struct error: target must be a non-nil pointer, got %T", v)
+	}
+	if err := assignMapValue(rv.Elem(), reflect.ValueOf(m)); err != nil {
+		return fmt.Errorf("map to struct error: %w", err)
+	}
+	return nil
+}
+
+// assignMapValue 将 src 写入 dst
+func assignMapValue(dst, src reflect.Value) error {
+	for src.Kind() == reflect.Interface || (src.Kind() == reflect.Ptr && src.Type() != dst.Type()) {
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignMapValue(dst.Elem(), src)
+	}
+
+	switch {
+	case src.Kind() == reflect.String && isScalarType(dst.Type()):
+		return setConfigValue(dst, src.String())
+	case isNumberKind(src.Kind()) && isNumberKind(dst.Kind()):
+		converted := src.Convert(dst.Type())
+		if !converted.Convert(src.Type()).Equal(src) {
+			return fmt.Errorf("value %v overflows %s", src.Interface(), dst.Type())
+		}
+		dst.Set(converted)
+		return nil
+	case dst.Kind() == reflect.String && isScalarType(src.Type()):
+		s, err := formatScalar(src)
+		if err != nil {
+			return err
+		}
+		dst.SetString(s)
+		return nil
+	case dst.Kind() == reflect.Struct && src.Kind() == reflect.Map && src.Type().Key().Kind() == reflect.String:
+		fields := structFields(dst.Type(), "json")
+		iter := src.MapRange()
+		for iter.Next() {
+			f := findStructField(fields, iter.Key().String())
+			if f == nil {
+				continue
+			}
+			field, _ := fieldByIndex(dst, f.index, true)
+			if err := assignMapValue(field, iter.Value()); err != nil {
+				return fmt.Errorf("%s: %w", iter.Key().String(), err)
+			}
+		}
+		return nil
+	case dst.Kind() == reflect.Slice && (src.Kind() == reflect.Slice || src.Kind() == reflect.Array):
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := assignMapValue(slice.Index(i), src.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case dst.Kind() == reflect.Map && src.Kind() == reflect.Map:
+		result := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			key := reflect.New(dst.Type().Key()).Elem()
+			if err := assignMapValue(key, iter.Key()); err != nil {
+				return err
+			}
+			item := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignMapValue(item, iter.Value()); err != nil {
+				return fmt.Errorf("%v: %w", iter.Key().Interface(), err)
+			}
+			result.SetMapIndex(key, item)
+		}
+		dst.Set(result)
+		return nil
+	}
+	return fmt.Errorf("cannot assign %s to %s", src.Type(), dst.Type())
+}
+
+// isNumberKind 判断是否为数值类型
+func isNumberKind(kind reflect.Kind) bool {
+	return isIntegerKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+结构体与 url.Values、map 转换测试
+
+运行命令：
+go test -v -run "^Test(EncodeValues|DecodeValues|DecodeForm|StructToMap|MapToStruct|HTTPQuery).*$"
+
+测试内容：
+1. 结构体编码为 url.Values：omitempty、重复键、comma、时间格式、嵌套结构体和 map
+2. 查询字符串和表单解码到结构体，与编码互逆
+3. 结构体与 map[string]interface{} 互相转换
+4. HTTPClient.GetQuery 和 PostFormValues
+*/
+
+type valuesFilter struct {
+	Status string            `url:"status,omitempty"`
+	Labels map[string]string `url:"labels,omitempty"`
+}
+
+type valuesQuery struct {
+	Keyword  string        `url:"q"`
+	Page     int           `url:"page,omitempty"`
+	Size     *int          `url:"size,omitempty"`
+	Tags     []string      `url:"tag,omitempty"`
+	IDs      []int64       `url:"ids,comma,omitempty"`
+	Active   bool          `json:"active"`
+	Since    time.Time     `url:"since,omitempty"`
+	Until    time.Time     `url:"until,unix,omitempty"`
+	Day      time.Time     `url:"day,omitempty" layout:"2006-01-02"`
+	Timeout  time.Duration `url:"timeout,omitempty"`
+	Filter   valuesFilter  `url:"filter"`
+	Internal string        `url:"-"`
+}
+
+func TestEncodeValues(t *testing.T) {
+	size := 20
+	since := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	query := valuesQuery{
+		Keyword: "go lang",
+		Size:    &size,
+		Tags:    []string{"a", "b"},
+		IDs:     []int64{1, 2, 3},
+		Since:   since,
+		Until:   since.Add(time.Hour),
+		Day:     since,
+		Timeout: 90 * time.Second,
+		Filter:  valuesFilter{Status: "open", Labels: map[string]string{"team": "core"}},
+	}
+
+	values, err := EncodeValues(query)
+	if err != nil {
+		t.Fatalf("EncodeValues failed: %v", err)
+	}
+	expected := url.Values{
+		"q":                  {"go lang"},
+		"size":               {"20"},
+		"tag":                {"a", "b"},
+		"ids":                {"1,2,3"},
+		"active":             {"false"},
+		"since":              {"2024-05-01T08:30:00Z"},
+		"until":              {"1714555800"},
+		"day":                {"2024-05-01"},
+		"timeout":            {"1m30s"},
+		"filter.status":      {"open"},
+		"filter.labels.team": {"core"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("EncodeValues =\n%v\nwant\n%v", values, expected)
+	}
+
+	encoded, err := EncodeQuery(map[string]interface{}{"b": []int{1, 2}, "a": "x y", "n": nil})
+	if err != nil || encoded != "a=x+y&b=1&b=2" {
+		t.Errorf("EncodeQuery = %q, %v", encoded, err)
+	}
+	if _, err := EncodeValues([]int{1}); err == nil {
+		t.Error("Encoding a slice should fail")
+	}
+	if _, err := EncodeValues(struct{ C chan int }{}); err == nil {
+		t.Error("Encoding an unsupported field should fail")
+	}
+
+	// 返回的 url.Values 与输入互不影响
+	input := url.Values{"a": {"1"}}
+	for _, v := range []interface{}{input, map[string][]string(input)} {
+		copied, _ := EncodeValues(v)
+		copied.Add("a", "2")
+		copied.Set("b", "3")
+	}
+	if !reflect.DeepEqual(input, url.Values{"a": {"1"}}) {
+		t.Errorf("Input should not be modified: %v", input)
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	var query valuesQuery
+	err := DecodeQuery("?q=go+lang&page=2&size=20&tag=a&tag=b&ids=1,2&ids=3&active=true&since=2024-05-01T08:30:00Z"+
+		"&until=1714555800&day=2024-05-01&timeout=1m30s&filter.status=open&filter.labels.team=core&unknown=1", &query)
+	if err != nil {
+		t.Fatalf("DecodeQuery failed: %v", err)
+	}
+	if query.Keyword != "go lang" || query.Page != 2 || query.Size == nil || *query.Size != 20 || !query.Active {
+		t.Errorf("Unexpected scalars: %+v", query)
+	}
+	if !reflect.DeepEqual(query.Tags, []string{"a", "b"}) || !reflect.DeepEqual(query.IDs, []int64{1, 2, 3}) {
+		t.Errorf("Unexpected slices: %v %v", query.Tags, query.IDs)
+	}
+	since := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	if !query.Since.Equal(since) || !query.Until.Equal(since.Add(time.Hour)) || query.Day.Format("2006-01-02") != "2024-05-01" {
+		t.Errorf("Unexpected times: %v %v %v", query.Since, query.Until, query.Day)
+	}
+	if query.Timeout != 90*time.Second || query.Filter.Status != "open" || query.Filter.Labels["team"] != "core" {
+		t.Errorf("Unexpected nested values: %+v", query)
+	}
+
+	// 编码后再解码得到相同的值
+	values, _ := EncodeValues(query)
+	var roundTrip valuesQuery
+	if err := DecodeValues(values, &roundTrip); err != nil || !reflect.DeepEqual(roundTrip.Filter, query.Filter) || roundTrip.Until.Unix() != query.Until.Unix() {
+		t.Errorf("Round trip = %+v, %v", roundTrip, err)
+	}
+
+	kept := valuesQuery{Keyword: "keep", Page: 9}
+	if err := DecodeQuery("page=3", &kept); err != nil || kept.Keyword != "keep" || kept.Page != 3 || kept.Size != nil {
+		t.Errorf("Missing keys should keep fields: %+v, %v", kept, err)
+	}
+
+	for _, invalid := range []string{"page=x", "until=soon", "day=05/01/2024", "size=1.5"} {
+		if err := DecodeQuery(invalid, &valuesQuery{}); err == nil {
+			t.Errorf("DecodeQuery(%q) should fail", invalid)
+		}
+	}
+	if err := DecodeQuery("a=1", valuesQuery{}); err == nil {
+		t.Error("Non-pointer target should fail")
+	}
+
+	generic := map[string]interface{}{}
+	if err := DecodeQuery("a=1&b=2&b=3", &generic); err != nil || generic["a"] != "1" || !reflect.DeepEqual(generic["b"], []string{"2", "3"}) {
+		t.Errorf("Decode into map = %v, %v", generic, err)
+	}
+}
+
+func TestDecodeForm(t *testing.T) {
+	type login struct {
+		User     string   `url:"user"`
+		Remember bool     `url:"remember"`
+		Scopes   []string `url:"scope"`
+		Page     int      `url:"page"`
+	}
+
+	req := httptest.NewRequest("POST", "/login?page=2", strings.NewReader("user=alice&remember=true&scope=read&scope=write"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var form login
+	if err := DecodeForm(req, &form); err != nil {
+		t.Fatalf("DecodeForm failed: %v", err)
+	}
+	if form.User != "alice" || !form.Remember || len(form.Scopes) != 2 || form.Page != 2 {
+		t.Errorf("DecodeForm = %+v", form)
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip,omitempty"`
+	}
+	type person struct {
+		Name      string             `json:"name"`
+		Age       int                `json:"age"`
+		Tags      []string           `json:"tags"`
+		Home      address            `json:"home"`
+		Offices   []address          `json:"offices,omitempty"`
+		Contacts  map[string]address `json:"contacts,omitempty"`
+		Born      time.Time          `json:"born"`
+		Spouse    *person            `json:"spouse,omitempty"`
+		Nickname  string             `json:"nickname,omitempty"`
+		Ignored   string             `json:"-"`
+		unexposed int
+	}
+
+	born := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	p := person{
+		Name: "alice", Age: 30, Tags: []string{"a"}, Home: address{City: "x"}, Born: born,
+		Offices:  []address{{City: "y", Zip: "1"}},
+		Contacts: map[string]address{"work": {City: "z"}},
+	}
+	m, err := StructToMap(&p)
+	if err != nil {
+		t.Fatalf("StructToMap failed: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":     "alice",
+		"age":      30,
+		"tags":     []string{"a"},
+		"home":     map[string]interface{}{"city": "x"},
+		"offices":  []interface{}{map[string]interface{}{"city": "y", "zip": "1"}},
+		"contacts": map[string]interface{}{"work": map[string]interface{}{"city": "z"}},
+		"born":     born,
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("StructToMap =\n%#v\nwant\n%#v", m, expected)
+	}
+	if _, err := StructToMap(map[string]int{}); err == nil {
+		t.Error("StructToMap on a map should fail")
+	}
+
+	var back person
+	if err := MapToStruct(m, &back); err != nil {
+		t.Fatalf("MapToStruct failed: %v", err)
+	}
+	p.unexposed = 0
+	if !reflect.DeepEqual(back, p) {
+		t.Errorf("MapToStruct =\n%+v\nwant\n%+v", back, p)
+	}
+}
+
+func TestMapToStruct(t *testing.T) {
+	type settings struct {
+		Port    uint16            `json:"port"`
+		Ratio   float64           `json:"ratio"`
+		Timeout time.Duration     `json:"timeout"`
+		Since   time.Time         `json:"since"`
+		Enabled *bool             `json:"enabled"`
+		Name    string            `json:"name"`
+		Ports   []int             `json:"ports"`
+		Limits  map[string]int    `json:"limits"`
+		Extra   interface{}       `json:"extra"`
+		Labels  map[string]string `json:"labels"`
+	}
+
+	var s settings
+	err := MapToStruct(map[string]interface{}{
+		"port":    "8080",
+		"RATIO":   1,
+		"timeout": "2s",
+		"since":   "2024-05-01T00:00:00Z",
+		"enabled": true,
+		"name":    42,
+		"ports":   []interface{}{int64(80), 443.0, "8443"},
+		"limits":  map[string]interface{}{"cpu": 2},
+		"extra":   []string{"x"},
+		"labels":  map[string]string{"a": "b"},
+		"unknown": 1,
+	}, &s)
+	if err != nil {
+		t.Fatalf("MapToStruct failed: %v", err)
+	}
+	if s.Port != 8080 || s.Ratio != 1 || s.Timeout != 2*time.Second || s.Since.Year() != 2024 || s.Enabled == nil || !*s.Enabled || s.Name != "42" {
+		t.Errorf("Unexpected scalars: %+v", s)
+	}
+	if !reflect.DeepEqual(s.Ports, []int{80, 443, 8443}) || s.Limits["cpu"] != 2 || !reflect.DeepEqual(s.Extra, []string{"x"}) || s.Labels["a"] != "b" {
+		t.Errorf("Unexpected containers: %+v", s)
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"port": 70000},
+		{"port": -1},
+		{"ratio": "high"},
+		{"ports": "80"},
+	} {
+		if err := MapToStruct(invalid, &settings{}); err == nil {
+			t.Errorf("MapToStruct(%v) should fail", invalid)
+		}
+	}
+}
+
+func TestHTTPQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Write([]byte(r.Method + " " + r.Form.Encode()))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	resp := client.GetQuery("/search", valuesQuery{Keyword: "go", Tags: []string{"a", "b"}})
+	if resp.Error != nil || resp.String() != "GET active=false&q=go&tag=a&tag=b" {
+		t.Errorf("GetQuery = %q, %v", resp.String(), resp.Error)
+	}
+
+	resp = client.PostFormValues("/submit", url.Values{"x": {"1", "2"}})
+	if resp.Error != nil || resp.String() != "POST x=1&x=2" {
+		t.Errorf("PostFormValues = %q, %v", resp.String(), resp.Error)
+	}
+
+	if resp := client.GetQuery("/search", 42); resp.Error == nil {
+		t.Error("Invalid query should return an error")
+	}
+}
@@ -0,0 +1,354 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// xmlItemName map/切片编码时数组元素的默认元素名
+const xmlItemName = "item"
+
+// XML 序列化实现
+func (m *MarshalExt) marshalXML(v interface{}) ([]byte, error) {
+	// 转换键名时先紧凑编码，再逐个 token 改写名称并按需缩进，根元素名保持不变
+	if m.options.KeyNaming != KeepKeyNames {
+		data, err := m.Clone().SetKeyNaming(KeepKeyNames).SetPretty(false).marshalXML(v)
+		if err != nil {
+			return nil, err
+		}
+		return m.rewriteXMLNames(data, m.options.Pretty, &xmlEncodeRenamer{naming: m.options.KeyNaming})
+	}
+
+	// map、切片等动态数据按属性/文本约定编码
+	if isDynamicXML(v) {
+		return m.marshalDynamicXML(v)
+	}
+
+	// 排序时先按结构体编码，再经通用结构重新编码，子元素按名称排序
+	if m.options.SortKeys {
+		data, err := xml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		root, value, err := m.decodeXMLTree(data)
+		if err != nil {
+			return nil, err
+		}
+		return m.Clone().SetXMLRoot(root).marshalDynamicXML(value)
+	}
+
+	if m.options.Pretty {
+		return xml.MarshalIndent(v, "", m.options.Indent)
+	}
+	return xml.Marshal(v)
+}
+
+func (m *MarshalExt) unmarshalXML(data []byte, v interface{}) error {
+	if m.options.MaxDepth > 0 || m.options.DisallowTrailingData {
+		if err := m.checkXMLStructure(data); err != nil {
+			return err
+		}
+	}
+
+	switch target := v.(type) {
+	case *map[string]interface{}:
+		value, err := m.decodeDynamicXML(data)
+		if err != nil {
+			return err
+		}
+		result, ok := value.(map[string]interface{})
+		if !ok {
+			result = map[string]interface{}{m.xmlTextKey(): value}
+		}
+		*target = result
+		return nil
+	case *interface{}:
+		value, err := m.decodeDynamicXML(data)
+		if err != nil {
+			return err
+		}
+		*target = value
+		return nil
+	}
+
+	if m.options.KeyNaming != KeepKeyNames {
+		matched, err := m.rewriteXMLNames(data, false, &xmlDecodeRenamer{root: reflect.TypeOf(v)})
+		if err != nil {
+			return fmt.Errorf("xml unmarshal error: %w", err)
+		}
+		data = matched
+	}
+
+	if m.options.DisallowUnknownFields {
+		root, value, err := m.decodeXMLTree(data)
+		if err != nil {
+			return err
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			if err := m.checkXMLFields(rv.Type().Elem(), value, "/"+root); err != nil {
+				return err
+			}
+		}
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func (m *MarshalExt) xmlRoot() string {
+	if m.options.XMLRoot == "" {
+		return DefaultMarshalOptions.XMLRoot
+	}
+	return m.options.XMLRoot
+}
+
+func (m *MarshalExt) xmlAttrPrefix() string {
+	if m.options.XMLAttrPrefix == "" {
+		return DefaultMarshalOptions.XMLAttrPrefix
+	}
+	return m.options.XMLAttrPrefix
+}
+
+func (m *MarshalExt) xmlTextKey() string {
+	if m.options.XMLTextKey == "" {
+		return DefaultMarshalOptions.XMLTextKey
+	}
+	return m.options.XMLTextKey
+}
+
+// isDynamicXML 判断是否需要按动态数据编码：map 或非字节切片
+func isDynamicXML(v interface{}) bool {
+	rv := indirectValue(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// indirectValue 解引用指针和接口
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// marshalDynamicXML 将 map/切片编码为以 XMLRoot 为根的 XML
+func (m *MarshalExt) marshalDynamicXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	if m.options.Pretty {
+		encoder.Indent("", m.options.Indent)
+	}
+	if err := m.encodeXMLElement(encoder, m.xmlRoot(), reflect.ValueOf(v)); err != nil {
+		return nil, fmt.Errorf("xml marshal error: %w", err)
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeXMLElement 编码单个元素
+func (m *MarshalExt) encodeXMLElement(encoder *xml.Encoder, name string, rv reflect.Value) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	rv = indirectValue(rv)
+
+	switch {
+	case !rv.IsValid():
+		return encoder.EncodeElement("", start)
+	case rv.Kind() == reflect.Map:
+		return m.encodeXMLMap(encoder, start, rv)
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+		// 数组直接嵌套在数组或根元素中时，用 item 元素包裹每个成员
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := m.encodeXMLElement(encoder, xmlItemName, rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case rv.Kind() == reflect.Struct:
+		return encoder.EncodeElement(rv.Interface(), start)
+	default:
+		return encoder.EncodeElement(fmt.Sprint(rv.Interface()), start)
+	}
+}
+
+// encodeXMLMap 编码 map：属性前缀键为属性，文本键为文本内容，其余键为子元素
+func (m *MarshalExt) encodeXMLMap(encoder *xml.Encoder, start xml.StartElement, rv reflect.Value) error {
+	prefix, textKey := m.xmlAttrPrefix(), m.xmlTextKey()
+
+	keys := make([]string, 0, rv.Len())
+	values := make(map[string]reflect.Value, rv.Len())
+	for _, k := range rv.MapKeys() {
+		key := fmt.Sprint(k.Interface())
+		keys = append(keys, key)
+		values[key] = rv.MapIndex(k)
+	}
+	sort.Strings(keys)
+
+	var text string
+	var children []string
+	for _, key := range keys {
+		switch {
+		case key == textKey:
+			text = fmt.Sprint(indirectInterface(values[key]))
+		case strings.HasPrefix(key, prefix):
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(key, prefix)},
+				Value: fmt.Sprint(indirectInterface(values[key])),
+			})
+		default:
+			children = append(children, key)
+		}
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	for _, key := range children {
+		value := indirectValue(values[key])
+		// 切片成员编码为同名重复元素
+		if value.IsValid() && (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) &&
+			value.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < value.Len(); i++ {
+				if err := m.encodeXMLElement(encoder, key, value.Index(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := m.encodeXMLElement(encoder, key, value); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// indirectInterface 返回解引用后的值，nil 返回空字符串
+func indirectInterface(rv reflect.Value) interface{} {
+	rv = indirectValue(rv)
+	if !rv.IsValid() {
+		return ""
+	}
+	return rv.Interface()
+}
+
+// xmlNode 解析中的元素
+type xmlNode struct {
+	attrs    []xml.Attr
+	names    []string              // 子元素名，保持出现顺序
+	children map[string][]*xmlNode // 子元素，key为元素名
+	text     strings.Builder
+}
+
+// decodeDynamicXML 将任意 XML 解析为 map[string]interface{}/[]interface{}/string 组成的通用结构
+// 返回根元素的内容，根元素名本身被丢弃
+func (m *MarshalExt) decodeDynamicXML(data []byte) (interface{}, error) {
+	_, value, err := m.decodeXMLTree(data)
+	return value, err
+}
+
+// decodeXMLTree 解析 XML，返回根元素名和根元素内容
+func (m *MarshalExt) decodeXMLTree(data []byte) (string, interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	var rootName string
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("xml unmarshal error: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{attrs: t.Attr, children: make(map[string][]*xmlNode)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				if _, ok := parent.children[t.Name.Local]; !ok {
+					parent.names = append(parent.names, t.Name.Local)
+				}
+				parent.children[t.Name.Local] = append(parent.children[t.Name.Local], node)
+			} else if root == nil {
+				root = node
+				rootName = t.Name.Local
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return "", nil, fmt.Errorf("xml unmarshal error: no root element")
+	}
+	return rootName, m.xmlNodeValue(root), nil
+}
+
+// xmlAttrName 返回属性名：命名空间声明保留 xmlns: 前缀，其余属性使用本地名
+func xmlAttrName(name xml.Name) string {
+	if name.Space == "xmlns" {
+		return "xmlns:" + name.Local
+	}
+	return name.Local
+}
+
+// isXMLNamespaceDecl 判断属性是否为 xmlns 或 xmlns:* 命名空间声明
+func isXMLNamespaceDecl(name string) bool {
+	return name == "xmlns" || strings.HasPrefix(name, "xmlns:")
+}
+
+// xmlNodeValue 将元素转换为通用值：纯文本元素为字符串，其余为 map
+func (m *MarshalExt) xmlNodeValue(node *xmlNode) interface{} {
+	text := strings.TrimSpace(node.text.String())
+	if len(node.attrs) == 0 && len(node.names) == 0 {
+		return text
+	}
+
+	result := make(map[string]interface{}, len(node.attrs)+len(node.names)+1)
+	for _, attr := range node.attrs {
+		result[m.xmlAttrPrefix()+xmlAttrName(attr.Name)] = attr.Value
+	}
+	for _, name := range node.names {
+		nodes := node.children[name]
+		if len(nodes) == 1 {
+			result[name] = m.xmlNodeValue(nodes[0])
+			continue
+		}
+		items := make([]interface{}, len(nodes))
+		for i, child := range nodes {
+			items[i] = m.xmlNodeValue(child)
+		}
+		result[name] = items
+	}
+	if text != "" {
+		result[m.xmlTextKey()] = text
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*
+XML 动态数据序列化测试
+
+运行命令：
+go test -v -run "^TestXML.*$"
+
+测试内容：
+1. map/[]interface{} 编码（根元素、属性、文本约定）
+2. 任意 XML 解析为 map
+3. 自定义约定与往返一致性
+*/
+
+func TestXMLMarshalMap(t *testing.T) {
+	value := map[string]interface{}{
+		"@id":   1,
+		"name":  "张三",
+		"tags":  []interface{}{"a", "b"},
+		"empty": nil,
+		"address": map[string]interface{}{
+			"@type": "home",
+			"#text": "Beijing",
+		},
+	}
+
+	out, err := ToXML(value)
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+	expected := `<root id="1"><address type="home">Beijing</address><empty></empty>` +
+		`<name>张三</name><tags>a</tags><tags>b</tags></root>`
+	if out != expected {
+		t.Errorf("Unexpected XML:\n got: %s\nwant: %s", out, expected)
+	}
+
+	out, err = DefaultMarshalExt().SetXMLRoot("list").ToXML([]interface{}{1, []int{2, 3}})
+	if err != nil {
+		t.Fatalf("ToXML failed: %v", err)
+	}
+	if out != "<list><item>1</item><item><item>2</item><item>3</item></item></list>" {
+		t.Errorf("Unexpected slice XML: %s", out)
+	}
+
+	pretty, err := ToPrettyXML(map[string]interface{}{"a": map[string]interface{}{"b": 1}})
+	if err != nil {
+		t.Fatalf("ToPrettyXML failed: %v", err)
+	}
+	if pretty != "<root>\n  <a>\n    <b>1</b>\n  </a>\n</root>" {
+		t.Errorf("Unexpected pretty XML:\n%s", pretty)
+	}
+}
+
+func TestXMLUnmarshalMap(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<order id="42">
+  <item sku="A">Apple</item>
+  <item sku="B">Banana</item>
+  <note>fragile</note>
+  <customer><name>Li</name></customer>
+  <empty/>
+</order>`
+
+	var decoded map[string]interface{}
+	if err := DefaultMarshalExt().SetFormat(XMLFormat).UnmarshalFromString(input, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"@id": "42",
+		"item": []interface{}{
+			map[string]interface{}{"@sku": "A", "#text": "Apple"},
+			map[string]interface{}{"@sku": "B", "#text": "Banana"},
+		},
+		"note":     "fragile",
+		"customer": map[string]interface{}{"name": "Li"},
+		"empty":    "",
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Unexpected map:\n got: %#v\nwant: %#v", decoded, expected)
+	}
+
+	var generic interface{}
+	if err := DefaultMarshalExt().SetFormat(XMLFormat).UnmarshalFromString("<a>text</a>", &generic); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if generic != "text" {
+		t.Errorf("Expected text value, got %#v", generic)
+	}
+
+	if err := DefaultMarshalExt().SetFormat(XMLFormat).UnmarshalFromString("", &decoded); err == nil {
+		t.Error("Expected error for empty document")
+	}
+}
+
+func TestXMLCustomConventionRoundTrip(t *testing.T) {
+	marshal := DefaultMarshalExt().
+		SetFormat(XMLFormat).
+		SetXMLRoot("config").
+		SetXMLAttrPrefix("-").
+		SetXMLTextKey("_value")
+
+	value := map[string]interface{}{
+		"-version": "2",
+		"server": []interface{}{
+			map[string]interface{}{"-name": "a", "_value": "10.0.0.1"},
+			map[string]interface{}{"-name": "b", "_value": "10.0.0.2"},
+		},
+	}
+
+	data, err := marshal.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `<config version="2">`) {
+		t.Errorf("Unexpected XML: %s", data)
+	}
+
+	var decoded map[string]interface{}
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("Round trip mismatch:\n got: %#v\nwant: %#v", decoded, value)
+	}
+}
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML 序列化实现
+func (m *MarshalExt) marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := m.newYAMLEncoder(&buf)
+	if err := m.encodeYAML(encoder, v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *MarshalExt) unmarshalYAML(data []byte, v interface{}) error {
+	if m.options.MaxDepth > 0 {
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return fmt.Errorf("yaml unmarshal error: %w", err)
+		}
+		if yamlDepth(&node) > m.options.MaxDepth {
+			return fmt.Errorf("yaml unmarshal error: %w", ErrMaxDepthExceeded)
+		}
+	}
+
+	if m.options.KeyNaming != KeepKeyNames {
+		matched, err := matchYAMLKeys(data, v)
+		if err != nil {
+			return fmt.Errorf("yaml unmarshal error: %w", err)
+		}
+		data = matched
+	}
+
+	if m.decodingEnabled() {
+		rewritten, err := m.rewriteYAMLInput(data, v)
+		if err != nil {
+			return fmt.Errorf("yaml unmarshal error: %w", err)
+		}
+		data = rewritten
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(m.options.DisallowUnknownFields)
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		if isUnknownYAMLField(err) {
+			return fmt.Errorf("yaml unmarshal error: %w: %w", ErrUnknownField, err)
+		}
+		return fmt.Errorf("yaml unmarshal error: %w", err)
+	}
+
+	// 默认与 yaml.Unmarshal 一致，只解析第一个文档
+	if m.options.DisallowTrailingData {
+		var extra yaml.Node
+		if err := decoder.Decode(&extra); err != io.EOF {
+			if err == nil {
+				err = ErrTrailingData
+			}
+			return fmt.Errorf("yaml unmarshal error: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarshalYAMLDocuments 将多个对象序列化为以 --- 分隔的 YAML 多文档流
+func (m *MarshalExt) MarshalYAMLDocuments(docs ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := m.newYAMLEncoder(&buf)
+	for _, doc := range docs {
+		if err := m.encodeYAML(encoder, doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalYAMLDocuments 将 YAML 多文档流逐个解析到切片，out 必须是切片指针，例如 *[]Config
+// 每个文档与 Unmarshal 一样应用严格解析、键名风格等选项
+func (m *MarshalExt) UnmarshalYAMLDocuments(data []byte, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("yaml documents target must be a pointer to slice, got %T", out)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+
+	ext := m
+	if m.options.Format != YAMLFormat {
+		ext = m.Clone().SetFormat(YAMLFormat)
+	}
+	if ext.options.MaxSize > 0 && len(data) > ext.options.MaxSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrMaxSizeExceeded, len(data), ext.options.MaxSize)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("yaml document %d: %w", slice.Len(), err)
+		}
+
+		// 逐个文档重新编码后走 Unmarshal 的解析流程
+		elem := reflect.New(elemType)
+		if doc.Kind != 0 {
+			raw, err := yaml.Marshal(&doc)
+			if err != nil {
+				return fmt.Errorf("yaml document %d: %w", slice.Len(), err)
+			}
+			if err := ext.Unmarshal(raw, elem.Interface()); err != nil {
+				return fmt.Errorf("yaml document %d: %w", slice.Len(), err)
+			}
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+}
+
+// newYAMLEncoder 按缩进选项创建编码器
+func (m *MarshalExt) newYAMLEncoder(w io.Writer) *yaml.Encoder {
+	encoder := yaml.NewEncoder(w)
+	indent := len(m.options.Indent)
+	if indent < 2 {
+		indent = 2
+	}
+	encoder.SetIndent(indent)
+	return encoder
+}
+
+// encodeYAML 编码单个文档，流式风格时将根节点标记为 FlowStyle，排序时对映射节点按键排序，设置了 KeyNaming 时转换键名，
+// 设置了时间或 []byte 编码方式时改写对应节点；yaml.v3 对部分类型（如未导出的嵌入结构体）会 panic，此时转换为错误返回
+func (m *MarshalExt) encodeYAML(encoder *yaml.Encoder, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("yaml marshal error: %v", r)
+		}
+	}()
+
+	if !m.options.YAMLFlow && !m.options.SortKeys && m.options.KeyNaming == KeepKeyNames && !m.encodingEnabled() {
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("yaml marshal error: %w", err)
+		}
+		return nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return fmt.Errorf("yaml marshal error: %w", err)
+	}
+	if m.encodingEnabled() {
+		m.rewriteYAMLOutput(&node, reflect.ValueOf(v))
+	}
+	if m.options.KeyNaming != KeepKeyNames {
+		m.renameYAMLNode(&node, reflect.ValueOf(v))
+	}
+	if m.options.YAMLFlow {
+		node.Style |= yaml.FlowStyle
+	}
+	if m.options.SortKeys {
+		sortYAMLNode(&node)
+	}
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("yaml marshal error: %w", err)
+	}
+	return nil
+}
+
+// sortYAMLNode 递归地将映射节点的键值对按键排序
+func sortYAMLNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i][0].Value < pairs[j][0].Value
+		})
+		for i, pair := range pairs {
+			node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+		}
+	}
+	for _, child := range node.Content {
+		sortYAMLNode(child)
+	}
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/so68/utils/logger"
+)
+
+/*
+YAML 序列化测试
+
+运行命令：
+go test -v -run "^TestYAML.*$"
+
+测试内容：
+1. yaml 标签及往返一致性 (logger.Config)
+2. 块风格/流式风格与缩进
+3. 锚点与别名解析
+4. 多文档流，每个文档应用严格解析和键名风格选项
+5. 无法编码的值返回错误而不是 panic
+*/
+
+func TestYAMLRoundTrip(t *testing.T) {
+	config := logger.DefaultConfig()
+	config.File.Path = "/var/log/app.log"
+
+	marshal := DefaultMarshalExt().SetFormat(YAMLFormat)
+	data, err := marshal.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	yamlStr := string(data)
+	if strings.HasPrefix(yamlStr, "{") {
+		t.Fatalf("YAML output should not be JSON: %s", yamlStr)
+	}
+	for _, want := range []string{"level: info\n", "file:\n  path: /var/log/app.log\n", "max_size_mb:"} {
+		if !strings.Contains(yamlStr, want) {
+			t.Errorf("YAML output should contain %q:\n%s", want, yamlStr)
+		}
+	}
+
+	var decoded logger.Config
+	if err := marshal.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(*config, decoded) {
+		t.Errorf("Round trip mismatch:\n got: %+v\nwant: %+v", decoded, *config)
+	}
+}
+
+func TestYAMLStyles(t *testing.T) {
+	value := map[string]interface{}{
+		"name": "app",
+		"tags": []string{"a", "b"},
+	}
+
+	t.Run("Indent", func(t *testing.T) {
+		out, err := DefaultMarshalExt().SetIndent("    ").ToYAML(value)
+		if err != nil {
+			t.Fatalf("ToYAML failed: %v", err)
+		}
+		if !strings.Contains(out, "tags:\n    - a\n    - b\n") {
+			t.Errorf("Expected 4-space indent:\n%s", out)
+		}
+	})
+
+	t.Run("Flow", func(t *testing.T) {
+		out, err := DefaultMarshalExt().SetYAMLFlow(true).ToYAML(value)
+		if err != nil {
+			t.Fatalf("ToYAML failed: %v", err)
+		}
+		if strings.TrimSpace(out) != "{name: app, tags: [a, b]}" {
+			t.Errorf("Unexpected flow output: %s", out)
+		}
+
+		var decoded map[string]interface{}
+		if err := DefaultMarshalExt().SetFormat(YAMLFormat).UnmarshalFromString(out, &decoded); err != nil {
+			t.Fatalf("Unmarshal flow failed: %v", err)
+		}
+		if decoded["name"] != "app" || len(decoded["tags"].([]interface{})) != 2 {
+			t.Errorf("Unexpected decoded value %v", decoded)
+		}
+	})
+}
+
+func TestYAMLAnchors(t *testing.T) {
+	input := `
+defaults: &defaults
+  level: warn
+  output: stdout
+service:
+  <<: *defaults
+  level: debug
+alias: *defaults
+`
+	var decoded struct {
+		Defaults logger.Config `yaml:"defaults"`
+		Service  logger.Config `yaml:"service"`
+		Alias    logger.Config `yaml:"alias"`
+	}
+	if err := DefaultMarshalExt().SetFormat(YAMLFormat).UnmarshalFromString(input, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Service.Level != logger.LevelDebug || decoded.Service.Output != logger.OutputStdout {
+		t.Errorf("Merge key not applied: %+v", decoded.Service)
+	}
+	if decoded.Alias.Level != logger.LevelWarn {
+		t.Errorf("Alias not resolved: %+v", decoded.Alias)
+	}
+}
+
+func TestYAMLDocuments(t *testing.T) {
+	type doc struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	}
+	docs := []doc{{"a", 1}, {"b", 2}}
+
+	marshal := DefaultMarshalExt()
+	data, err := marshal.MarshalYAMLDocuments(docs[0], docs[1])
+	if err != nil {
+		t.Fatalf("MarshalYAMLDocuments failed: %v", err)
+	}
+	if string(data) != "name: a\nport: 1\n---\nname: b\nport: 2\n" {
+		t.Errorf("Unexpected stream:\n%s", data)
+	}
+
+	var decoded []doc
+	if err := marshal.UnmarshalYAMLDocuments(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalYAMLDocuments failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, docs) {
+		t.Errorf("Unexpected documents %+v", decoded)
+	}
+
+	if err := marshal.UnmarshalYAMLDocuments(data, decoded); err == nil {
+		t.Error("Expected error for non-pointer target")
+	}
+}
+
+func TestYAMLDocumentsOptions(t *testing.T) {
+	type doc struct {
+		UserName string
+		Port     int
+	}
+	data := []byte("user_name: a\nport: 1\n---\nuser_name: b\nport: 2\n")
+
+	var decoded []doc
+	if err := DefaultMarshalExt().SetKeyNaming(SnakeCaseKeys).UnmarshalYAMLDocuments(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalYAMLDocuments failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, []doc{{"a", 1}, {"b", 2}}) {
+		t.Errorf("KeyNaming documents = %+v", decoded)
+	}
+
+	strict := DefaultMarshalExt().SetDisallowUnknownFields(true)
+	err := strict.UnmarshalYAMLDocuments([]byte("username: a\n---\nusername: b\nextra: 1\n"), &[]doc{})
+	if err == nil || !strings.Contains(err.Error(), "yaml document 1") || !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Unknown field in second document = %v", err)
+	}
+}
+
+func TestYAMLMarshalPanic(t *testing.T) {
+	type inner struct{ Name string }
+	type outer struct {
+		inner
+		Age int
+	}
+	if _, err := DefaultMarshalExt().SetFormat(YAMLFormat).Marshal(outer{inner{"a"}, 1}); err == nil {
+		t.Error("Expected error for unexported embedded struct")
+	}
+	if _, err := DefaultMarshalExt().MarshalYAMLDocuments(outer{}); err == nil {
+		t.Error("Expected error from MarshalYAMLDocuments")
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/so68/utils"
+)
+
+// defaultCodec 未通过 WithCodec 指定时使用的序列化器，只读
+var defaultCodec = utils.DefaultMarshalExt()
+
+// codecWriter 携带序列化器的 ResponseWriter
+type codecWriter struct {
+	http.ResponseWriter
+	codec *utils.MarshalExt
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (cw *codecWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// WithCodec 指定 Bind、WriteJSON 和 WriteError 使用的序列化器
+func WithCodec(codec *utils.MarshalExt) Middleware {
+	return func(next http.Handler) http.Handler {
+		if codec == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), codecKey, codec)
+			next.ServeHTTP(&codecWriter{ResponseWriter: w, codec: codec}, r.WithContext(ctx))
+		})
+	}
+}
+
+// codecFromContext 从上下文获取序列化器
+func codecFromContext(ctx context.Context) *utils.MarshalExt {
+	if codec, ok := ctx.Value(codecKey).(*utils.MarshalExt); ok {
+		return codec
+	}
+	return defaultCodec
+}
+
+// codecFromWriter 沿 Unwrap 链查找 WithCodec 附加的序列化器
+func codecFromWriter(w http.ResponseWriter) *utils.MarshalExt {
+	for w != nil {
+		if cw, ok := w.(*codecWriter); ok {
+			return cw.codec
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return defaultCodec
+}
+
+// Bind 将 JSON 请求体解析到 v，失败时返回 400 错误
+func Bind(r *http.Request, v interface{}) error {
+	if r.Body == nil {
+		return BadRequest("request body is empty")
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return NewError(http.StatusRequestEntityTooLarge, "body_too_large",
+				fmt.Sprintf("request body exceeds %d bytes", maxErr.Limit))
+		}
+		return BadRequest(fmt.Sprintf("read request body: %v", err))
+	}
+	if len(data) == 0 {
+		return BadRequest("request body is empty")
+	}
+
+	if err := codecFromContext(r.Context()).Unmarshal(data, v); err != nil {
+		return BadRequest(fmt.Sprintf("invalid json: %v", err))
+	}
+	return nil
+}
+
+// WriteJSON 写入响应，默认为 JSON，WithCodec 指定其他格式时 Content-Type 随之变化
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	codec := codecFromWriter(w)
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	contentType := codec.ContentType()
+	if contentType == "application/json" {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteError 写入标准错误响应，非 *Error 的错误按 500 处理且不暴露内部信息
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Internal(http.StatusText(http.StatusInternalServerError))
+	}
+
+	envelope := ErrorEnvelope{Error: e}
+	if r != nil {
+		envelope.RequestID = RequestIDFromContext(r.Context())
+	}
+	_ = WriteJSON(w, e.Status, envelope)
+}
+
+// Handle 将 HandlerFunc 适配为 http.Handler，返回的错误写成标准错误响应
+func Handle(fn HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			WriteError(w, r, err)
+		}
+	})
+}
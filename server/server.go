@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/so68/utils"
+)
+
+// Server 支持优雅关闭的 HTTP 服务器
+type Server struct {
+	config      Config            // 服务器配置
+	handler     http.Handler      // 业务处理器
+	logger      *slog.Logger      // 日志记录器
+	codec       *utils.MarshalExt // 请求/响应序列化器，nil 时使用默认 JSON
+	middlewares []Middleware      // 额外中间件
+
+	mu         sync.Mutex   // 保护 httpServer
+	httpServer *http.Server // 底层服务器，首次 Serve 或 Shutdown 时创建
+}
+
+// NewServer 创建服务器，默认启用请求ID、访问日志、panic 恢复和请求体大小限制
+func NewServer(handler http.Handler) *Server {
+	return &Server{
+		config:  DefaultConfig(),
+		handler: handler,
+		logger:  slog.Default(),
+	}
+}
+
+// SetConfig 设置服务器配置
+func (s *Server) SetConfig(config Config) *Server {
+	s.config = config
+	return s
+}
+
+// SetAddr 设置监听地址
+func (s *Server) SetAddr(addr string) *Server {
+	s.config.Addr = addr
+	return s
+}
+
+// SetLogger 设置日志记录器
+func (s *Server) SetLogger(logger *slog.Logger) *Server {
+	s.logger = logger
+	return s
+}
+
+// SetCodec 设置 Bind、WriteJSON 和 WriteError 使用的序列化器
+func (s *Server) SetCodec(codec *utils.MarshalExt) *Server {
+	s.codec = codec
+	return s
+}
+
+// Use 追加中间件，位于默认中间件之内
+func (s *Server) Use(middlewares ...Middleware) *Server {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
+// Handler 返回包装了全部中间件的处理器
+func (s *Server) Handler() http.Handler {
+	defaults := []Middleware{
+		RequestID(),
+		WithCodec(s.codec),
+		AccessLog(s.logger),
+		Recover(s.logger),
+		BodyLimit(s.config.MaxBodyBytes),
+	}
+	return Chain(s.handler, append(defaults, s.middlewares...)...)
+}
+
+// Serve 在指定监听器上提供服务，ctx 取消后优雅关闭
+// 已调用 Shutdown 时立即返回 nil
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := s.server()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("http server started", "addr", listener.Addr().String())
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	return s.Shutdown()
+}
+
+// ListenAndServe 监听配置地址，ctx 取消或收到 SIGINT/SIGTERM 后优雅关闭
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", s.config.Addr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return s.Serve(ctx, listener)
+}
+
+// server 返回底层服务器，不存在时按当前配置创建
+func (s *Server) server() *http.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.httpServer == nil {
+		s.httpServer = &http.Server{
+			Handler:           s.Handler(),
+			ReadTimeout:       s.config.ReadTimeout,
+			ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+			WriteTimeout:      s.config.WriteTimeout,
+			IdleTimeout:       s.config.IdleTimeout,
+			ErrorLog:          slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+		}
+	}
+	return s.httpServer
+}
+
+// Shutdown 在 ShutdownTimeout 内等待进行中的请求完成后关闭服务器
+// 在 Serve 之前调用时，之后的 Serve 会立即返回
+func (s *Server) Shutdown() error {
+	httpServer := s.server()
+
+	ctx := context.Background()
+	if s.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	s.logger.Info("http server shutting down")
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	s.logger.Info("http server stopped")
+	return nil
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/so68/utils"
+)
+
+/*
+HTTP服务端工具测试
+
+运行命令：
+go test -v ./server
+
+测试内容：
+1. JSON 请求绑定与响应写入 (Bind, WriteJSON, WriteError, Handle)
+2. 中间件 (RequestID, AccessLog, Recover, BodyLimit, WithCodec, Chain)
+3. 服务器启动与优雅关闭 (Serve, Shutdown)
+*/
+
+type createUser struct {
+	Name string `json:"name"`
+}
+
+func createUserHandler(w http.ResponseWriter, r *http.Request) error {
+	var req createUser
+	if err := Bind(r, &req); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return BadRequest("name is required").WithDetails(map[string]string{"field": "name"})
+	}
+	return WriteJSON(w, http.StatusCreated, req)
+}
+
+func decodeEnvelope(t *testing.T, body []byte) ErrorEnvelope {
+	t.Helper()
+	var envelope ErrorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Invalid error envelope %s: %v", body, err)
+	}
+	if envelope.Error == nil {
+		t.Fatalf("Missing error in envelope: %s", body)
+	}
+	return envelope
+}
+
+func TestBindAndWriteJSON(t *testing.T) {
+	handler := Chain(Handle(createUserHandler), RequestID())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"alice"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	if strings.TrimSpace(rec.Body.String()) != `{"name":"alice"}` {
+		t.Errorf("Unexpected body %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/users", strings.NewReader(`{bad`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid json, got %d", rec.Code)
+	}
+	envelope := decodeEnvelope(t, rec.Body.Bytes())
+	if envelope.Error.Code != "bad_request" || envelope.RequestID == "" {
+		t.Errorf("Unexpected envelope %+v", envelope)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/users", strings.NewReader(`{}`)))
+	envelope = decodeEnvelope(t, rec.Body.Bytes())
+	if envelope.Error.Details == nil {
+		t.Error("Expected details in envelope")
+	}
+}
+
+func TestWriteErrorHidesInternalErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.New("database password leaked"))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "password") {
+		t.Errorf("Internal error message should not be exposed: %s", rec.Body.String())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}), RequestID())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if seen == "" || rec.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("Expected generated request ID, got %q / %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if seen != "abc" {
+		t.Errorf("Expected incoming request ID to be kept, got %q", seen)
+	}
+}
+
+func TestRecoverAndAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), RequestID(), AccessLog(logger), Recover(logger))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 after panic, got %d", rec.Code)
+	}
+	decodeEnvelope(t, rec.Body.Bytes())
+
+	logs := buf.String()
+	if !strings.Contains(logs, `"panic":"boom"`) {
+		t.Errorf("Expected panic to be logged: %s", logs)
+	}
+	if !strings.Contains(logs, `"msg":"http request"`) || !strings.Contains(logs, `"status":500`) ||
+		!strings.Contains(logs, `"level":"ERROR"`) {
+		t.Errorf("Expected access log entry with status 500: %s", logs)
+	}
+}
+
+func TestRecoverAfterWriteHeader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	}), Recover(logger))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" {
+		t.Errorf("Response already started should be left untouched, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWithCodec(t *testing.T) {
+	codec := utils.DefaultMarshalExt().SetKeyNaming(utils.SnakeCaseKeys)
+	handler := Chain(Handle(func(w http.ResponseWriter, r *http.Request) error {
+		var req struct{ UserName string }
+		if err := Bind(r, &req); err != nil {
+			return err
+		}
+		return WriteJSON(w, http.StatusOK, map[string]string{"got": req.UserName})
+	}), WithCodec(codec), Recover(nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"user_name":"alice"}`)))
+	if strings.TrimSpace(rec.Body.String()) != `{"got":"alice"}` {
+		t.Errorf("Codec should be used for Bind and WriteJSON, got %s", rec.Body.String())
+	}
+
+	// 未指定时使用默认 JSON
+	rec = httptest.NewRecorder()
+	Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return WriteJSON(w, http.StatusOK, struct{ UserName string }{"bob"})
+	}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if strings.TrimSpace(rec.Body.String()) != `{"UserName":"bob"}` {
+		t.Errorf("Default codec = %s", rec.Body.String())
+	}
+
+	// Content-Type 跟随序列化器的格式
+	rec = httptest.NewRecorder()
+	Chain(Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return WriteJSON(w, http.StatusOK, map[string]string{"name": "carol"})
+	}), WithCodec(utils.DefaultMarshalExt().SetFormat(utils.YAMLFormat))).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("Expected YAML Content-Type, got %q", ct)
+	}
+	if strings.TrimSpace(rec.Body.String()) != "name: carol" {
+		t.Errorf("Expected YAML body, got %s", rec.Body.String())
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	handler := Chain(Handle(createUserHandler), BodyLimit(8))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"too long"}`)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413, got %d", rec.Code)
+	}
+}
+
+func TestServerGracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.Handle("/slow", Handle(func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return WriteJSON(w, http.StatusOK, map[string]bool{"done": true})
+	}))
+
+	srv := NewServer(mux).SetLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	respCh := make(chan *utils.HTTPResponse, 1)
+	go func() {
+		respCh <- utils.NewHTTPClient("http://"+listener.Addr().String()).Get("/slow", nil)
+	}()
+
+	<-started
+	cancel()
+
+	resp := <-respCh
+	if !resp.IsSuccess() || !strings.Contains(resp.String(), `"done":true`) {
+		t.Errorf("In-flight request should complete during shutdown, got %d %v", resp.StatusCode, resp.Error)
+	}
+	if resp.Headers.Get(RequestIDHeader) == "" {
+		t.Error("Default middlewares should set request ID")
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
+
+func TestServerShutdownBeforeServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	srv := NewServer(http.NotFoundHandler()).SetLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	if err := srv.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(context.Background(), listener) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve after Shutdown returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve after Shutdown should return immediately")
+	}
+}
+
+func TestServerConcurrentShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	srv := NewServer(http.NotFoundHandler()).SetLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(context.Background(), listener) }()
+	if err := srv.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Middleware HTTP 中间件
+type Middleware func(next http.Handler) http.Handler
+
+// HandlerFunc 可返回错误的处理函数，错误会被写成标准错误响应
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Error 标准错误，Status 为 HTTP 状态码，Code 为业务错误码
+type Error struct {
+	Status  int         `json:"-"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// ErrorEnvelope 错误响应外层结构
+type ErrorEnvelope struct {
+	Error     *Error `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Config 服务器配置
+type Config struct {
+	Addr              string        // 监听地址
+	ReadTimeout       time.Duration // 读超时
+	ReadHeaderTimeout time.Duration // 读请求头超时
+	WriteTimeout      time.Duration // 写超时
+	IdleTimeout       time.Duration // 空闲连接超时
+	ShutdownTimeout   time.Duration // 优雅关闭等待时间
+	MaxBodyBytes      int64         // 请求体最大字节数，0表示无限制
+}
+
+// DefaultConfig 返回默认服务器配置
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   15 * time.Second,
+		MaxBodyBytes:      10 * 1024 * 1024, // 10MB
+	}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// NewError 创建标准错误
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// WithDetails 附加错误详情
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// BadRequest 400 错误
+func BadRequest(message string) *Error {
+	return NewError(http.StatusBadRequest, "bad_request", message)
+}
+
+// Unauthorized 401 错误
+func Unauthorized(message string) *Error {
+	return NewError(http.StatusUnauthorized, "unauthorized", message)
+}
+
+// Forbidden 403 错误
+func Forbidden(message string) *Error {
+	return NewError(http.StatusForbidden, "forbidden", message)
+}
+
+// NotFound 404 错误
+func NotFound(message string) *Error {
+	return NewError(http.StatusNotFound, "not_found", message)
+}
+
+// Conflict 409 错误
+func Conflict(message string) *Error {
+	return NewError(http.StatusConflict, "conflict", message)
+}
+
+// Internal 500 错误
+func Internal(message string) *Error {
+	return NewError(http.StatusInternalServerError, "internal_error", message)
+}
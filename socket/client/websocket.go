@@ -158,12 +158,15 @@ func (m *Websocket) connect(dialURL string) error {
 
 	conn, _, err := dialer.DialContext(ctx, dialURL, reqHeader)
 	if err != nil {
+		m.mux.Lock()
 		m.retryCount++
-		if m.shouldRetry() {
+		retryCount, retry := m.retryCount, m.shouldRetry()
+		m.mux.Unlock()
+		if retry {
 			// 不在这里递归调用connect，让调用者处理重试逻辑
 			return fmt.Errorf("WebSocket connection failed: %w", err)
 		}
-		return fmt.Errorf("WebSocket connect failed after %d retries: %w", m.retryCount, err)
+		return fmt.Errorf("WebSocket connect failed after %d retries: %w", retryCount, err)
 	}
 
 	// 更新连接状态（需要加锁保护）
@@ -215,11 +218,15 @@ func (m *Websocket) listenLoop() {
 			m.conn.Close()
 			m.conn = nil
 		}
+		retryCount, retry := m.retryCount, m.shouldRetry()
 		m.mux.Unlock()
 
-		// 检查是否需要重连
-		if m.shouldRetry() {
-			m.logger.Info("WebSocket Reconnecting...", "attempt", m.retryCount+1)
+		// 检查是否需要重连（已调用 Close 时不再重连）
+		if m.ctx.Err() != nil {
+			return
+		}
+		if retry {
+			m.logger.Info("WebSocket Reconnecting...", "attempt", retryCount+1)
 			// 使用延迟重连，避免立即递归
 			m.goroutines.Add(1)
 			go func() {
@@ -241,7 +248,7 @@ func (m *Websocket) listenLoop() {
 				}
 			}()
 		} else {
-			m.logger.Info("WebSocket permanently closed after retries", "retry_count", m.retryCount)
+			m.logger.Info("WebSocket permanently closed after retries", "retry_count", retryCount)
 		}
 	}()
 
@@ -298,32 +305,31 @@ func (m *Websocket) pingLoop() {
 		case <-m.ctx.Done():
 			return
 		case <-ticker.C:
-			m.mux.RLock()
-			conn := m.conn
-			m.mux.RUnlock()
-
-			if conn == nil {
-				return
-			}
-
-			// 根据配置选择心跳方式
-			if m.config.PingMessage != "" {
-				// 发送JSON消息作为心跳
-				if err := conn.WriteMessage(websocket.TextMessage, []byte(m.config.PingMessage)); err != nil {
-					m.logger.Error("WebSocket Ping message error", "error", err.Error())
-					return // 触发重连
-				}
-			} else {
-				// 使用标准ping帧
-				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-					m.logger.Error("WebSocket Ping error", "error", err.Error())
-					return // 触发重连
-				}
+			// 持锁写入，避免与 WriteMessage、Close 并发写
+			if err := m.ping(); err != nil {
+				m.logger.Error("WebSocket Ping error", "error", err.Error())
+				return // 触发重连
 			}
 		}
 	}
 }
 
+// ping 发送一次心跳，连接已关闭时返回错误
+func (m *Websocket) ping() error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.conn == nil {
+		return fmt.Errorf("WebSocket connection is closed")
+	}
+	// 根据配置选择心跳方式
+	if m.config.PingMessage != "" {
+		// 发送JSON消息作为心跳
+		return m.conn.WriteMessage(websocket.TextMessage, []byte(m.config.PingMessage))
+	}
+	// 使用标准ping帧
+	return m.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
 // IsConnected 检查连接状态
 func (m *Websocket) IsConnected() bool {
 	m.mux.RLock()
@@ -383,36 +389,24 @@ func (m *Websocket) Close() {
 	// 取消上下文，停止所有goroutine
 	m.cancel()
 	m.isRunning = false
-	m.mux.Unlock()
 
-	if m.conn != nil {
-		// 安全地发送关闭帧
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					m.logger.Error("WebSocket Close frame send panic (ignored)")
-				}
-			}()
-			// 设置关闭超时
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-
-			// 尝试发送关闭帧
-			select {
-			case <-ctx.Done():
-				m.logger.Error("WebSocket Close frame timeout")
-			default:
-				if err := m.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
-					m.logger.Error("WebSocket Failed to send close frame", "error", err.Error())
-				}
-			}
-		}()
+	// 持锁取出连接，避免与监听、心跳和写入并发访问
+	conn := m.conn
+	m.conn = nil
+	if conn != nil {
+		// 尝试发送关闭帧，最多等待 5 秒
+		_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
+			m.logger.Error("WebSocket Failed to send close frame", "error", err.Error())
+		}
+	}
+	m.mux.Unlock()
 
-		// 关闭连接
-		if err := m.conn.Close(); err != nil {
+	// 关闭连接，阻塞中的 ReadMessage 随之返回
+	if conn != nil {
+		if err := conn.Close(); err != nil {
 			m.logger.Error("WebSocket Failed to close connection", "error", err.Error())
 		}
-		m.conn = nil
 	}
 
 	// 等待所有goroutine完成